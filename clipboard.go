@@ -0,0 +1,39 @@
+// clipboard.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/alexferrari88/prompt-scanner/utils"
+)
+
+// readClipboard returns the text on the system clipboard using the platform's built-in tooling:
+// pbpaste on macOS, Get-Clipboard on Windows and wl-paste, xclip or xsel elsewhere.
+func readClipboard() ([]byte, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("pbpaste")
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw")
+	default:
+		switch {
+		case os.Getenv("WAYLAND_DISPLAY") != "" && utils.CommandExists("wl-paste"):
+			cmd = exec.Command("wl-paste", "--no-newline")
+		case utils.CommandExists("xclip"):
+			cmd = exec.Command("xclip", "-selection", "clipboard", "-o")
+		case utils.CommandExists("xsel"):
+			cmd = exec.Command("xsel", "--clipboard", "--output")
+		default:
+			return nil, fmt.Errorf("reading clipboard: install wl-paste, xclip or xsel")
+		}
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("reading clipboard: %w", err)
+	}
+	return out, nil
+}
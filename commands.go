@@ -0,0 +1,1072 @@
+// commands.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexferrari88/prompt-scanner/scanner"
+)
+
+// runSubcommand dispatches "prompt-scanner <command> ..." invocations. It reports false when
+// args do not name a subcommand, in which case the default scan command runs. A file or directory
+// with the name of a subcommand in the working directory is scanned instead, so "prompt-scanner
+// report" scans ./report when it exists.
+func runSubcommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if _, err := os.Stat(args[0]); err == nil {
+		return false
+	}
+	switch args[0] {
+	case "report":
+		runReportCommand(args[1:])
+	case "dashboard":
+		runDashboardCommand(args[1:])
+	case "diff-prompt":
+		runDiffPromptCommand(args[1:])
+	case "check":
+		runCheckCommand(args[1:])
+	case "query":
+		runQueryCommand(args[1:])
+	case "verify":
+		runVerifyCommand(args[1:])
+	case "prune":
+		runPruneCommand(args[1:])
+	case "extract":
+		runExtractCommand(args[1:])
+	case "export":
+		runExportCommand(args[1:])
+	case "inventory":
+		runInventoryCommand(args[1:])
+	case "sync-check":
+		runSyncCheckCommand(args[1:])
+	case "serve":
+		runServeCommand(args[1:])
+	case "staged":
+		runStagedCommand(args[1:])
+	default:
+		return false
+	}
+	return true
+}
+
+// runReportCommand implements "report <kind>" for reports built from stored scan runs.
+func runReportCommand(args []string) {
+	if len(args) == 0 || args[0] != "trend" {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s report trend -history <file>|-db <file> [-format markdown|html]\n", filepath.Base(os.Args[0]))
+		os.Exit(scanner.ExitError)
+	}
+	fs := flag.NewFlagSet("report trend", flag.ExitOnError)
+	historyPath := fs.String("history", "", "History file written by scans run with -history.")
+	dbPath := fs.String("db", "", "SQLite database written by scans run with -format sqlite.")
+	format := fs.String("format", "markdown", "Trend output format: markdown or html.")
+	fs.Parse(args[1:])
+	if (*historyPath == "") == (*dbPath == "") {
+		fatalf("report trend: exactly one of -history and -db is required")
+	}
+
+	var records []scanner.HistoryRecord
+	var err error
+	if *dbPath != "" {
+		records, err = scanner.ReadSQLiteHistory(*dbPath)
+	} else {
+		records, err = scanner.ReadHistory(*historyPath)
+	}
+	if err != nil {
+		fatalf("report trend: %v", err)
+	}
+	if err := scanner.WriteTrend(os.Stdout, records, strings.ToLower(*format)); err != nil {
+		fatalf("report trend: %v", err)
+	}
+}
+
+// runDashboardCommand builds a static multi-repository dashboard from envelope reports.
+func runDashboardCommand(args []string) {
+	fs := flag.NewFlagSet("dashboard", flag.ExitOnError)
+	outDir := fs.String("out", "prompt-dashboard", "Directory to write the dashboard site to.")
+	similarity := fs.Float64("similarity", scanner.DefaultSimilarityThreshold, "Similarity (0-1) at which prompts in different repositories count as copies.")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s dashboard [-out <dir>] <envelope.json>...\n\nBuilds a static site from reports written with -format envelope.\n\nOptions:\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(scanner.ExitError)
+	}
+
+	envelopes := make([]scanner.JSONEnvelope, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		envelope, err := scanner.ReadEnvelope(path)
+		if err != nil {
+			fatalf("dashboard: %v", err)
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	if err := scanner.WriteDashboard(*outDir, envelopes, *similarity); err != nil {
+		fatalf("dashboard: %v", err)
+	}
+	log.Printf("Dashboard for %d repositories written to %s", len(envelopes), filepath.Join(*outDir, "index.html"))
+}
+
+// stringList collects the values of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// byteSize is a size flag given in bytes or with a KB, MB or GB suffix (powers of 1024), e.g. 5MB.
+type byteSize int64
+
+var byteSizeUnits = []struct {
+	suffix string
+	size   int64
+}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+
+func (b *byteSize) String() string {
+	for _, unit := range byteSizeUnits {
+		if *b != 0 && int64(*b)%unit.size == 0 {
+			return fmt.Sprintf("%d%s", int64(*b)/unit.size, unit.suffix)
+		}
+	}
+	return "0"
+}
+
+func (b *byteSize) Set(value string) error {
+	number, size := strings.ToUpper(strings.TrimSpace(value)), int64(1)
+	for _, unit := range byteSizeUnits {
+		if trimmed, ok := strings.CutSuffix(number, unit.suffix); ok {
+			number, size = strings.TrimSpace(trimmed), unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/size {
+		return fmt.Errorf("invalid size '%s' (use e.g. 500KB or 5MB)", value)
+	}
+	*b = byteSize(n * size)
+	return nil
+}
+
+// presetFlags are the -mode flag and its deprecated -greedy shorthand.
+type presetFlags struct {
+	mode   *string
+	greedy *bool
+}
+
+func addPresetFlags(fs *flag.FlagSet) presetFlags {
+	return presetFlags{
+		mode:   fs.String("mode", "", "Heuristic preset: strict, balanced or greedy (default balanced). Sets the defaults of -min-len and -content-keywords."),
+		greedy: fs.Bool("greedy", false, "Shorthand for -mode greedy (deprecated)."),
+	}
+}
+
+// resolve returns the preset selected by the flags.
+func (p presetFlags) resolve() (scanner.Preset, error) {
+	switch {
+	case *p.mode == "" && *p.greedy:
+		return scanner.PresetGreedy, nil
+	case *p.mode == "":
+		return scanner.PresetBalanced, nil
+	case *p.greedy && *p.mode != scanner.ModeGreedy:
+		return scanner.Preset{}, fmt.Errorf("-greedy conflicts with -mode %s", *p.mode)
+	}
+	return scanner.LookupPreset(strings.ToLower(*p.mode))
+}
+
+// applyPreset selects preset in opts and uses its MinLength and ContentKeywords unless the
+// -min-len or -content-keywords flags of fs were set.
+func applyPreset(fs *flag.FlagSet, preset scanner.Preset, opts *scanner.ScanOptions) {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	opts.Mode = preset.Name
+	if !set["min-len"] {
+		opts.MinLength = preset.MinLength
+	}
+	if !set["content-keywords"] && fs.Lookup("content-keywords") != nil {
+		opts.ContentKeywords = preset.ContentKeywords
+	}
+}
+
+// labelMap collects the key=value pairs of the repeatable -label flag; a repeated key keeps its
+// last value.
+type labelMap map[string]string
+
+func (m labelMap) String() string {
+	pairs := make([]string, 0, len(m))
+	for key, value := range m {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (m labelMap) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" || strings.ContainsAny(key, " \t") {
+		return fmt.Errorf("invalid label '%s' (use key=value)", value)
+	}
+	m[key] = strings.TrimSpace(val)
+	return nil
+}
+
+// runDiffPromptCommand prints a unified diff of one finding, identified by its id, between two
+// refs of a repository.
+func runDiffPromptCommand(args []string) {
+	fs := flag.NewFlagSet("diff-prompt", flag.ExitOnError)
+	id := fs.String("id", "", "Finding id, as shown in json and envelope output.")
+	var refs stringList
+	fs.Var(&refs, "ref", "Branch, tag or commit to compare; give exactly two.")
+	scanConfigs := fs.Bool("scan-configs", false, "Also scan config files, for prompts found with -scan-configs.")
+	presets := addPresetFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s diff-prompt -id <finding-id> -ref <A> -ref <B> [<repo_path_or_url>]\n\nShows how one prompt changed between two refs of a git repository (default: the current directory).\n\nOptions:\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *id == "" || len(refs) != 2 || fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(scanner.ExitError)
+	}
+	target := "."
+	if fs.NArg() == 1 {
+		target = fs.Arg(0)
+	}
+	if !looksLikeGitHubURL(target) {
+		absTarget, err := filepath.Abs(target)
+		if err != nil {
+			fatalf("diff-prompt: resolving '%s': %v", target, err)
+		}
+		target = absTarget
+	}
+
+	preset, err := presets.resolve()
+	if err != nil {
+		fatalf("diff-prompt: %v", err)
+	}
+	opts := scanner.ScanOptions{
+		VariableKeywords:       scanner.DefaultVarKeywordsList,
+		PlaceholderPatterns:    scanner.DefaultPlaceholderPatternsList,
+		PromptFilenamePatterns: scanner.DefaultPromptFilenamePatternsList,
+		ScanConfigs:            *scanConfigs,
+	}
+	preset.Apply(&opts)
+	s, err := scanner.New(opts)
+	if err != nil {
+		fatalf("diff-prompt: %v", err)
+	}
+
+	var versions [2]struct {
+		label   string
+		content string
+	}
+	for i, ref := range refs {
+		prompt, err := findPromptAtRef(s, target, ref, *id)
+		if err != nil {
+			fatalf("diff-prompt: %v", err)
+		}
+		versions[i].label = "/dev/null"
+		if prompt != nil {
+			versions[i].label = fmt.Sprintf("%s:%s:%d", ref, prompt.Filepath, prompt.Line)
+			versions[i].content = prompt.Content
+		}
+	}
+	if versions[0].label == "/dev/null" && versions[1].label == "/dev/null" {
+		fatalf("diff-prompt: no finding with id %s at %s or %s", *id, refs[0], refs[1])
+	}
+	diff := scanner.UnifiedDiff(versions[0].label, versions[1].label, versions[0].content, versions[1].content)
+	if diff == "" {
+		log.Printf("Prompt %s is identical at %s and %s.", *id, refs[0], refs[1])
+		return
+	}
+	fmt.Print(diff)
+}
+
+// findPromptAtRef scans target at ref and returns the finding with the given id, or nil if the
+// prompt does not exist there. Filepath is made relative to the repository root.
+func findPromptAtRef(s *scanner.Scanner, target, ref, id string) (*scanner.FoundPrompt, error) {
+	fetchRef := ref
+	if !looksLikeGitHubURL(target) {
+		// Local refs may be expressions like HEAD~1, which cannot be fetched by name.
+		sha, err := s.ResolveRef(target, ref)
+		if err != nil {
+			return nil, err
+		}
+		fetchRef = sha
+	}
+	dir, err := s.CloneRepoAtRef(target, fetchRef)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	prompts, err := s.ScanDirectory(dir)
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s at %s: %w", target, ref, err)
+	}
+	for _, p := range prompts {
+		if p.ID == id {
+			if rel, err := filepath.Rel(dir, p.Filepath); err == nil {
+				p.Filepath = filepath.ToSlash(rel)
+			}
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+// runCheckCommand runs the heuristics on a snippet given as an argument, on stdin or on the
+// clipboard, and prints the verdict, score and matched signals of every candidate string in it.
+func runCheckCommand(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	lang := fs.String("lang", "", "Language name or extension of the snippet (e.g. ts, python, yaml). Default: the snippet is one bare string.")
+	fromClipboard := fs.Bool("clipboard", false, "Check the text on the system clipboard.")
+	minLength := fs.Int("min-len", scanner.DefaultMinLength, "Minimum character length for a string to be considered a potential prompt.")
+	varKeywordsStr := fs.String("var-keywords", scanner.DefaultVarKeywords, "Comma-separated keywords for variable or key names.")
+	contentKeywordsStr := fs.String("content-keywords", scanner.DefaultContentKeywords, "Comma-separated keywords to search for within string content.")
+	placeholderPatternsStr := fs.String("placeholder-patterns", scanner.DefaultPlaceholderPatterns, "Comma-separated regex patterns to identify templating placeholders.")
+	langConfigPath := fs.String("lang-config", "", "YAML file overriding -min-len and keyword sets per language or file extension.")
+	presets := addPresetFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s check [options] <snippet>\n  %s check [options] -          (read the snippet from stdin)\n  %s check [options] -clipboard\n\nRuns the heuristics on a snippet and prints the verdict, score and matched signals.\n\nOptions:\n", filepath.Base(os.Args[0]), filepath.Base(os.Args[0]), filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var snippet []byte
+	var err error
+	switch {
+	case *fromClipboard && fs.NArg() == 0:
+		snippet, err = readClipboard()
+	case !*fromClipboard && fs.NArg() == 1 && fs.Arg(0) == "-":
+		snippet, err = io.ReadAll(os.Stdin)
+	case !*fromClipboard && fs.NArg() == 1:
+		snippet = []byte(fs.Arg(0))
+	default:
+		fs.Usage()
+		os.Exit(scanner.ExitError)
+	}
+	if err != nil {
+		fatalf("check: %v", err)
+	}
+
+	opts := scanner.ScanOptions{
+		MinLength:           *minLength,
+		VariableKeywords:    splitAndTrim(*varKeywordsStr),
+		ContentKeywords:     splitAndTrim(*contentKeywordsStr),
+		PlaceholderPatterns: splitAndTrim(*placeholderPatternsStr),
+	}
+	preset, err := presets.resolve()
+	if err != nil {
+		fatalf("check: %v", err)
+	}
+	applyPreset(fs, preset, &opts)
+	if *langConfigPath != "" {
+		overrides, err := scanner.LoadLanguageOverrides(*langConfigPath)
+		if err != nil {
+			fatalf("check: %v", err)
+		}
+		opts.LanguageOverrides = overrides
+	}
+	s, err := scanner.New(opts)
+	if err != nil {
+		fatalf("check: %v", err)
+	}
+	verdicts, err := s.Check(snippet, *lang)
+	if err != nil {
+		fatalf("check: %v", err)
+	}
+	if len(verdicts) == 0 {
+		fmt.Println("No candidate strings found in the snippet.")
+		return
+	}
+	mode := preset.Name
+	for i, v := range verdicts {
+		if i > 0 {
+			fmt.Println()
+		}
+		verdict := "not a prompt (" + v.RejectReason + ")"
+		if v.IsPrompt {
+			verdict = "PROMPT"
+		}
+		location := fmt.Sprintf("Line %d", v.Line)
+		if v.VariableName != "" {
+			location += " (" + v.VariableName + ")"
+		}
+		fmt.Printf("%s: %s [%s mode, score %d]\n", location, verdict, mode, v.Score)
+		fmt.Printf("  %q\n", v.Content)
+		for _, signal := range v.ScoreSignals {
+			fmt.Printf("  %+d  %s\n", signal.Points, signal.Label)
+		}
+		for _, matched := range v.Matched {
+			fmt.Printf("  matched: %s\n", matched)
+		}
+	}
+}
+
+// runQueryCommand implements "query", which searches the string literals recorded by a scan run
+// with -index without parsing the code again.
+func runQueryCommand(args []string) {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	indexPath := fs.String("index", "", "Index file written by a scan run with -index.")
+	grep := fs.String("grep", "", "Regular expression the literal must match (RE2 syntax; prefix with (?i) to ignore case).")
+	promptsOnly := fs.Bool("prompts-only", false, "Only search literals the indexing scan reported as prompts.")
+	limit := fs.Int("limit", 0, "Stop after this many matches (0 for no limit).")
+	format := fs.String("format", "text", "Output format: text (path:line: matching line) or json.")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s query -index <file> [options] [text...]\n\nLists the indexed string literals containing text (case-insensitive) and matching -grep.\n\nOptions:\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *indexPath == "" || (fs.NArg() == 0 && *grep == "") {
+		fs.Usage()
+		os.Exit(scanner.ExitError)
+	}
+	if *format != "text" && *format != "json" {
+		fatalf("query: unknown -format %q, expected text or json", *format)
+	}
+
+	q := scanner.IndexQuery{Text: strings.Join(fs.Args(), " "), PromptsOnly: *promptsOnly, Limit: *limit}
+	if *grep != "" {
+		pattern, err := regexp.Compile(*grep)
+		if err != nil {
+			fatalf("query: invalid -grep pattern: %v", err)
+		}
+		q.Pattern = pattern
+	}
+	index, err := scanner.LoadLiteralIndex(*indexPath)
+	if err != nil {
+		fatalf("query: %v", err)
+	}
+	matches := index.Query(q)
+
+	paths := make([]string, len(matches))
+	for i, m := range matches {
+		paths[i] = m.Path
+	}
+	if stale := index.StaleFiles(paths); len(stale) > 0 {
+		log.Printf("Warning: %d matched file(s) changed since the index was built at %s (e.g. %s); rerun the scan with -index to refresh it.",
+			len(stale), index.BuiltAt.Local().Format(time.DateTime), stale[0])
+	}
+
+	if *format == "json" {
+		if matches == nil {
+			matches = []scanner.IndexMatch{}
+		}
+		data, err := json.MarshalIndent(matches, "", "  ")
+		if err != nil {
+			fatalf("query: %v", err)
+		}
+		fmt.Println(string(data))
+		return
+	}
+	if len(matches) == 0 {
+		log.Printf("No indexed literals match.")
+		return
+	}
+	for _, m := range matches {
+		fmt.Printf("%s:%d: %s\n", m.Path, m.MatchLine, m.MatchText)
+	}
+}
+
+// runVerifyCommand checks the detached signature of a report written with -sign. It exits with
+// status 1 when the signature does not match.
+func runVerifyCommand(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	keyPath := fs.String("key", "", "Ed25519 public key (PEM) matching the -sign key.")
+	sigPath := fs.String("sig", "", "Signature file (default: the report path with a .sig suffix).")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s verify -key <public.pem> [-sig <file>] <report>\n\nChecks that a report written with -output and -sign has not been modified.\n\nOptions:\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *keyPath == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(scanner.ExitError)
+	}
+	report := fs.Arg(0)
+	if *sigPath == "" {
+		*sigPath = report + scanner.SignatureSuffix
+	}
+	key, err := scanner.LoadVerifyingKey(*keyPath)
+	if err != nil {
+		fatalf("verify: %v", err)
+	}
+	if err := scanner.VerifyFile(key, report, *sigPath); err != nil {
+		if errors.Is(err, scanner.ErrBadSignature) {
+			log.Printf("verify: %v", err)
+			os.Exit(scanner.ExitFindings)
+		}
+		fatalf("verify: %v", err)
+	}
+	fmt.Printf("%s: signature OK\n", report)
+}
+
+// runPruneCommand removes the baseline entries whose prompts are no longer in the scanned tree.
+func runPruneCommand(args []string) {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	baselinePath := fs.String("baseline", "", "Baseline file to prune.")
+	dryRun := fs.Bool("dry-run", false, "List the entries that would be removed without changing the baseline.")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s prune -baseline <file> [-dry-run] [directory]\n\nRemoves baseline entries whose file is gone or no longer contains the prompt. The directory\n(default: the current one) must be the one the baseline was created for.\n\nOptions:\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *baselinePath == "" || fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(scanner.ExitError)
+	}
+	root := "."
+	if fs.NArg() == 1 {
+		root = fs.Arg(0)
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		fatalf("prune: %v", err)
+	}
+
+	baseline, err := scanner.LoadBaseline(*baselinePath)
+	if err != nil {
+		fatalf("prune: %v", err)
+	}
+	// Every kind of file a baseline can name is parsed; the heuristics do not matter, since all
+	// candidate literals are compared with the entries.
+	s, err := scanner.New(scanner.ScanOptions{MinLength: scanner.DefaultMinLength, ScanConfigs: true, ScanText: true})
+	if err != nil {
+		fatalf("prune: %v", err)
+	}
+	total := len(baseline.Findings)
+	removed := s.PruneBaseline(baseline, root)
+	for _, entry := range removed {
+		fmt.Printf("%s:%d: %s\n", entry.Path, entry.Line, entry.Preview)
+	}
+	if *dryRun {
+		log.Printf("Would remove %d of %d entries from %s.", len(removed), total, *baselinePath)
+		return
+	}
+	if len(removed) > 0 {
+		if err := baseline.Write(*baselinePath); err != nil {
+			fatalf("prune: %v", err)
+		}
+	}
+	log.Printf("Removed %d of %d entries from %s.", len(removed), total, *baselinePath)
+}
+
+// runExtractCommand writes every prompt found in a directory or repository to its own file, with
+// a manifest mapping the files back to their source.
+func runExtractCommand(args []string) {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	outDir := fs.String("out", "extracted-prompts", "Directory to write the prompt files and "+scanner.ExtractManifestFile+" to.")
+	ref := fs.String("ref", "", "Branch, tag or commit SHA to check out when extracting from a GitHub URL.")
+	minLength := fs.Int("min-len", scanner.DefaultMinLength, "Minimum character length for a string to be considered a potential prompt.")
+	contentKeywordsStr := fs.String("content-keywords", scanner.DefaultContentKeywords, "Comma-separated keywords to search for within string content.")
+	scanConfigs := fs.Bool("scan-configs", false, "Also extract prompts from JSON, YAML, TOML, XML and .env files.")
+	scanText := fs.Bool("scan-text", false, "Also extract whole-file prompts under prompts/ directories.")
+	useGitignore := fs.Bool("use-gitignore", false, "Skip files and directories listed in .gitignore files.")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging.")
+	var includes, excludes stringList
+	fs.Var(&includes, "include", "Only extract from files matching this glob, relative to the target (repeatable).")
+	fs.Var(&excludes, "exclude", "Skip files matching this glob, relative to the target (repeatable).")
+	presets := addPresetFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s extract [-out <dir>] [options] <directory_or_github_url>\n\nWrites each prompt to its own file under -out, mirroring the source tree (line 12 of\nsrc/agent.py becomes src/agent.py.L12.txt), and a %s mapping every file to its source\nlocation, commit, hash and placeholders.\n\nOptions:\n", filepath.Base(os.Args[0]), scanner.ExtractManifestFile)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(scanner.ExitError)
+	}
+	VLog = log.New(io.Discard, "", 0)
+	if *verbose {
+		VLog = log.New(os.Stderr, "", 0)
+	}
+
+	opts := scanner.ScanOptions{
+		MinLength:              *minLength,
+		VariableKeywords:       scanner.DefaultVarKeywordsList,
+		ContentKeywords:        splitAndTrim(*contentKeywordsStr),
+		PlaceholderPatterns:    scanner.DefaultPlaceholderPatternsList,
+		PromptFilenamePatterns: scanner.DefaultPromptFilenamePatternsList,
+		ScanConfigs:            *scanConfigs,
+		ScanText:               *scanText,
+		UseGitignore:           *useGitignore,
+		Verbose:                *verbose,
+		Include:                includes,
+		Exclude:                excludes,
+		MaxFileSize:            scanner.DefaultMaxFileSize,
+	}
+	preset, err := presets.resolve()
+	if err != nil {
+		fatalf("extract: %v", err)
+	}
+	applyPreset(fs, preset, &opts)
+	s, err := scanner.New(opts)
+	if err != nil {
+		fatalf("extract: %v", err)
+	}
+	target, err := resolveTarget(context.Background(), s, fs.Arg(0), *ref)
+	defer removeClones([]resolvedTarget{target})
+	if err != nil {
+		fatalf("extract: %v", err)
+	}
+	if info, err := os.Stat(target.path); err != nil || !info.IsDir() {
+		fatalf("extract: %s is not a directory", fs.Arg(0))
+	}
+	// Prompt files extracted into the scanned tree are not extracted again.
+	if skipOutputDir(&opts, target.path, *outDir) {
+		if s, err = scanner.New(opts); err != nil {
+			fatalf("extract: %v", err)
+		}
+	}
+
+	meta := scanner.ReportMeta{Target: target.display, Root: target.path, RepoWebURL: target.RepoWebURL, Commit: target.Commit, Ref: *ref}
+	if !target.clone {
+		if sha, err := s.HeadCommit(target.path); err == nil {
+			meta.Commit = sha
+		}
+	}
+	prompts, err := s.ScanDirectory(target.path)
+	if err != nil {
+		fatalf("extract: %v", err)
+	}
+	scanner.SortFindings(prompts)
+	manifest, err := scanner.Extract(*outDir, meta, version, prompts)
+	if err != nil {
+		fatalf("extract: %v", err)
+	}
+	log.Printf("Extracted %d prompts to %s (manifest: %s).", len(manifest.Prompts), *outDir, filepath.Join(*outDir, scanner.ExtractManifestFile))
+}
+
+// runStagedCommand scans the files staged in a git repository, as they are in the index, and
+// exits with ExitFindings when they hold prompts that are not in -baseline, for pre-commit hooks.
+func runStagedCommand(args []string) {
+	fs := flag.NewFlagSet("staged", flag.ExitOnError)
+	format := fs.String("format", "text", "Output format: text, json, ndjson, markdown, problem-matcher, quickfix or junit.")
+	baselinePath := fs.String("baseline", "", "Only fail on findings not recorded in this baseline file.")
+	minLength := fs.Int("min-len", scanner.DefaultMinLength, "Minimum character length for a string to be considered a potential prompt.")
+	contentKeywordsStr := fs.String("content-keywords", scanner.DefaultContentKeywords, "Comma-separated keywords to search for within string content.")
+	scanConfigs := fs.Bool("scan-configs", false, "Also scan staged JSON, YAML, TOML, XML and .env files.")
+	scanText := fs.Bool("scan-text", false, "Also report whole-file prompts under prompts/ directories.")
+	parseTimeout := fs.Duration("parse-timeout", scanner.DefaultParseTimeout, "Give up on files that take longer than this to parse, such as huge generated sources (e.g. 10s; 0 for no limit).")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging.")
+	configFile := fs.String("config", "", "Project configuration file setting options and per-path overrides (default: .prompt-scanner.yaml in the repository directory, if present). Options staged does not take are ignored.")
+	var includes, excludes stringList
+	fs.Var(&includes, "include", "Only scan staged files matching this glob, relative to the repository root (repeatable).")
+	fs.Var(&excludes, "exclude", "Skip staged files matching this glob, relative to the repository root (repeatable).")
+	presets := addPresetFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s staged [-baseline <file>] [options] [repository_dir]\n\nScans the files staged in the git repository (default: the current directory) with the\ncontent they are about to be committed with, and exits with status %d when they hold new\npotential prompts, so it can run as a pre-commit hook.\n\nOptions:\n", filepath.Base(os.Args[0]), scanner.ExitFindings)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(scanner.ExitError)
+	}
+	repoDir := "."
+	if fs.NArg() == 1 {
+		repoDir = fs.Arg(0)
+	}
+	var pathOverrides []scanner.PathOverride
+	if configPath := findProjectConfig(*configFile, repoDir); configPath != "" {
+		overrides, err := loadProjectConfig(fs, configPath, true)
+		if err != nil {
+			fatalf("staged: %v", err)
+		}
+		pathOverrides = overrides
+	}
+	VLog = log.New(io.Discard, "", 0)
+	if *verbose {
+		VLog = log.New(os.Stderr, "", 0)
+	}
+
+	opts := scanner.ScanOptions{
+		MinLength:              *minLength,
+		VariableKeywords:       scanner.DefaultVarKeywordsList,
+		ContentKeywords:        splitAndTrim(*contentKeywordsStr),
+		PlaceholderPatterns:    scanner.DefaultPlaceholderPatternsList,
+		PromptFilenamePatterns: scanner.DefaultPromptFilenamePatternsList,
+		ScanConfigs:            *scanConfigs,
+		ScanText:               *scanText,
+		Verbose:                *verbose,
+		Include:                includes,
+		Exclude:                excludes,
+		MaxFileSize:            scanner.DefaultMaxFileSize,
+		ParseTimeout:           *parseTimeout,
+		PathOverrides:          pathOverrides,
+	}
+	if *baselinePath != "" {
+		baseline, err := scanner.LoadBaseline(*baselinePath)
+		if err != nil {
+			fatalf("staged: %v", err)
+		}
+		opts.Baseline = baseline
+	}
+	preset, err := presets.resolve()
+	if err != nil {
+		fatalf("staged: %v", err)
+	}
+	applyPreset(fs, preset, &opts)
+	s, err := scanner.New(opts)
+	if err != nil {
+		fatalf("staged: %v", err)
+	}
+	prompts, dir, files, err := scanStaged(s, repoDir)
+	if err != nil {
+		fatalf("staged: %v", err)
+	}
+	if files == 0 {
+		VLog.Println("No staged files.")
+		return
+	}
+	reporter, err := scanner.NewReporter(strings.ToLower(*format), scanner.ReporterOptions{Writer: os.Stdout, ToolVersion: version, Weights: scanner.DefaultSeverityWeights})
+	if err != nil {
+		fatalf("staged: %v", err)
+	}
+	// Findings are shown relative to the checkout, which is laid out like the repository root.
+	meta := scanner.ReportMeta{Target: "staged changes", Root: dir}
+	if err := scanner.ReportAll(reporter, meta, prompts); err != nil {
+		fatalf("staged: %v", err)
+	}
+	if len(prompts) > 0 {
+		log.Printf("Found %d new potential prompts in %d staged files.", len(prompts), files)
+		os.Exit(scanner.ExitFindings)
+	}
+}
+
+// scanStaged scans the files staged in the repository holding repoDir from a temporary checkout of
+// the index, which it removes again. It returns the findings, the checkout they were found in and
+// the number of staged files.
+func scanStaged(s *scanner.Scanner, repoDir string) ([]scanner.FoundPrompt, string, int, error) {
+	dir, staged, err := s.CheckoutStaged(repoDir)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	defer os.RemoveAll(dir)
+	if len(staged) == 0 {
+		return nil, dir, 0, nil
+	}
+	VLog.Printf("Scanning %d staged files", len(staged))
+	prompts, err := s.ScanDirectory(dir)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	scanner.SortFindings(prompts)
+	return prompts, dir, len(staged), nil
+}
+
+// skipOutputDir makes opts skip outDir when it lies inside root, so files written there by an
+// earlier run are not picked up as prompts. It reports whether opts changed.
+func skipOutputDir(opts *scanner.ScanOptions, root, outDir string) bool {
+	absOut, err := filepath.Abs(outDir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, absOut)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	opts.PathOverrides = append(opts.PathOverrides, scanner.PathOverride{Paths: []string{filepath.ToSlash(rel) + "/**"}, Skip: true})
+	return true
+}
+
+// runExportCommand writes each prompt to its own file with a front matter header, as a starting
+// point for a prompt library.
+func runExportCommand(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	outDir := fs.String("out-dir", "prompts", "Directory to write the prompt files to.")
+	ext := fs.String("ext", "md", "Extension of the prompt files: md or txt.")
+	overwrite := fs.Bool("overwrite", false, "Replace prompt files that already exist; by default they are kept, so edited prompts are not lost.")
+	ref := fs.String("ref", "", "Branch, tag or commit SHA to check out when exporting from a GitHub URL.")
+	minLength := fs.Int("min-len", scanner.DefaultMinLength, "Minimum character length for a string to be considered a potential prompt.")
+	contentKeywordsStr := fs.String("content-keywords", scanner.DefaultContentKeywords, "Comma-separated keywords to search for within string content.")
+	scanConfigs := fs.Bool("scan-configs", false, "Also export prompts from JSON, YAML, TOML, XML and .env files.")
+	scanText := fs.Bool("scan-text", false, "Also export whole-file prompts under prompts/ directories.")
+	useGitignore := fs.Bool("use-gitignore", false, "Skip files and directories listed in .gitignore files.")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging.")
+	var includes, excludes stringList
+	fs.Var(&includes, "include", "Only export from files matching this glob, relative to the target (repeatable).")
+	fs.Var(&excludes, "exclude", "Skip files matching this glob, relative to the target (repeatable).")
+	presets := addPresetFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s export [-out-dir <dir>] [options] <directory_or_github_url>\n\nWrites each prompt to its own file under -out-dir, named after its source file and variable\n(SYSTEM_PROMPT in src/agent.py becomes agent-system_prompt.md), with a front matter header\nholding the source file, line, variable, symbol and SHA-256 of the prompt.\n\nOptions:\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(scanner.ExitError)
+	}
+	VLog = log.New(io.Discard, "", 0)
+	if *verbose {
+		VLog = log.New(os.Stderr, "", 0)
+	}
+	if *ext != "md" && *ext != "txt" {
+		fatalf("export: invalid -ext '%s': expected md or txt", *ext)
+	}
+
+	opts := scanner.ScanOptions{
+		MinLength:              *minLength,
+		VariableKeywords:       scanner.DefaultVarKeywordsList,
+		ContentKeywords:        splitAndTrim(*contentKeywordsStr),
+		PlaceholderPatterns:    scanner.DefaultPlaceholderPatternsList,
+		PromptFilenamePatterns: scanner.DefaultPromptFilenamePatternsList,
+		ScanConfigs:            *scanConfigs,
+		ScanText:               *scanText,
+		UseGitignore:           *useGitignore,
+		Verbose:                *verbose,
+		Include:                includes,
+		Exclude:                excludes,
+		MaxFileSize:            scanner.DefaultMaxFileSize,
+	}
+	preset, err := presets.resolve()
+	if err != nil {
+		fatalf("export: %v", err)
+	}
+	applyPreset(fs, preset, &opts)
+	s, err := scanner.New(opts)
+	if err != nil {
+		fatalf("export: %v", err)
+	}
+	target, err := resolveTarget(context.Background(), s, fs.Arg(0), *ref)
+	defer removeClones([]resolvedTarget{target})
+	if err != nil {
+		fatalf("export: %v", err)
+	}
+	if info, err := os.Stat(target.path); err != nil || !info.IsDir() {
+		fatalf("export: %s is not a directory", fs.Arg(0))
+	}
+	// Prompt files exported into the scanned tree are not exported again.
+	if skipOutputDir(&opts, target.path, *outDir) {
+		if s, err = scanner.New(opts); err != nil {
+			fatalf("export: %v", err)
+		}
+	}
+
+	meta := scanner.ReportMeta{Target: target.display, Root: target.path, RepoWebURL: target.RepoWebURL, Commit: target.Commit, Ref: *ref}
+	if !target.clone {
+		if sha, err := s.HeadCommit(target.path); err == nil {
+			meta.Commit = sha
+		}
+	}
+	prompts, err := s.ScanDirectory(target.path)
+	if err != nil {
+		fatalf("export: %v", err)
+	}
+	scanner.SortFindings(prompts)
+	exported, err := scanner.Export(*outDir, meta, prompts, scanner.ExportOptions{Extension: "." + *ext, Overwrite: *overwrite})
+	if err != nil {
+		fatalf("export: %v", err)
+	}
+	kept := 0
+	for _, p := range exported {
+		if p.Kept {
+			kept++
+			VLog.Printf("Kept existing %s (%s:%d)", filepath.Join(*outDir, p.File), p.Source, p.Line)
+		}
+	}
+	log.Printf("Exported %d prompts to %s.", len(exported)-kept, *outDir)
+	if kept > 0 {
+		log.Printf("Kept %d existing prompt files; pass -overwrite to replace them.", kept)
+	}
+}
+
+// runInventoryCommand prints the distinct prompts of a target, each with every place it is found.
+func runInventoryCommand(args []string) {
+	fs := flag.NewFlagSet("inventory", flag.ExitOnError)
+	format := fs.String("format", "text", "Output format: text, json or markdown.")
+	minLocations := fs.Int("min-locations", 1, "Only list prompts found in at least this many places (2 lists the duplicated ones).")
+	ref := fs.String("ref", "", "Branch, tag or commit SHA to check out when scanning a GitHub URL.")
+	minLength := fs.Int("min-len", scanner.DefaultMinLength, "Minimum character length for a string to be considered a potential prompt.")
+	contentKeywordsStr := fs.String("content-keywords", scanner.DefaultContentKeywords, "Comma-separated keywords to search for within string content.")
+	scanConfigs := fs.Bool("scan-configs", false, "Also scan JSON, YAML, TOML, XML and .env files.")
+	scanText := fs.Bool("scan-text", false, "Also scan whole-file prompts under prompts/ directories.")
+	useGitignore := fs.Bool("use-gitignore", false, "Skip files and directories listed in .gitignore files.")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging.")
+	var includes, excludes stringList
+	fs.Var(&includes, "include", "Only scan files matching this glob, relative to the target (repeatable).")
+	fs.Var(&excludes, "exclude", "Skip files matching this glob, relative to the target (repeatable).")
+	presets := addPresetFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s inventory [-format text|json|markdown] [options] <directory_or_github_url>\n\nLists every distinct prompt of the target once, with all the places it is found. Findings\nare the same prompt when their text is equal up to whitespace (their fingerprint matches).\n\nOptions:\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(scanner.ExitError)
+	}
+	VLog = log.New(io.Discard, "", 0)
+	if *verbose {
+		VLog = log.New(os.Stderr, "", 0)
+	}
+	if *format != "text" && *format != "json" && *format != "markdown" {
+		fatalf("inventory: unknown -format '%s': expected text, json or markdown", *format)
+	}
+
+	opts := scanner.ScanOptions{
+		MinLength:              *minLength,
+		VariableKeywords:       scanner.DefaultVarKeywordsList,
+		ContentKeywords:        splitAndTrim(*contentKeywordsStr),
+		PlaceholderPatterns:    scanner.DefaultPlaceholderPatternsList,
+		PromptFilenamePatterns: scanner.DefaultPromptFilenamePatternsList,
+		ScanConfigs:            *scanConfigs,
+		ScanText:               *scanText,
+		UseGitignore:           *useGitignore,
+		Verbose:                *verbose,
+		Include:                includes,
+		Exclude:                excludes,
+		MaxFileSize:            scanner.DefaultMaxFileSize,
+	}
+	preset, err := presets.resolve()
+	if err != nil {
+		fatalf("inventory: %v", err)
+	}
+	applyPreset(fs, preset, &opts)
+	s, err := scanner.New(opts)
+	if err != nil {
+		fatalf("inventory: %v", err)
+	}
+	target, err := resolveTarget(context.Background(), s, fs.Arg(0), *ref)
+	defer removeClones([]resolvedTarget{target})
+	if err != nil {
+		fatalf("inventory: %v", err)
+	}
+	meta := scanner.ReportMeta{Target: target.display, Root: target.path, RepoWebURL: target.RepoWebURL, Commit: target.Commit, Ref: *ref}
+	if !target.clone {
+		if sha, err := s.HeadCommit(target.path); err == nil {
+			meta.Commit = sha
+		}
+	}
+	prompts, err := s.ScanDirectory(target.path)
+	if err != nil {
+		fatalf("inventory: %v", err)
+	}
+	scanner.SortFindings(prompts)
+	inventory := scanner.BuildInventory(meta, version, prompts)
+	distinct := len(inventory.Prompts)
+	entries := inventory.Prompts[:0]
+	for _, entry := range inventory.Prompts {
+		if len(entry.Locations) >= *minLocations {
+			entries = append(entries, entry)
+		}
+	}
+	inventory.Prompts = entries
+
+	switch *format {
+	case "json":
+		data, errJSON := json.MarshalIndent(inventory, "", "  ")
+		if errJSON != nil {
+			fatalf("inventory: %v", errJSON)
+		}
+		_, err = fmt.Println(string(data))
+	case "markdown":
+		err = inventory.WriteMarkdown(os.Stdout)
+	default:
+		err = inventory.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fatalf("inventory: %v", err)
+	}
+	log.Printf("Found %d distinct prompts in %d findings.", distinct, inventory.TotalFindings)
+	if len(inventory.Prompts) < distinct {
+		log.Printf("Listed the %d found in at least %d places.", len(inventory.Prompts), *minLocations)
+	}
+}
+
+// runSyncCheckCommand fails when the source tree has drifted from the prompts written by extract:
+// prompt files that are gone or no longer referenced, and prompts pasted back inline.
+func runSyncCheckCommand(args []string) {
+	fs := flag.NewFlagSet("sync-check", flag.ExitOnError)
+	manifestPath := fs.String("manifest", filepath.Join("extracted-prompts", scanner.ExtractManifestFile), "Manifest written by extract; the prompt files are read relative to its directory.")
+	useGitignore := fs.Bool("use-gitignore", false, "Skip files and directories listed in .gitignore files.")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging.")
+	var includes, excludes stringList
+	fs.Var(&includes, "include", "Only check files matching this glob, relative to the directory (repeatable).")
+	fs.Var(&excludes, "exclude", "Skip files matching this glob, relative to the directory (repeatable).")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s sync-check [-manifest <file>] [directory]\n\nChecks that the source in the directory (default: the current one) still uses the prompts\nwritten by extract: every prompt file must exist and be referenced by its path or ID, and no\nstring literal may hold a prompt's text again. Exits with status 1 on drift.\n\nOptions:\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(scanner.ExitError)
+	}
+	root := "."
+	if fs.NArg() == 1 {
+		root = fs.Arg(0)
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		fatalf("sync-check: %v", err)
+	}
+
+	manifest, err := scanner.LoadExtractManifest(*manifestPath)
+	if err != nil {
+		fatalf("sync-check: %v", err)
+	}
+	// References and copies can be in any kind of file; the heuristics do not matter, since all
+	// candidate literals are compared with the prompts.
+	s, err := scanner.New(scanner.ScanOptions{
+		MinLength:    scanner.DefaultMinLength,
+		ScanConfigs:  true,
+		ScanText:     true,
+		UseGitignore: *useGitignore,
+		Verbose:      *verbose,
+		Include:      includes,
+		Exclude:      excludes,
+		MaxFileSize:  scanner.DefaultMaxFileSize,
+	})
+	if err != nil {
+		fatalf("sync-check: %v", err)
+	}
+	issues, err := s.SyncCheck(root, filepath.Dir(*manifestPath), manifest)
+	if err != nil {
+		fatalf("sync-check: %v", err)
+	}
+	for _, issue := range issues {
+		switch issue.Kind {
+		case scanner.SyncMissingFile:
+			fmt.Printf("%s: prompt file is missing\n", issue.File)
+		case scanner.SyncUnreferenced:
+			fmt.Printf("%s: not referenced by the source\n", issue.File)
+		case scanner.SyncInlineCopy:
+			fmt.Printf("%s:%d: inline copy of %s\n", issue.Path, issue.Line, issue.File)
+		}
+	}
+	if len(issues) > 0 {
+		log.Printf("%d of %d extracted prompts have drifted (%d issues).", driftedPrompts(issues), len(manifest.Prompts), len(issues))
+		os.Exit(scanner.ExitFindings)
+	}
+	log.Printf("All %d extracted prompts are in sync.", len(manifest.Prompts))
+}
+
+// driftedPrompts counts the distinct prompt files named by issues.
+func driftedPrompts(issues []scanner.SyncIssue) int {
+	files := make(map[string]bool)
+	for _, issue := range issues {
+		files[issue.File] = true
+	}
+	return len(files)
+}
@@ -0,0 +1,138 @@
+// config.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alexferrari88/prompt-scanner/scanner"
+	"gopkg.in/yaml.v3"
+)
+
+// projectConfigFile is the project configuration discovered at the root of a scanned directory.
+const projectConfigFile = ".prompt-scanner.yaml"
+
+// projectConfig is the layout of a project configuration file. Every key except "overrides" is a
+// command-line option without its dash:
+//
+//	min-len: 40
+//	scan-configs: true
+//	content-keywords: ["you are", "act as"]   # list values of comma-separated options are joined
+//	label: [team=search, env=prod]            # repeatable options take one value per item
+//	exclude: ["**/fixtures/**", "{a,b}/*.py"] # so globs may contain commas
+//	overrides:
+//	  - paths: ["tests/**", "**/fixtures/**"]
+//	    skip: true
+//	  - paths: ["docs/**"]
+//	    min_length: 80
+type projectConfig struct {
+	Options   map[string]any         `yaml:",inline"`
+	Overrides []scanner.PathOverride `yaml:"overrides"`
+}
+
+// findProjectConfig returns the configuration file to load: explicitPath when set, otherwise the
+// projectConfigFile at the root of target if target is a local directory that has one.
+func findProjectConfig(explicitPath, target string) string {
+	if explicitPath != "" {
+		return explicitPath
+	}
+	if target == "" || looksLikeGitHubURL(target) {
+		return ""
+	}
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return ""
+	}
+	candidate := filepath.Join(target, projectConfigFile)
+	if _, err := os.Stat(candidate); err != nil {
+		return ""
+	}
+	return candidate
+}
+
+// loadProjectConfig reads the configuration file at path and applies its options to the flags of
+// fs that were not set on the command line, which always win. It returns the path overrides.
+// Options fs does not define are an error, unless ignoreUnknown is set for subcommands that take
+// only some of the scan options of a configuration shared with the scan command.
+func loadProjectConfig(fs *flag.FlagSet, path string, ignoreUnknown bool) ([]scanner.PathOverride, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	var config projectConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	setOnCommandLine := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { setOnCommandLine[f.Name] = true })
+
+	names := make([]string, 0, len(config.Options))
+	for name := range config.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil || name == "config" {
+			if ignoreUnknown {
+				continue
+			}
+			return nil, fmt.Errorf("config %s: unknown option '%s'", path, name)
+		}
+		if setOnCommandLine[name] {
+			continue
+		}
+		if err := setConfigOption(fs, f, config.Options[name]); err != nil {
+			return nil, fmt.Errorf("config %s: option '%s': %w", path, name, err)
+		}
+	}
+	return config.Overrides, nil
+}
+
+// setConfigOption sets the flag f of fs from a YAML value, so that fs.Visit sees it as set. Lists
+// are joined with commas, except for repeatable options such as -label or -include, which are set
+// once per item; a mapping sets -label pairs.
+func setConfigOption(fs *flag.FlagSet, f *flag.Flag, value any) error {
+	_, labels := f.Value.(labelMap)
+	_, list := f.Value.(*stringList)
+	repeatable := labels || list
+	set := func(v string) error { return fs.Set(f.Name, v) }
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		if !repeatable {
+			return set(strings.Join(items, ","))
+		}
+		for _, item := range items {
+			if err := set(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]any:
+		if !labels {
+			return fmt.Errorf("expected a single value or a list")
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := set(key + "=" + fmt.Sprint(v[key])); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return set(fmt.Sprint(v))
+	}
+}
@@ -3,9 +3,26 @@ module github.com/alexferrari88/prompt-scanner
 go 1.24.1
 
 require (
-	github.com/BurntSushi/toml v1.5.0
 	github.com/smacker/go-tree-sitter v0.0.0-20240827094217-dd81d9e9be82
 	gopkg.in/yaml.v3 v3.0.1
 )
 
-require github.com/sabhiram/go-gitignore v0.0.0-20210923224102-525f6e181f06
+require (
+	github.com/alecthomas/chroma/v2 v2.14.0
+	github.com/bmatcuk/doublestar/v4 v4.9.1
+	github.com/mattn/go-runewidth v0.0.16
+	github.com/mattn/go-sqlite3 v1.14.32
+	github.com/pelletier/go-toml/v2 v2.2.4
+	github.com/sabhiram/go-gitignore v0.0.0-20210923224102-525f6e181f06
+	github.com/xeipuuv/gojsonschema v1.2.0
+	golang.org/x/sync v0.16.0
+	golang.org/x/term v0.30.0
+)
+
+require (
+	github.com/dlclark/regexp2 v1.11.0 // indirect
+	github.com/rivo/uniseg v0.2.0 // indirect
+	github.com/xeipuuv/gojsonpointer v0.0.0-20180127040702-4e3ac2762d5f // indirect
+	github.com/xeipuuv/gojsonreference v0.0.0-20180127040603-bd5ef7bd5415 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+)
@@ -2,53 +2,167 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/alexferrari88/prompt-scanner/scanner"
+	"github.com/alexferrari88/prompt-scanner/utils"
+	"golang.org/x/sync/errgroup"
 )
 
 var (
 	// VLog is a global logger for verbose output. It's initialized in main.
 	VLog *log.Logger
+
+	// version is the release version, set at build time with -ldflags "-X main.version=...".
+	version = "dev"
 )
 
 func main() {
 	startTime := time.Now()
 	log.SetFlags(0) // Simpler logging for fatal errors and final summary (goes to stderr)
 
+	if runSubcommand(os.Args[1:]) {
+		return
+	}
+	// Registered first so it runs last, after deferred cleanup such as removing a cloned repository.
+	exitCode := scanner.ExitClean
+	defer func() {
+		if exitCode != scanner.ExitClean {
+			os.Exit(exitCode)
+		}
+	}()
+
 	// --- Define flags ---
 	// Output control
-	jsonOutput := flag.Bool("json", false, "Output results in JSON format.")
+	jsonOutput := flag.Bool("json", false, "Output results in JSON format (shorthand for -format json).")
+	format := flag.String("format", "text", "Output format: text, json, ndjson, envelope, markdown, html, pr-comment, problem-matcher, quickfix, gitlab-codequality, azure-devops, junit, dot, sqlite or template.")
+	compareWith := flag.String("compare-with", "", "Envelope report of the base branch; -format pr-comment then lists new, changed and removed prompts.")
+	prNumber := flag.Int("pr", 0, "Post the pr-comment report to this GitHub pull request, editing the earlier scan comment if there is one; -format still selects the regular output.")
+	githubToken := flag.String("github-token", os.Getenv("GITHUB_TOKEN"), "Token allowed to comment on pull requests, for -pr (default: $GITHUB_TOKEN).")
+	githubRepo := flag.String("github-repo", os.Getenv("GITHUB_REPOSITORY"), "Repository (owner/name) of the -pr pull request (default: $GITHUB_REPOSITORY).")
+	printSchema := flag.Bool("schema", false, "Print the JSON Schema for the json and envelope output formats and exit.")
+	printProblemMatcher := flag.Bool("problem-matcher", false, "Print the GitHub Actions problem matcher for the problem-matcher output format and exit.")
+	snippetLines := flag.Int("snippet-lines", 3, "Lines of source context shown around each finding in markdown and html reports.")
 	noFilepath := flag.Bool("no-filepath", false, "Omit the filepath from the default text output.")
 	noLinenumber := flag.Bool("no-linenumber", false, "Omit the line number from the default text output.")
+	multiline := flag.String("multiline", scanner.MultilineIndent, "How multi-line prompts are rendered in text output: indent, collapse or escape.")
+	escapeNewlines := flag.Bool("escape-newlines", false, "Print each prompt on a single line with newlines escaped as \\n (shorthand for -multiline escape).")
+	scoreWeightsStr := flag.String("score-weights", "", "Severity weights for the prompt hygiene score, e.g. 'high=10,medium=3,low=1'.")
+	historyPath := flag.String("history", "", "Append a summary of this run to the given history file (JSON lines) for 'report trend'.")
+	watch := flag.Bool("watch", false, "After the scan, keep watching the local directory and report prompts that appear in saved files.")
+	watchInterval := flag.Duration("watch-interval", 2*time.Second, "How often -watch checks files for changes.")
+	notify := flag.Bool("notify", false, "With -watch, show a desktop notification when new prompts appear.")
+	tui := flag.Bool("tui", false, "Show a live dashboard (per-language progress and latest findings) on stderr while scanning.")
 	verbose := flag.Bool("verbose", false, "Enable verbose logging output to stderr.")
 
 	// Scanning behavior
-	scanConfigs := flag.Bool("scan-configs", false, "Also scan common config files (JSON, YAML, TOML, .env).")
+	scanConfigs := flag.Bool("scan-configs", false, "Also scan common config files (JSON, YAML, TOML, XML, HCL/Terraform, .env).")
+	promptFilenamePatternsStr := flag.String("prompt-filename-patterns", scanner.DefaultPromptFilenamePatterns, "Comma-separated file name globs of config files scanned even without -scan-configs (empty to disable).")
+	constantsFiles := flag.Bool("constants-files", false, "Report every long, sentence-like string in constants modules (files that are mostly string assignments), not just keyword matches.")
+	maxFileSize := byteSize(scanner.DefaultMaxFileSize)
+	flag.Var(&maxFileSize, "max-file-size", "Skip files larger than this, such as bundles and data dumps (e.g. 500KB, 5MB; 0 for no limit).")
+	scanMinified := flag.Bool("scan-minified", false, "Also scan minified and bundled JavaScript (*.min.js, files ending with a sourceMappingURL comment or made of very long lines).")
+	parseTimeout := flag.Duration("parse-timeout", scanner.DefaultParseTimeout, "Give up on files that take longer than this to parse, such as huge generated sources (e.g. 10s; 0 for no limit).")
+	workers := flag.Int("workers", 0, "Number of files parsed concurrently (default: one per CPU). Lower it to throttle CPU use in shared CI runners.")
+	maxNestingDepth := flag.Int("max-nesting-depth", scanner.DefaultMaxNestingDepth, "How many levels of code held in config values or other code are parsed, e.g. 1 for a script in a YAML value (negative to evaluate such values as plain strings).")
+	scanText := flag.Bool("scan-text", false, "Also scan .txt, .prompt and extensionless files under prompts/ directories as whole-file prompts.")
+	ignoreKeysStr := flag.String("ignore-keys", "", "Comma-separated config keys to skip in JSON/YAML/TOML/.env scanning, e.g. 'description,help_text,changelog.*'.")
 	useGitignore := flag.Bool("use-gitignore", false, "Skip files and directories listed in .gitignore files.")
-	greedy := flag.Bool("greedy", false, "Use aggressive (current) heuristics if true. If false, use stricter rules based on content keywords and multi-line criteria.")
+	ref := flag.String("ref", "", "Branch, tag or commit SHA to check out when scanning a GitHub URL (default: the default branch).")
+	presets := addPresetFlags(flag.CommandLine)
 
 	// Heuristic tuning
+	lintOnly := flag.Bool("lint", false, "Only report prompts with lint issues (input_variables mismatches, broken placeholders).")
+	qualityLints := flag.Bool("quality-lints", false, "Also report advisory prompt-quality lints: very long sentences, contradictory instructions, invisible control characters.")
+	policyPath := flag.String("policy", "", "YAML file of token-budget rules (per model, provider or role) to check each prompt against.")
+	tokenizerPath := flag.String("tokenizer", "", "Count the tokens of findings with this tokenizer file (SentencePiece tokenizer.model or Hugging Face tokenizer.json) instead of estimating them.")
+	multilineOnly := flag.Bool("multiline-only", false, "Only report multi-line prompts, regardless of keyword matches.")
+	minLines := flag.Int("min-lines", 0, "Only report prompts with at least this many lines of content.")
 	minLength := flag.Int("min-len", scanner.DefaultMinLength, "Minimum character length for a string to be considered a potential prompt.")
 	varKeywordsStr := flag.String("var-keywords", scanner.DefaultVarKeywords, "Comma-separated keywords for variable or key names.")
 	contentKeywordsStr := flag.String("content-keywords", scanner.DefaultContentKeywords, "Comma-separated keywords to search for within string content.")
+	langConfigPath := flag.String("lang-config", "", "YAML file overriding -min-len and keyword sets per language or file extension.")
 	placeholderPatternsStr := flag.String("placeholder-patterns", scanner.DefaultPlaceholderPatterns, "Comma-separated regex patterns to identify templating placeholders.")
 
+	outputPath := flag.String("output", "-", "Write the results to this file instead of stdout ('-'). The file is replaced only once the report is complete.")
+	templateFile := flag.String("template-file", "", "Go text/template file rendering the findings for -format template.")
+	keywordReport := flag.Bool("keyword-report", false, "After the scan, show how many findings each variable/content keyword and placeholder pattern contributed to, and which never fired.")
+	adaptive := flag.Bool("adaptive", false, "Profile the string literals of the target before scanning and raise -min-len for languages whose literals run long.")
+	includeRejected := flag.Bool("include-rejected", false, "Also output strings that were considered but rejected, with a reason code (text, json, ndjson and envelope formats).")
+	failOnFound := flag.Bool("fail-on-found", false, "Exit with status 1 when any potential prompt is found (shorthand for -fail-threshold 0).")
+	failThreshold := flag.Int("fail-threshold", -1, "Exit with status 1 when more than this many potential prompts are found (-1 disables). Scan errors exit with status 2.")
+	baselinePath := flag.String("baseline", "", "Baseline file of known findings; only findings not in it are reported.")
+	warnUnused := flag.Int("warn-unused", 0, "With -baseline, count in the baseline file how many scans in a row each entry matched nothing, and warn about entries unused for this many scans (0 disables).")
+	updateBaseline := flag.Bool("update-baseline", false, "With -baseline, record all current findings in the baseline file (reporting those that are new).")
+	migrateBaseline := flag.Bool("migrate-baseline", false, "With -baseline, re-record the baseline when it was recorded with other built-in heuristics, listing the findings it absorbs on stderr instead of reporting them.")
+	uploadURL := flag.String("upload", "", "POST the envelope report to this URL (a prompt inventory service); failed uploads are spooled and retried on the next run.")
+	spoolDir := flag.String("spool-dir", "", "Directory holding -upload reports waiting to be delivered (default: prompt-scanner/spool under the user cache directory).")
+	webhookURL := flag.String("webhook-url", "", "POST the envelope report to this URL after the scan, retrying network errors, 429 and 5xx responses with backoff (no spool).")
+	webhookSecret := flag.String("webhook-secret", os.Getenv("PROMPT_SCANNER_WEBHOOK_SECRET"), "Sign -webhook-url deliveries with HMAC-SHA256 in the "+scanner.SignatureHeader+" header (default: $PROMPT_SCANNER_WEBHOOK_SECRET).")
+	shareStatsURL := flag.String("share-stats", "", "Opt in to POST anonymous, content-free statistics of the scan (finding counts per rule, language, severity and kind, and ignore-pragma counts) to this URL, to help tune the default heuristics. The payload is printed on stderr first.")
+	shareStatsPreview := flag.Bool("share-stats-preview", false, "Print the statistics -share-stats would send on stderr, without sending anything.")
+	apiKey := flag.String("api-key", os.Getenv("PROMPT_SCANNER_API_KEY"), "Bearer token for -upload (default: $PROMPT_SCANNER_API_KEY).")
+	project := flag.String("project", "", "Project name recorded on every finding, the envelope and the history record, for segmenting results in a central store.")
+	team := flag.String("team", "", "Owning team recorded on every finding, the envelope and the history record.")
+	labels := labelMap{}
+	flag.Var(labels, "label", "Attach a key=value label to every finding, the envelope and the history record (repeatable).")
+	tmpDir := flag.String("tmp-dir", "", "Directory for temporary files: repository clones and the GitHub problem matcher (default: $TMPDIR or the system temporary directory).")
+	noWrite := flag.Bool("no-write", false, "Never write to the filesystem; only stdout and stderr are used. Rejects options that write files and GitHub URL targets, and disables the -upload spool.")
+	signKey := flag.String("sign", "", "Ed25519 private key (PEM, PKCS #8) to sign the -output report with; the detached signature is written next to it with a .sig suffix.")
+	configFile := flag.String("config", "", "Project configuration file setting options and per-path overrides (default: .prompt-scanner.yaml in the target directory, if present). Command-line flags take precedence.")
+	reproducible := flag.Bool("reproducible", false, "Make reports byte-identical across scans of the same tree: findings sorted, no timestamps, and grammar and ruleset hashes recorded in the envelope.")
+	filesFrom := flag.String("files-from", "", "Scan only the files listed one per line in this file ('-' for stdin), relative to the target directory (default '.'), instead of walking it.")
+	var includes, excludes stringList
+	flag.Var(&includes, "include", "Only scan files matching this glob, relative to the target (e.g. 'src/**/*.py'; repeatable).")
+	flag.Var(&excludes, "exclude", "Skip files matching this glob, relative to the target (e.g. '**/fixtures/**'; repeatable).")
+	noDefaultExcludes := flag.Bool("no-default-excludes", false, "Also scan lockfiles (package-lock.json, yarn.lock, poetry.lock, ...), minified stylesheets, source maps and datasets, which are skipped by default.")
+	onlyFilesFrom := flag.String("only-files-from", "", "Only rescan the files with findings in this earlier json, ndjson or envelope report, to check whether they were fixed.")
+	indexPath := flag.String("index", "", "Write an index of every string literal in the target to this file, for instant searches with 'query'.")
+	traceHeuristics := flag.String("trace-heuristics", "", "Explain every heuristic rule evaluated for candidates at <file:line> (or every candidate in <file>) on stderr.")
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "LLM Prompt Scanner\nRecursively scans codebases for potential LLM prompts.\n\nUsage:\n  %s [options] <target_path_or_github_url>\n\nOptions:\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "LLM Prompt Scanner\nRecursively scans codebases for potential LLM prompts.\n\nUsage:\n  %s [options] <target_path_or_github_url>...\n  %[1]s report trend -history <file>|-db <file> [-format markdown|html]\n  %[1]s diff-prompt -id <finding-id> -ref <A> -ref <B> [<repo_path_or_url>]\n  %[1]s check [options] <snippet>|-|-clipboard\n  %[1]s dashboard [-out <dir>] <envelope.json>...\n  %[1]s query -index <file> [-grep <regex>] [text...]\n  %[1]s verify -key <public.pem> <report>\n  %[1]s prune -baseline <file> [directory]\n  %[1]s extract [-out <dir>] <directory_or_github_url>\n  %[1]s export [-out-dir <dir>] <directory_or_github_url>\n  %[1]s inventory [-format text|json|markdown] <directory_or_github_url>\n  %[1]s sync-check [-manifest <file>] [directory]\n  %[1]s serve [-addr host:port] [options]\n  %[1]s staged [-baseline <file>] [options] [repository_dir]\n\nOptions:\n", filepath.Base(os.Args[0]))
 		flag.PrintDefaults()
 	}
 	flag.Parse()
 
+	// The project configuration is only looked up in a single target; several share -config.
+	targetInputs := flag.Args()
+	if len(targetInputs) == 0 && *filesFrom != "" {
+		targetInputs = []string{"."}
+	}
+	configTarget := ""
+	if len(targetInputs) == 1 {
+		configTarget = targetInputs[0]
+	}
+	configPath := findProjectConfig(*configFile, configTarget)
+	var pathOverrides []scanner.PathOverride
+	if configPath != "" {
+		overrides, errConfig := loadProjectConfig(flag.CommandLine, configPath, false)
+		if errConfig != nil {
+			fatalf("Error loading -config: %v", errConfig)
+		}
+		pathOverrides = overrides
+	}
+	preset, err := presets.resolve()
+	if err != nil {
+		fatalf("Error: %v", err)
+	}
+
 	// Initialize VLog based on the verbose flag
 	if *verbose {
 		VLog = log.New(os.Stderr, "", 0) // Standard log output to stderr for verbose messages
@@ -56,81 +170,859 @@ func main() {
 		VLog = log.New(io.Discard, "", 0) // Discard verbose logs if not enabled
 	}
 
-	if flag.NArg() == 0 {
+	if configPath != "" {
+		VLog.Printf("Using configuration %s", configPath)
+	}
+
+	if *printSchema {
+		os.Stdout.Write(scanner.OutputSchema)
+		return
+	}
+	if *printProblemMatcher {
+		os.Stdout.Write(scanner.ProblemMatcher)
+		return
+	}
+
+	if len(targetInputs) == 0 {
 		flag.Usage()
-		os.Exit(1)
+		os.Exit(scanner.ExitError)
+	}
+
+	outputFormat := strings.ToLower(*format)
+	if *jsonOutput {
+		outputFormat = "json"
+	}
+	weights, err := scanner.ParseSeverityWeights(*scoreWeightsStr)
+	if err != nil {
+		fatalf("Error parsing -score-weights: %v", err)
+	}
+	// A SQLite database collects many scans, so it is added to in place instead of being replaced.
+	outputFile, databasePath := *outputPath, ""
+	if outputFormat == "sqlite" {
+		if *outputPath == "-" || *outputPath == "" {
+			fatalf("-format sqlite requires -output, the database file to add the scan to")
+		}
+		outputFile, databasePath = "-", *outputPath
+	}
+	output, err := scanner.CreateOutput(outputFile)
+	if err != nil {
+		fatalf("Error opening -output: %v", err)
+	}
+	reporterOpts := scanner.ReporterOptions{
+		Writer:       output,
+		NoFilepath:   *noFilepath,
+		NoLinenumber: *noLinenumber,
+		Multiline:    strings.ToLower(*multiline),
+		SnippetLines: *snippetLines,
+		ToolVersion:  version,
+		Weights:      weights,
+		DatabasePath: databasePath,
+	}
+	if *escapeNewlines {
+		reporterOpts.Multiline = scanner.MultilineEscape
+	}
+	// Inside GitHub Actions the problem matcher registers itself, so annotations need no extra setup.
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		reporterOpts.MatcherDir = os.Getenv("RUNNER_TEMP")
+		if *tmpDir != "" {
+			reporterOpts.MatcherDir = *tmpDir
+		} else if reporterOpts.MatcherDir == "" {
+			reporterOpts.MatcherDir = os.TempDir()
+		}
+	}
+	if *noWrite {
+		reporterOpts.MatcherDir = ""
+	}
+	if outputFormat == "template" {
+		if *templateFile == "" {
+			fatalf("-format template requires -template-file")
+		}
+		tmpl, err := scanner.ParseOutputTemplate(*templateFile)
+		if err != nil {
+			fatalf("Error loading -template-file: %v", err)
+		}
+		reporterOpts.Template = tmpl
+	}
+	if *compareWith != "" {
+		baseline, err := scanner.ReadEnvelope(*compareWith)
+		if err != nil {
+			fatalf("Error reading -compare-with report: %v", err)
+		}
+		reporterOpts.Baseline = &baseline
+	}
+	if !containsString(scanner.MultilineModes, reporterOpts.Multiline) {
+		fatalf("Unknown -multiline mode '%s'. Supported modes: %s", *multiline, strings.Join(scanner.MultilineModes, ", "))
+	}
+	if *includeRejected && !containsString([]string{"text", "json", "ndjson", "envelope"}, outputFormat) {
+		fatalf("-include-rejected is only supported with the text, json, ndjson and envelope formats")
+	}
+	if *includeRejected && *watch {
+		fatalf("-include-rejected cannot be combined with -watch")
+	}
+	if *outputPath != "-" && *outputPath != "" && *watch {
+		fatalf("-output cannot be combined with -watch")
+	}
+	if *updateBaseline && *baselinePath == "" {
+		fatalf("-update-baseline requires -baseline")
+	}
+	if *migrateBaseline && *baselinePath == "" {
+		fatalf("-migrate-baseline requires -baseline")
+	}
+	if *warnUnused > 0 && *baselinePath == "" {
+		fatalf("-warn-unused requires -baseline")
+	}
+	if *filesFrom != "" && *onlyFilesFrom != "" {
+		fatalf("-files-from cannot be combined with -only-files-from")
+	}
+	// A partial scan would drop the findings of every other file from the baseline.
+	for _, partial := range []struct {
+		name string
+		set  bool
+	}{{"-files-from", *filesFrom != ""}, {"-only-files-from", *onlyFilesFrom != ""}} {
+		if partial.set && (*updateBaseline || *migrateBaseline || *warnUnused > 0) {
+			fatalf("%s cannot be combined with -update-baseline, -migrate-baseline or -warn-unused", partial.name)
+		}
+	}
+	var listedFiles []string
+	if *filesFrom != "" {
+		if len(targetInputs) > 1 {
+			fatalf("-files-from needs a single target")
+		}
+		if listedFiles, err = readFileList(*filesFrom); err != nil {
+			fatalf("Error reading -files-from: %v", err)
+		}
+	}
+	var previousFindings []scanner.JSONOutput
+	if *onlyFilesFrom != "" {
+		if previousFindings, err = scanner.ReadFindings(*onlyFilesFrom); err != nil {
+			fatalf("Error reading -only-files-from report: %v", err)
+		}
+	}
+	if *uploadURL != "" {
+		if u, errURL := url.ParseRequestURI(*uploadURL); errURL != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			fatalf("Invalid -upload URL '%s': expected an http or https URL", *uploadURL)
+		}
+	}
+	if *webhookURL != "" {
+		if u, errURL := url.ParseRequestURI(*webhookURL); errURL != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			fatalf("Invalid -webhook-url '%s': expected an http or https URL", *webhookURL)
+		}
+	}
+	if *prNumber < 0 {
+		fatalf("Invalid -pr %d: expected a pull request number", *prNumber)
+	}
+	if *prNumber > 0 {
+		if *githubToken == "" {
+			fatalf("-pr requires -github-token or $GITHUB_TOKEN")
+		}
+		if owner, name, ok := strings.Cut(*githubRepo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			fatalf("-pr requires -github-repo or $GITHUB_REPOSITORY as owner/name, got '%s'", *githubRepo)
+		}
+	}
+	if *shareStatsURL != "" {
+		if u, errURL := url.ParseRequestURI(*shareStatsURL); errURL != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			fatalf("Invalid -share-stats URL '%s': expected an http or https URL", *shareStatsURL)
+		}
+	}
+	if *workers < 0 {
+		fatalf("Invalid -workers %d: expected a positive number, or 0 for one per CPU", *workers)
+	}
+	if *indexPath != "" && *watch {
+		fatalf("-index cannot be combined with -watch")
+	}
+	var signingKey ed25519.PrivateKey
+	if *signKey != "" {
+		if *outputPath == "-" || *outputPath == "" {
+			fatalf("-sign requires -output, the report file to sign")
+		}
+		if signingKey, err = scanner.LoadSigningKey(*signKey); err != nil {
+			fatalf("Error loading -sign key: %v", err)
+		}
+	}
+	if *tmpDir != "" {
+		if info, errStat := os.Stat(*tmpDir); errStat != nil || !info.IsDir() {
+			fatalf("Invalid -tmp-dir '%s': not an existing directory", *tmpDir)
+		}
+	}
+	if *noWrite {
+		writers := []struct {
+			name string
+			set  bool
+		}{
+			{"-output", *outputPath != "-" && *outputPath != ""},
+			{"-index", *indexPath != ""},
+			{"-history", *historyPath != ""},
+			{"-update-baseline", *updateBaseline},
+			{"-migrate-baseline", *migrateBaseline},
+		}
+		for _, w := range writers {
+			if w.set {
+				fatalf("%s writes a file and cannot be combined with -no-write", w.name)
+			}
+		}
+		for _, input := range targetInputs {
+			if looksLikeGitHubURL(input) {
+				fatalf("-no-write cannot scan a GitHub URL, which is cloned to disk; scan a checkout instead")
+			}
+		}
+	}
+	if *watch {
+		for _, input := range targetInputs {
+			if looksLikeGitHubURL(input) {
+				fatalf("-watch needs a local directory, not a repository URL")
+			}
+		}
+	}
+	if len(targetInputs) > 1 {
+		perTarget := []struct {
+			name string
+			set  bool
+		}{
+			{"-baseline", *baselinePath != ""},
+			{"-index", *indexPath != ""},
+			{"-watch", *watch},
+		}
+		for _, option := range perTarget {
+			if option.set {
+				fatalf("%s needs a single target", option.name)
+			}
+		}
+	}
+	reporter, err := scanner.NewReporter(outputFormat, reporterOpts)
+	if err != nil {
+		fatalf("%v. Supported formats: %s", err, strings.Join(scanner.ReporterNames(), ", "))
 	}
-	targetInput := flag.Arg(0)
 
 	scanOpts := scanner.ScanOptions{
-		MinLength:           *minLength,
-		VariableKeywords:    splitAndTrim(*varKeywordsStr),
-		ContentKeywords:     splitAndTrim(*contentKeywordsStr),
-		PlaceholderPatterns: splitAndTrim(*placeholderPatternsStr),
-		ScanConfigs:         *scanConfigs,
-		Greedy:              *greedy,
-		UseGitignore:        *useGitignore,
-		Verbose:             *verbose, // Pass verbose to scanner package for its own internal logs
+		MinLength:              *minLength,
+		VariableKeywords:       splitAndTrim(*varKeywordsStr),
+		ContentKeywords:        splitAndTrim(*contentKeywordsStr),
+		PlaceholderPatterns:    splitAndTrim(*placeholderPatternsStr),
+		ScanConfigs:            *scanConfigs,
+		ScanText:               *scanText,
+		ScanMinified:           *scanMinified,
+		Workers:                *workers,
+		ParseTimeout:           *parseTimeout,
+		ConstantsFiles:         *constantsFiles,
+		PromptFilenamePatterns: splitAndTrim(*promptFilenamePatternsStr),
+		IgnoreKeys:             splitAndTrim(*ignoreKeysStr),
+		UseGitignore:           *useGitignore,
+		Verbose:                *verbose, // Pass verbose to scanner package for its own internal logs
+		MultilineOnly:          *multilineOnly,
+		LintOnly:               *lintOnly,
+		QualityLints:           *qualityLints,
+		MinLines:               *minLines,
+		TraceLocation:          *traceHeuristics,
+		IncludeRejected:        *includeRejected,
+		Adaptive:               *adaptive,
+		TempDir:                *tmpDir,
+		PathOverrides:          pathOverrides,
+		Include:                includes,
+		Exclude:                excludes,
+		NoDefaultExcludes:      *noDefaultExcludes,
+		MaxFileSize:            int64(maxFileSize),
+		MaxNestingDepth:        *maxNestingDepth,
+	}
+	applyPreset(flag.CommandLine, preset, &scanOpts)
+	var traced atomic.Bool
+	if *traceHeuristics != "" {
+		scanOpts.Trace = func(step string) {
+			traced.Store(true)
+			fmt.Fprintf(os.Stderr, "trace: %s\n", step)
+		}
+	}
+
+	// The baseline filters findings during the scan, except when it is being recorded again: then
+	// all findings are kept for the new baseline and the old one only decides what is reported. A
+	// migrated baseline absorbs the findings the old one did not have instead of reporting them.
+	var baseline *scanner.Baseline
+	var migrating bool
+	if *baselinePath != "" {
+		if _, errStat := os.Stat(*baselinePath); errStat == nil || !*updateBaseline {
+			if baseline, err = scanner.LoadBaseline(*baselinePath); err != nil {
+				fatalf("Error loading -baseline: %v", err)
+			}
+		}
+		if baseline != nil && baseline.HeuristicsChanged() && !*updateBaseline {
+			migrating = *migrateBaseline
+			warnHeuristicsChanged(baseline, *baselinePath, migrating)
+		}
+		if !*updateBaseline && !migrating {
+			scanOpts.Baseline = baseline
+		}
+		scanOpts.SkipFiles = append(scanOpts.SkipFiles, *baselinePath)
+	}
+	if *outputPath != "-" && *outputPath != "" {
+		scanOpts.SkipFiles = append(scanOpts.SkipFiles, *outputPath)
+	}
+	if configPath != "" {
+		scanOpts.SkipFiles = append(scanOpts.SkipFiles, configPath)
+	}
+	if *onlyFilesFrom != "" {
+		scanOpts.SkipFiles = append(scanOpts.SkipFiles, *onlyFilesFrom)
+	}
+	if listedFiles != nil {
+		scanOpts.OnlyFiles = listedFiles
+		source := *filesFrom
+		if source == "-" {
+			source = "standard input"
+		}
+		VLog.Printf("Scanning %d files listed in %s", len(listedFiles), source)
+	}
+
+	var index *scanner.LiteralIndex
+	if *indexPath != "" {
+		index = scanner.NewLiteralIndex()
+		scanOpts.Index = index
+	}
+	var anonymousStats *scanner.AnonymousStats
+	if *shareStatsURL != "" || *shareStatsPreview {
+		anonymousStats = scanner.NewAnonymousStats(version)
+		scanOpts.AnonymousStats = anonymousStats
+	}
+
+	if *langConfigPath != "" {
+		overrides, errConfig := scanner.LoadLanguageOverrides(*langConfigPath)
+		if errConfig != nil {
+			fatalf("Error loading language config: %v", errConfig)
+		}
+		scanOpts.LanguageOverrides = overrides
+	}
+	if *policyPath != "" {
+		policy, errPolicy := scanner.LoadPolicy(*policyPath)
+		if errPolicy != nil {
+			fatalf("Error loading policy: %v", errPolicy)
+		}
+		scanOpts.Policy = policy
+	}
+	if *tokenizerPath != "" {
+		tokenizer, errTokenizer := scanner.LoadTokenizer(*tokenizerPath)
+		if errTokenizer != nil {
+			fatalf("Error loading tokenizer: %v", errTokenizer)
+		}
+		scanOpts.Tokenizer = tokenizer
 	}
 
-	s, err := scanner.New(scanOpts)
+	var dash *dashboard
+	if *tui {
+		if dash = newDashboard(os.Stderr); dash == nil {
+			log.Println("Warning: -tui requires stderr to be a terminal; continuing without the dashboard.")
+		} else {
+			scanOpts.Progress = dash.handle
+		}
+	}
+
+	// Every target gets its own scanner, so that they can be scanned concurrently.
+	scanners := make([]*scanner.Scanner, len(targetInputs))
+	for i := range scanners {
+		if scanners[i], err = scanner.New(scanOpts); err != nil {
+			fatalf("Error initializing scanner: %v", err) // Fatal, always prints to stderr
+		}
+	}
+	s := scanners[0]
+
+	// The first interrupt stops cloning and scanning, and the findings of the files scanned so far
+	// are reported; a second one, once scanning has stopped, kills the process as usual.
+	ctx, stopInterrupt := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stopInterrupt()
+	targets := make([]resolvedTarget, len(targetInputs))
+	var resolving errgroup.Group
+	for i, input := range targetInputs {
+		resolving.Go(func() (err error) {
+			targets[i], err = resolveTarget(ctx, scanners[i], input, *ref)
+			return err
+		})
+	}
+	err = resolving.Wait()
+	defer removeClones(targets)
 	if err != nil {
-		log.Fatalf("Error initializing scanner: %v", err) // Fatal, always prints to stderr
+		removeClones(targets)
+		if ctx.Err() != nil {
+			log.Printf("Interrupted before scanning.")
+			os.Exit(scanner.ExitInterrupted)
+		}
+		fatalf("Error %v", err)
+	}
+	if len(targets) > 1 {
+		if err := checkDisjointTargets(targets); err != nil {
+			removeClones(targets)
+			fatalf("Error: %v", err)
+		}
+	}
+	if *onlyFilesFrom != "" {
+		for i, target := range targets {
+			scanners[i].Options.OnlyFiles = filesWithFindings(previousFindings, target.ScanTarget)
+			VLog.Printf("Rescanning %d files with findings in %s under %s", len(scanners[i].Options.OnlyFiles), *onlyFilesFrom, target.path)
+		}
 	}
 
+	// Single-target runs show paths relative to the target; multi-target ones under each target.
 	var foundPrompts []scanner.FoundPrompt
-	scanPath := targetInput
-	isTempDir := false
-	originalTargetForDisplay := targetInput
-
-	if looksLikeGitHubURL(targetInput) {
-		VLog.Printf("GitHub URL detected: %s", targetInput)
-		tempDir, errClone := s.CloneRepo(targetInput)
-		if errClone != nil {
-			log.Fatalf("Error cloning repository '%s': %v", targetInput, errClone)
-		}
-		scanPath = tempDir
-		isTempDir = true
-		defer func() {
-			VLog.Printf("Cleaning up temporary directory: %s", tempDir)
-			if err := os.RemoveAll(tempDir); err != nil {
-				// This is a warning, so it might be useful even if not verbose, but let's gate it too.
-				VLog.Printf("Warning: Failed to remove temporary directory %s: %v", tempDir, err)
+	scanPath := targets[0].path
+	isTempDir := targets[0].clone
+	originalTargetForDisplay := targets[0].display
+	commit := targets[0].Commit
+	meta := scanner.ReportMeta{
+		Target:     originalTargetForDisplay,
+		RepoWebURL: targets[0].RepoWebURL,
+		Commit:     commit,
+		Ref:        *ref,
+		StartedAt:  startTime,
+		Project:    strings.TrimSpace(*project),
+		Team:       strings.TrimSpace(*team),
+	}
+	if len(targets) > 1 {
+		originalTargetForDisplay = strings.Join(targetInputs, ", ")
+		commit = ""
+		meta.Target, meta.RepoWebURL, meta.Commit = originalTargetForDisplay, "", ""
+		for _, target := range targets {
+			meta.Targets = append(meta.Targets, target.ScanTarget)
+		}
+	}
+	if len(labels) > 0 {
+		meta.Labels = labels
+	}
+	if *reproducible {
+		hashes := s.Hashes()
+		meta.Reproducible = true
+		meta.Hashes = &hashes
+		meta.StartedAt = time.Time{}
+	}
+	// Show paths relative to the cloned repository or scanned directory; single files keep their path.
+	if info, errStat := os.Stat(scanPath); len(targets) == 1 && (isTempDir || (errStat == nil && info.IsDir())) {
+		meta.Root = scanPath
+	}
+
+	if index != nil {
+		index.Target = originalTargetForDisplay
+		// Cloned repositories are deleted after the scan, so their index has no local root.
+		if !isTempDir {
+			index.Root = scanPath
+			if meta.Root == "" {
+				index.Root = filepath.Dir(scanPath)
 			}
+		}
+	}
+
+	if dash != nil {
+		if len(targets) == 1 {
+			dash.setRoot(scanPath)
+		}
+		dash.start()
+	}
+	counter := scanner.NewSummaryCounter(weights)
+	var keywordStats *scanner.KeywordStats
+	if *keywordReport {
+		keywordStats = s.NewKeywordStats()
+	}
+	recording := *updateBaseline || migrating
+	var allPrompts []scanner.FoundPrompt // Unfiltered findings for -update-baseline
+	var absorbed []scanner.FoundPrompt   // New findings a -migrate-baseline run records without reporting
+	// ndjson output is written as files finish scanning, and findings are only kept for -watch and
+	// -upload. Reproducible output is sorted, so it is written once the scan is complete.
+	streaming := outputFormat == "ndjson" && !*reproducible
+	if streaming {
+		if err := reporter.Start(meta); err != nil {
+			output.Abort()
+			fatalf("Error writing %s output: %v", outputFormat, err)
+		}
+	}
+	// Targets are scanned concurrently; mu serializes their callbacks. Findings that are not
+	// streamed are kept per target, so that the report lists the targets in command-line order.
+	var mu sync.Mutex
+	perTarget := make([][]scanner.FoundPrompt, len(targets))
+	scanErrs := make([]error, len(targets))
+	var scans sync.WaitGroup
+	for i, target := range targets {
+		scans.Add(1)
+		go func() {
+			defer scans.Done()
+			scanErrs[i] = scanners[i].ScanDirectoryFuncContext(ctx, target.path, func(prompts []scanner.FoundPrompt) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if !streaming {
+					perTarget[i] = append(perTarget[i], prompts...)
+					return nil
+				}
+				if recording {
+					allPrompts = append(allPrompts, prompts...)
+					prompts = baseline.NewFindings(scanPath, prompts)
+					if migrating {
+						prompts = absorbFindings(prompts, &absorbed)
+					}
+				}
+				for _, p := range prompts {
+					counter.Add(p)
+					if keywordStats != nil {
+						keywordStats.Add(p)
+					}
+					if err := reporter.Report(p); err != nil {
+						return fmt.Errorf("writing %s output: %w", outputFormat, err)
+					}
+				}
+				if *watch || *uploadURL != "" || *webhookURL != "" || *prNumber > 0 {
+					foundPrompts = append(foundPrompts, prompts...)
+				}
+				return nil
+			})
 		}()
-		VLog.Printf("Repository cloned. Starting scan in %s...", scanPath)
+	}
+	scans.Wait()
+	interrupted := ctx.Err() != nil
+	stopInterrupt()
+	if interrupted {
+		log.Printf("Interrupted: reporting the findings of the files scanned so far.")
+	}
+	if !streaming {
+		for _, prompts := range perTarget {
+			if *reproducible {
+				scanner.SortFindings(prompts)
+			}
+			foundPrompts = append(foundPrompts, prompts...)
+		}
+		if recording {
+			allPrompts = foundPrompts
+			foundPrompts = baseline.NewFindings(scanPath, foundPrompts)
+			if migrating {
+				foundPrompts = absorbFindings(foundPrompts, &absorbed)
+			}
+		}
+	}
+	if dash != nil {
+		dash.finish()
+	}
+	for i, errScan := range scanErrs {
+		if errScan != nil && !(interrupted && errors.Is(errScan, context.Canceled)) {
+			output.Abort()
+			removeClones(targets)
+			fatalf("Error during scan of '%s': %v", targets[i].path, errScan)
+		}
+	}
+	if *traceHeuristics != "" && !traced.Load() {
+		log.Printf("trace: no candidate strings at %s. The file may be ignored or unsupported (see -scan-configs), or the line holds no string literal.", *traceHeuristics)
+	}
+
+	if streaming {
+		err = reporter.Finish()
 	} else {
-		absTarget, errPath := filepath.Abs(targetInput)
-		if errPath != nil {
-			log.Fatalf("Error resolving absolute path for '%s': %v", targetInput, errPath)
-		}
-		scanPath = absTarget
-		originalTargetForDisplay = scanPath // Use absolute path for display if local
-		fileInfo, errStat := os.Stat(scanPath)
-		if errStat != nil {
-			log.Fatalf("Error accessing target path '%s': %v", scanPath, errStat)
-		}
-		if fileInfo.IsDir() {
-			VLog.Printf("Scanning local directory: %s", scanPath)
+		for _, p := range foundPrompts {
+			counter.Add(p)
+			if keywordStats != nil {
+				keywordStats.Add(p)
+			}
+		}
+		err = scanner.ReportAll(reporter, meta, foundPrompts)
+	}
+	if err == nil {
+		err = output.Commit()
+	}
+	if err != nil {
+		output.Abort()
+		fatalf("Error writing %s output: %v", outputFormat, err)
+	}
+	if signingKey != nil {
+		sigPath, errSign := scanner.SignFile(signingKey, *outputPath)
+		if errSign != nil {
+			fatalf("Error signing the report: %v", errSign)
+		}
+		VLog.Printf("Signed the report: %s", sigPath)
+	}
+
+	// A partial scan would drop the findings of unscanned files from the baseline and the index.
+	if recording && interrupted {
+		log.Printf("Not updating baseline %s after an interrupted scan.", *baselinePath)
+	} else if recording {
+		updated := scanner.NewBaseline(scanPath, allPrompts, version)
+		if *reproducible {
+			updated.GeneratedAt = time.Time{}
+		}
+		if err := updated.Write(*baselinePath); err != nil {
+			fatalf("Error writing -baseline: %v", err)
+		}
+		if migrating {
+			reportAbsorbedFindings(scanner.NewBaseline(scanPath, absorbed, version).Findings, *baselinePath)
+		}
+		log.Printf("Updated baseline %s with %d findings.", *baselinePath, len(updated.Findings))
+	} else if baseline != nil {
+		if baseline.Suppressed() > 0 {
+			log.Printf("%d known findings in baseline %s were not reported.", baseline.Suppressed(), *baselinePath)
+		}
+		if *warnUnused > 0 && !interrupted {
+			reportUnusedBaselineEntries(baseline, *baselinePath, *warnUnused, !*noWrite)
+		}
+	}
+	skippedLarge, skippedMinified := 0, 0
+	var timedOut []string
+	for _, s := range scanners {
+		skippedLarge += s.SkippedLargeFiles()
+		skippedMinified += s.SkippedMinifiedFiles()
+		timedOut = append(timedOut, s.TimedOutFiles()...)
+	}
+	if skippedLarge > 0 {
+		log.Printf("Skipped %d files larger than %s; raise -max-file-size to scan them (-verbose lists them).", skippedLarge, &maxFileSize)
+	}
+	if skippedMinified > 0 {
+		log.Printf("Skipped %d minified or bundled JavaScript files; use -scan-minified to scan them (-verbose lists them).", skippedMinified)
+	}
+	if len(timedOut) > 0 {
+		log.Printf("Gave up on %d files that took longer than %s to parse; raise -parse-timeout to scan them:", len(timedOut), *parseTimeout)
+		for _, path := range timedOut {
+			log.Printf("  %s", path)
+		}
+	}
+	if index != nil && !interrupted {
+		if err := index.Save(*indexPath); err != nil {
+			log.Printf("Warning: %v", err)
 		} else {
-			VLog.Printf("Scanning local file: %s", scanPath)
+			VLog.Printf("Indexed %d string literals in %d files to %s", len(index.Literals), len(index.Files), *indexPath)
 		}
 	}
 
-	foundPrompts, err = s.ScanDirectory(scanPath)
+	duration := time.Since(startTime)
+	summary := counter.Summary()
+	if *historyPath != "" && !interrupted {
+		record := scanner.HistoryRecord{
+			Target: originalTargetForDisplay, Commit: commit,
+			Project: meta.Project, Team: meta.Team, Labels: meta.Labels,
+			RecordedAt: startTime.UTC(), Summary: summary,
+		}
+		if err := scanner.AppendHistory(*historyPath, record); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	failPolicy := scanner.FailPolicy{Threshold: *failThreshold}
+	if *failOnFound && failPolicy.Threshold < 0 {
+		failPolicy.Threshold = 0
+	}
+	result := failPolicy.Evaluate(summary)
+	exitCode = result.ExitCode
+	// Final summary always prints to stderr, as it's essential info.
+	outcome := "Scan complete"
+	if interrupted {
+		outcome = "Scan interrupted"
+	}
+	log.Printf("%s. Found %d potential prompts (%d high, %d medium, %d low) in %.2fs from '%s'. Prompt hygiene score: %d/100.",
+		outcome, summary.TotalFindings, summary.BySeverity[scanner.SeverityHigh], summary.BySeverity[scanner.SeverityMedium], summary.BySeverity[scanner.SeverityLow],
+		duration.Seconds(), originalTargetForDisplay, summary.HygieneScore)
+	if keywordStats != nil {
+		printKeywordReport(os.Stderr, keywordStats.Report())
+	}
+	if interrupted {
+		log.Printf("Files not scanned before the interrupt are missing from these results.")
+		exitCode = scanner.ExitInterrupted
+		return
+	}
+	if result.Failed {
+		log.Printf("Failing: %d potential prompts found, more than the allowed %d.", summary.TotalFindings, result.Threshold)
+	}
+	if *uploadURL != "" {
+		uploader := &scanner.Uploader{URL: *uploadURL, APIKey: *apiKey, UserAgent: "prompt-scanner/" + version, SpoolDir: *spoolDir, NoSpool: *noWrite, Logf: log.Printf}
+		envelope := scanner.BuildEnvelope(meta, version, foundPrompts, weights)
+		if err := uploader.Upload(context.Background(), envelope); err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			VLog.Printf("Uploaded the report to %s", *uploadURL)
+		}
+	}
+	if *webhookURL != "" {
+		// A webhook is a notification: a delivery that fails for good is not retried on later runs.
+		webhook := &scanner.Uploader{URL: *webhookURL, Secret: *webhookSecret, UserAgent: "prompt-scanner/" + version, NoSpool: true, Logf: log.Printf}
+		envelope := scanner.BuildEnvelope(meta, version, foundPrompts, weights)
+		if err := webhook.Upload(context.Background(), envelope); err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			VLog.Printf("Delivered the report to %s", *webhookURL)
+		}
+	}
+	if *prNumber > 0 {
+		postPRComment(meta, foundPrompts, reporterOpts.Baseline, weights, *prNumber, *githubRepo, *githubToken)
+	}
+	if anonymousStats != nil {
+		shareStats(anonymousStats.Report(), *shareStatsURL)
+	}
+
+	if *watch {
+		watchDirectory(s, scanPath, meta, foundPrompts, *watchInterval, *notify, outputFormat, reporterOpts)
+	}
+}
+
+// postPRComment renders the pr-comment report of prompts and posts it to pull request number of
+// repo. Failures are logged, like failed uploads, so they do not fail the scan.
+func postPRComment(meta scanner.ReportMeta, prompts []scanner.FoundPrompt, base *scanner.JSONEnvelope, weights scanner.SeverityWeights, number int, repo, token string) {
+	var body strings.Builder
+	reporter, err := scanner.NewReporter("pr-comment", scanner.ReporterOptions{Writer: &body, Baseline: base, Weights: weights})
+	if err == nil {
+		err = scanner.ReportAll(reporter, meta, prompts)
+	}
 	if err != nil {
-		log.Fatalf("Error during scan of '%s': %v", scanPath, err)
+		log.Printf("Warning: rendering the pull request comment: %v", err)
+		return
 	}
+	commenter := &scanner.PRCommenter{Repo: repo, Token: token, APIURL: os.Getenv("GITHUB_API_URL"), UserAgent: "prompt-scanner/" + version}
+	commentURL, err := commenter.Post(context.Background(), number, body.String())
+	if err != nil {
+		log.Printf("Warning: posting the pull request comment: %v", err)
+		return
+	}
+	VLog.Printf("Posted the scan comment to %s", commentURL)
+}
 
-	if *jsonOutput {
-		outputJSON(foundPrompts, scanPath, isTempDir, originalTargetForDisplay)
+// resolvedTarget is a target of the run, ready to be scanned.
+type resolvedTarget struct {
+	scanner.ScanTarget
+	path    string // Local directory or file to scan
+	display string // Target shown in single-target reports: the URL or absolute path
+	clone   bool   // path is a temporary clone, removed after the scan
+}
+
+// resolveTarget clones a GitHub URL at ref until ctx is done, or resolves a local path to an absolute one.
+func resolveTarget(ctx context.Context, s *scanner.Scanner, input, ref string) (resolvedTarget, error) {
+	target := resolvedTarget{ScanTarget: scanner.ScanTarget{Target: input}, display: input}
+	if looksLikeGitHubURL(input) {
+		VLog.Printf("GitHub URL detected: %s", input)
+		tempDir, err := s.CloneRepoAtRefContext(ctx, input, ref)
+		if err != nil {
+			return target, fmt.Errorf("cloning repository '%s': %w", input, err)
+		}
+		target.path, target.Root, target.clone = tempDir, tempDir, true
+		target.RepoWebURL = utils.GitHubWebURL(input)
+		if sha, errHead := s.HeadCommit(tempDir); errHead != nil {
+			VLog.Printf("Warning: Could not resolve cloned commit, permalinks will be omitted: %v", errHead)
+		} else {
+			target.Commit = sha
+		}
+		VLog.Printf("Repository cloned. Starting scan in %s...", tempDir)
+		return target, nil
+	}
+	absTarget, err := filepath.Abs(input)
+	if err != nil {
+		return target, fmt.Errorf("resolving absolute path for '%s': %w", input, err)
+	}
+	target.path, target.Root = absTarget, absTarget
+	target.display = absTarget // Use absolute path for display if local
+	fileInfo, err := os.Stat(absTarget)
+	if err != nil {
+		return target, fmt.Errorf("accessing target path '%s': %w", absTarget, err)
+	}
+	if fileInfo.IsDir() {
+		VLog.Printf("Scanning local directory: %s", absTarget)
 	} else {
-		outputText(foundPrompts, *noFilepath, *noLinenumber, scanPath, isTempDir, originalTargetForDisplay)
+		VLog.Printf("Scanning local file: %s", absTarget)
 	}
+	return target, nil
+}
 
-	duration := time.Since(startTime)
-	// Final summary always prints to stderr, as it's essential info.
-	log.Printf("Scan complete. Found %d potential prompts in %.2fs from '%s'.", len(foundPrompts), duration.Seconds(), originalTargetForDisplay)
+// readFileList reads a newline-separated list of files from path, or from standard input for "-".
+// Blank lines are ignored. The list is never nil, so that an empty one scans no files.
+func readFileList(path string) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file list %s: %w", path, err)
+	}
+	files := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if file := strings.TrimSpace(line); file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// filesWithFindings returns the files of target that have accepted findings in an earlier report,
+// relative to the target's root or absolute, as the report shows them. Findings tagged with a
+// target belong to the target given the same way, under its name; untagged ones to any target.
+func filesWithFindings(findings []scanner.JSONOutput, target scanner.ScanTarget) []string {
+	files := []string{}
+	seen := make(map[string]bool)
+	for _, finding := range findings {
+		if finding.Rejected {
+			continue
+		}
+		path := filepath.FromSlash(finding.Filepath)
+		if finding.Target != "" {
+			if finding.Target != target.Target {
+				continue
+			}
+			rel, err := filepath.Rel(target.Name(), path)
+			if err != nil {
+				continue
+			}
+			path = rel
+		}
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// removeClones deletes the temporary clones of targets. It may be called more than once.
+func removeClones(targets []resolvedTarget) {
+	for i := range targets {
+		if !targets[i].clone {
+			continue
+		}
+		VLog.Printf("Cleaning up temporary directory: %s", targets[i].path)
+		if err := os.RemoveAll(targets[i].path); err != nil {
+			// This is a warning, so it might be useful even if not verbose, but let's gate it too.
+			VLog.Printf("Warning: Failed to remove temporary directory %s: %v", targets[i].path, err)
+		}
+		targets[i].clone = false
+	}
+}
+
+// checkDisjointTargets rejects local targets that are the same or nested, whose files would be
+// scanned and reported twice.
+func checkDisjointTargets(targets []resolvedTarget) error {
+	for i, a := range targets {
+		for _, b := range targets[i+1:] {
+			if a.clone || b.clone {
+				continue
+			}
+			for _, pair := range [][2]resolvedTarget{{a, b}, {b, a}} {
+				rel, err := filepath.Rel(pair[0].path, pair[1].path)
+				if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+					return fmt.Errorf("targets '%s' and '%s' overlap", pair[0].Target, pair[1].Target)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// watchDirectory reports prompts that appear in files saved under scanPath until interrupted.
+func watchDirectory(s *scanner.Scanner, scanPath string, meta scanner.ReportMeta, initial []scanner.FoundPrompt, interval time.Duration, notify bool, outputFormat string, reporterOpts scanner.ReporterOptions) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	log.Printf("Watching '%s' for new prompts (Ctrl+C to stop)...", scanPath)
+	err := s.Watch(ctx, scanPath, initial, interval, func(ev scanner.WatchEvent) {
+		if len(ev.New) == 0 {
+			return
+		}
+		reporter, err := scanner.NewReporter(outputFormat, reporterOpts)
+		if err == nil {
+			err = scanner.ReportAll(reporter, meta, ev.New)
+		}
+		if err != nil {
+			log.Printf("Warning: Error writing %s output: %v", outputFormat, err)
+		}
+		if notify {
+			message := fmt.Sprintf("%d new prompt(s) in %s", len(ev.New), meta.DisplayPath(ev.Filepath))
+			if err := notifyDesktop("prompt-scanner", message); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+	})
+	if err != nil {
+		fatalf("Error watching '%s': %v", scanPath, err)
+	}
 }
 
 func splitAndTrim(s string) []string {
@@ -148,6 +1040,15 @@ func splitAndTrim(s string) []string {
 	return cleanedParts
 }
 
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
 func looksLikeGitHubURL(target string) bool {
 	if strings.HasPrefix(target, "git@github.com:") {
 		return true
@@ -161,90 +1062,126 @@ func looksLikeGitHubURL(target string) bool {
 		(strings.HasSuffix(parsedURL.Path, ".git") || !strings.Contains(parsedURL.Path, ".")) // Broader match for repo URLs
 }
 
-func outputJSON(prompts []scanner.FoundPrompt, scanRoot string, isTempScan bool, originalTarget string) {
-	outputData := make([]scanner.JSONOutput, len(prompts))
-	for i, p := range prompts {
-		displayFilepath := p.Filepath
-		if isTempScan {
-			relPath, err := filepath.Rel(scanRoot, p.Filepath)
-			if err == nil {
-				displayFilepath = relPath // Show path relative to temp cloned dir root
-			}
-		} else {
-			// If original target was a dir, make path relative to it.
-			// If it was a file, displayFilepath will remain absolute (or as is).
-			info, _ := os.Stat(originalTarget)
-			if info != nil && info.IsDir() {
-				relPath, err := filepath.Rel(originalTarget, p.Filepath)
-				if err == nil {
-					displayFilepath = relPath
-				}
-			}
-		}
+// fatalf logs like log.Fatalf but exits with scanner.ExitError, so CI can tell a scan that failed
+// apart from one whose findings exceed -fail-threshold.
+func fatalf(format string, args ...any) {
+	log.Printf(format, args...)
+	os.Exit(scanner.ExitError)
+}
 
-		outputData[i] = scanner.JSONOutput{
-			Filepath: displayFilepath,
-			Line:     p.Line,
-			Content:  p.Content,
-		}
+// warnHeuristicsChanged warns that baseline was recorded with other built-in heuristics than this
+// build's, so findings may appear or disappear in code nobody changed, and lists what changed.
+func warnHeuristicsChanged(baseline *scanner.Baseline, path string, migrating bool) {
+	recorded := fmt.Sprintf("heuristics version %d", baseline.HeuristicsVersion)
+	if baseline.HeuristicsVersion == 0 {
+		recorded = "unversioned heuristics"
 	}
-	jsonData, err := json.MarshalIndent(outputData, "", "  ")
-	if err != nil {
-		log.Fatalf("Error marshalling JSON: %v", err) // Fatal, always prints to stderr
+	action := "Run once with -migrate-baseline to re-record it and review the findings it absorbs"
+	if migrating {
+		action = "Migrating it"
+	}
+	log.Printf("Warning: baseline %s was recorded with %s, this build uses heuristics version %d; findings may appear or disappear without code changes. %s.", path, recorded, scanner.HeuristicsVersion, action)
+	if baseline.HeuristicsVersion > scanner.HeuristicsVersion {
+		log.Printf("  The baseline is newer than this build; upgrade prompt-scanner instead.")
+		return
+	}
+	for _, change := range scanner.HeuristicsChangesSince(baseline.HeuristicsVersion) {
+		log.Printf("  version %d: %s", change.Version, change.Summary)
 	}
-	fmt.Println(string(jsonData)) // JSON output to stdout
 }
 
-func outputText(prompts []scanner.FoundPrompt, noFilepath, noLinenumber bool, scanRoot string, isTempScan bool, originalTarget string) {
+// absorbFindings moves the accepted findings of prompts to absorbed and returns the rest, the
+// rejected candidates.
+func absorbFindings(prompts []scanner.FoundPrompt, absorbed *[]scanner.FoundPrompt) []scanner.FoundPrompt {
+	kept := prompts[:0:0]
 	for _, p := range prompts {
-		displayFilepath := p.Filepath
-		if isTempScan {
-			relPath, err := filepath.Rel(scanRoot, p.Filepath)
-			if err == nil {
-				displayFilepath = relPath
-			}
-		} else {
-			info, _ := os.Stat(originalTarget)
-			if info != nil && info.IsDir() {
-				relPath, err := filepath.Rel(originalTarget, p.Filepath)
-				if err == nil {
-					displayFilepath = relPath
-				}
-			}
+		if p.Rejected {
+			kept = append(kept, p)
+			continue
 		}
+		*absorbed = append(*absorbed, p)
+	}
+	return kept
+}
 
-		var prefixParts []string
-		if !noFilepath {
-			prefixParts = append(prefixParts, displayFilepath)
-		}
-		if !noLinenumber {
-			prefixParts = append(prefixParts, fmt.Sprintf("%d", p.Line))
-		}
+// reportAbsorbedFindings lists on stderr the baseline entries of the findings a -migrate-baseline
+// run recorded instead of reporting them, for review.
+func reportAbsorbedFindings(absorbed []scanner.BaselineEntry, path string) {
+	if len(absorbed) == 0 {
+		return
+	}
+	log.Printf("Migrated baseline %s absorbed %d findings it did not have; review them before committing it:", path, len(absorbed))
+	for _, entry := range absorbed {
+		log.Printf("  %s:%d: %s", entry.Path, entry.Line, entry.Preview)
+	}
+}
 
-		prefix := strings.Join(prefixParts, ":")
-		fullPrefixWithTab := ""
-		if prefix != "" {
-			fullPrefixWithTab = prefix + "\t"
+// reportUnusedBaselineEntries updates the usage counts of the baseline entries after a scan,
+// saving them to path when save is set, and warns about the entries unused for at least runs
+// scans in a row.
+func reportUnusedBaselineEntries(baseline *scanner.Baseline, path string, runs int, save bool) {
+	if baseline.UpdateUsage() && save {
+		if err := baseline.Write(path); err != nil {
+			log.Printf("Warning: %v", err)
 		}
+	}
+	stale := baseline.StaleEntries(runs)
+	if len(stale) == 0 {
+		return
+	}
+	log.Printf("Warning: %d entries in baseline %s matched no finding in the last %d scans; remove them with 'prompt-scanner prune -baseline %s':", len(stale), path, runs, path)
+	for _, entry := range stale {
+		log.Printf("  %s:%d: %s", entry.Path, entry.Line, entry.Preview)
+	}
+}
 
-		normalizedContent := strings.ReplaceAll(p.Content, "\r\n", "\n")
-		lines := strings.Split(strings.TrimRight(normalizedContent, "\n"), "\n")
-
-		if len(lines) > 0 {
-			// Text output (prompts) to stdout
-			fmt.Printf("%s%s%s", fullPrefixWithTab, lines[0], "\n")
-
-			indentation := ""
-			if fullPrefixWithTab != "" {
-				// Ensure indentation matches the visual start of the first line's content
-				indentation = strings.Repeat(" ", len(strings.Split(fullPrefixWithTab, "\t")[0])) + "\t"
+// printKeywordReport writes the -keyword-report table: the findings each keyword and placeholder
+// pattern contributed to, followed by those that never fired.
+func printKeywordReport(w io.Writer, report scanner.KeywordReport) {
+	fmt.Fprintf(w, "Keyword effectiveness (%s mode, %d findings):\n", report.Mode, report.Findings)
+	sections := []struct {
+		title  string
+		usages []scanner.KeywordUsage
+	}{
+		{"Content keywords", report.ContentKeywords},
+		{"Variable keywords", report.VariableKeywords},
+		{"Placeholder patterns", report.PlaceholderPatterns},
+	}
+	for _, section := range sections {
+		fmt.Fprintf(w, "  %s:\n", section.title)
+		for _, usage := range section.usages {
+			if usage.Findings == 0 {
+				continue
 			}
-
-			for i := 1; i < len(lines); i++ {
-				fmt.Printf("%s%s%s", indentation, lines[i], "\n")
+			note := ""
+			if !usage.Configured {
+				note = " (from overrides)"
 			}
-		} else if p.Content == "" && fullPrefixWithTab != "" { // Handle empty content line if prefix exists
-			fmt.Printf("%s%s", fullPrefixWithTab, "\n")
+			fmt.Fprintf(w, "    %5d  %s%s\n", usage.Findings, usage.Keyword, note)
 		}
+		if unused := scanner.UnusedKeywords(section.usages); len(unused) > 0 {
+			fmt.Fprintf(w, "    never fired (%d): %s\n", len(unused), strings.Join(unused, ", "))
+		}
+	}
+	if report.Mode != scanner.ModeGreedy {
+		fmt.Fprintf(w, "  Variable keywords and placeholder patterns only decide findings in greedy mode.\n")
+	}
+}
+
+// shareStats prints the anonymous statistics of the scan on stderr and, unless only a preview was
+// asked for, sends them to url. Failures are warnings: they never fail the scan.
+func shareStats(report scanner.AnonymousStatsReport, url string) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	if url == "" {
+		fmt.Fprintf(os.Stderr, "Statistics -share-stats would send (nothing was sent):\n%s\n", data)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Sending these statistics to %s:\n%s\n", url, data)
+	if err := scanner.PostAnonymousStats(context.Background(), url, "prompt-scanner/"+version, report); err != nil {
+		log.Printf("Warning: %v", err)
 	}
 }
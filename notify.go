@@ -0,0 +1,45 @@
+// notify.go
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// notifyDesktop shows a desktop notification using the platform's built-in tooling:
+// notify-send on Linux and BSDs, osascript on macOS and a PowerShell balloon tip on Windows.
+func notifyDesktop(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.Visible = $true
+$n.ShowBalloonTip(5000, %s, %s, 'Info')
+Start-Sleep -Seconds 5
+$n.Dispose()`, powerShellString(title), powerShellString(message))
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+	default:
+		cmd = exec.Command("notify-send", "--app-name=prompt-scanner", title, message)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("sending desktop notification: %w", err)
+	}
+	// Don't block the watch loop on the notifier; reap it in the background.
+	go cmd.Wait()
+	return nil
+}
+
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
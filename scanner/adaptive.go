@@ -0,0 +1,150 @@
+// scanner/adaptive.go
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// adaptiveSampleFiles caps the files parsed per language by the profiling pre-pass.
+	adaptiveSampleFiles = 200
+	// adaptiveMinLiterals is the number of sampled literals a language needs before its
+	// threshold is adapted; smaller samples say little about the codebase.
+	adaptiveMinLiterals = 50
+	// adaptivePercentile is the share of a language's literals MinLength is raised above.
+	adaptivePercentile = 0.9
+	// adaptiveMaxFactor caps an adapted MinLength at this multiple of the configured one.
+	adaptiveMaxFactor = 3
+)
+
+// LanguageProfile summarizes the string literals of one language in a scanned tree.
+type LanguageProfile struct {
+	Files        int // Files of the language in the tree
+	Literals     int // String literals in the sampled files
+	MedianLength int // Median literal length in characters
+	P90Length    int // 90th percentile literal length in characters
+}
+
+// RepoProfile holds the statistics gathered by ProfileDirectory, keyed by language.
+type RepoProfile struct {
+	TotalFiles int
+	Languages  map[string]*LanguageProfile
+}
+
+// DominantLanguages returns the profiled languages ordered by file count, most files first.
+func (p RepoProfile) DominantLanguages() []string {
+	langs := make([]string, 0, len(p.Languages))
+	for lang := range p.Languages {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if p.Languages[langs[i]].Files != p.Languages[langs[j]].Files {
+			return p.Languages[langs[i]].Files > p.Languages[langs[j]].Files
+		}
+		return langs[i] < langs[j]
+	})
+	return langs
+}
+
+// ProfileDirectory counts the files of each language under rootDir and measures the lengths of
+// the string literals in up to adaptiveSampleFiles files per language. Every string the parsers
+// consider a candidate counts as a literal, whether or not it is reported.
+func (s *Scanner) ProfileDirectory(rootDir string) (RepoProfile, error) {
+	return s.profileDirectory(context.Background(), rootDir)
+}
+
+func (s *Scanner) profileDirectory(ctx context.Context, rootDir string) (RepoProfile, error) {
+	s.rootDir = rootDir
+	profile := RepoProfile{Languages: make(map[string]*LanguageProfile)}
+	lengths := make(map[string][]int)
+	err := s.walkFiles(ctx, rootDir, func(path, lang string) error {
+		profile.TotalFiles++
+		lp := profile.Languages[lang]
+		if lp == nil {
+			lp = &LanguageProfile{}
+			profile.Languages[lang] = lp
+		}
+		lp.Files++
+		if lp.Files > adaptiveSampleFiles || s.isTooLarge(path) {
+			return nil
+		}
+		contentBytes, err := s.readFile(path)
+		if err != nil || len(contentBytes) == 0 || isBinary(contentBytes) || s.skipsMinified(path, lang, contentBytes) {
+			return nil
+		}
+		parser := s.fileParser(path, lang, contentBytes)
+		// The pre-pass runs the parsers without tracing, so traced candidates are only explained once.
+		parser.Options.Trace = nil
+		parser.observe = func(ctx PromptContext, fp FoundPrompt, accepted bool) {
+			lengths[lang] = append(lengths[lang], len(strings.TrimSpace(fp.Content)))
+		}
+		if _, err := parser.parseContent(path, lang, contentBytes); err != nil && s.Options.Verbose {
+			log.Printf("Profiling: error parsing %q: %v", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return profile, fmt.Errorf("error profiling directory %s: %w", rootDir, err)
+	}
+	for lang, ls := range lengths {
+		sort.Ints(ls)
+		lp := profile.Languages[lang]
+		lp.Literals = len(ls)
+		lp.MedianLength = percentile(ls, 0.5)
+		lp.P90Length = percentile(ls, adaptivePercentile)
+	}
+	return profile, nil
+}
+
+// percentile returns the p-th percentile (0-1) of sorted values by the nearest-rank method.
+func percentile(sorted []int, p float64) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// adaptThresholds raises MinLength for every language with at least adaptiveMinLiterals
+// literals to its 90th percentile literal length, capped at adaptiveMaxFactor times the
+// configured MinLength. Codebases full of long literals, such as Go code with long format
+// strings or SQL, then need longer strings before length alone counts towards a prompt.
+// Languages whose MinLength is set in LanguageOverrides are left alone.
+func (s *Scanner) adaptThresholds(profile RepoProfile) error {
+	overrides := make(map[string]LanguageOverride, len(s.Options.LanguageOverrides)+len(profile.Languages))
+	for key, override := range s.Options.LanguageOverrides {
+		overrides[key] = override
+	}
+	var adapted []string
+	for _, lang := range profile.DominantLanguages() {
+		lp := profile.Languages[lang]
+		override := overrides[lang]
+		if lp.Literals < adaptiveMinLiterals || override.MinLength != nil {
+			continue
+		}
+		minLength := min(lp.P90Length, s.Options.MinLength*adaptiveMaxFactor)
+		if minLength <= s.Options.MinLength {
+			continue
+		}
+		override.MinLength = &minLength
+		overrides[lang] = override
+		adapted = append(adapted, fmt.Sprintf("%s=%d", lang, minLength))
+	}
+	if s.Options.Verbose {
+		var dominant []string
+		for _, lang := range profile.DominantLanguages() {
+			lp := profile.Languages[lang]
+			dominant = append(dominant, fmt.Sprintf("%s (%d files, median literal %d chars)", lang, lp.Files, lp.MedianLength))
+		}
+		log.Printf("Profiled %d files: %s", profile.TotalFiles, strings.Join(dominant, ", "))
+		if len(adapted) > 0 {
+			log.Printf("Adapted min-len: %s", strings.Join(adapted, ", "))
+		}
+	}
+	return s.compileLanguageOptions(overrides)
+}
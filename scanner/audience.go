@@ -0,0 +1,96 @@
+// scanner/audience.go
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Audience values: who a string is written for.
+const (
+	AudienceModel = "model" // Instructions or templates sent to an LLM
+	AudienceHuman = "human" // User-facing UI copy: labels, tooltips, marketing text
+)
+
+var (
+	// Role statements and instructions addressed to a model.
+	modelRolePattern = regexp.MustCompile(`(?i)\b(you are|act as|you will be|your (task|job|role|goal) is|as an? (ai|assistant|language model|expert))\b`)
+	// Second-person imperatives that open a sentence or line.
+	modelImperativePattern = regexp.MustCompile(`(?im)(^|[.!?:]\s+)(answer|respond|reply|summari[sz]e|translate|classify|extract|generate|rewrite|return|output|format|explain|analy[sz]e|given|use the|do not|don't|never|always|only|think|consider|list|write)\b`)
+	// References to the conversation setup that UI copy rarely makes.
+	modelReferencePattern = regexp.MustCompile(`(?i)\b(the user|the following|below|step[- ]by[- ]step|json|markdown|the (context|question|input|text|document)s?)\b`)
+
+	// Calls-to-action and first-person-plural phrasing typical of product copy.
+	humanCopyPattern = regexp.MustCompile(`(?i)\b(click|tap|sign (up|in)|log ?in|get started|learn more|try (it )?(for )?free|subscribe|download|welcome|our|we('re| are)?|your account|please wait|loading|successfully)\b`)
+	// Variable, key or call names that hold UI copy.
+	humanNamePattern = regexp.MustCompile(`(?i)(label|title|tooltip|button|btn|placeholder|caption|heading|subtitle|hint|toast|banner|cta|i18n|l10n|locale|translation|copy|text)`)
+	humanCallNames   = map[string]bool{"t": true, "_": true, "gettext": true, "i18n": true, "translate": true, "__": true, "settext": true, "alert": true, "confirm": true}
+	modelNamePattern = regexp.MustCompile(`(?i)(prompt|system|instruction|persona|llm|completion|chat)`)
+)
+
+// classifyAudience decides whether an accepted finding is written for a model or for humans.
+// Model cues are role statements, imperatives, placeholder density and references to the input;
+// human cues are calls-to-action, UI-ish variable names and i18n calls, and short title-like text.
+func (s *Scanner) classifyAudience(ctx PromptContext) string {
+	text := strings.TrimSpace(ctx.Text)
+	modelScore, humanScore := 0, 0
+
+	if modelRolePattern.MatchString(text) {
+		modelScore += 3
+	}
+	modelScore += min(len(modelImperativePattern.FindAllStringIndex(text, -1)), 3)
+	if modelReferencePattern.MatchString(text) {
+		modelScore++
+	}
+	placeholders := 0
+	for _, re := range s.Options.compiledPlaceholders {
+		placeholders += len(re.FindAllStringIndex(text, -1))
+	}
+	if words := len(strings.Fields(text)); placeholders > 0 && words > 0 {
+		modelScore++
+		if placeholders*20 >= words { // At least one placeholder per 20 words
+			modelScore++
+		}
+	}
+	if ctx.LinesInContent > 2 {
+		modelScore++
+	}
+
+	humanScore += min(len(humanCopyPattern.FindAllStringIndex(text, -1)), 3)
+	name := ctx.VariableName
+	if modelNamePattern.MatchString(name) {
+		modelScore += 2
+	} else if humanNamePattern.MatchString(name) {
+		humanScore += 2
+	}
+	if humanCallNames[strings.ToLower(ctx.InvocationFunctionName)] {
+		humanScore += 2
+	}
+	if isTitleLike(text) {
+		humanScore++
+	}
+
+	if humanScore > modelScore {
+		return AudienceHuman
+	}
+	return AudienceModel
+}
+
+// isTitleLike reports whether text is a short phrase in Title Case or ending in an exclamation,
+// as headings and button labels usually are.
+func isTitleLike(text string) bool {
+	words := strings.Fields(text)
+	if len(words) == 0 || len(words) > 10 || strings.Contains(text, "\n") {
+		return false
+	}
+	if strings.HasSuffix(text, "!") {
+		return true
+	}
+	capitalized := 0
+	for _, w := range words {
+		if r := w[0]; r >= 'A' && r <= 'Z' {
+			capitalized++
+		}
+	}
+	return len(words) > 1 && capitalized == len(words)
+}
@@ -0,0 +1,321 @@
+// scanner/baseline.go
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// baselineFormatVersion is bumped whenever the layout of baseline files changes.
+const baselineFormatVersion = 1
+
+// BaselineEntry is one accepted finding recorded in a baseline.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Path        string `json:"path"` // Relative to the scanned root, with forward slashes
+	Line        int    `json:"line"` // Informational; matching ignores lines so that moved code stays baselined
+	Preview     string `json:"preview"`
+	// UnusedRuns counts the consecutive scans, tracked with UpdateUsage, in which the entry held
+	// back no finding.
+	UnusedRuns int `json:"unused_runs,omitempty"`
+}
+
+// Baseline is a set of known findings that are not reported again, so a codebase full of existing
+// prompts can adopt the scanner and only see new ones. Findings are matched by fingerprint: a hash
+// of the file path and the trimmed content. A prompt that is edited or moved to another file is
+// new; one that only moves within its file is not.
+type Baseline struct {
+	Version     int    `json:"version"`
+	ToolVersion string `json:"tool_version,omitempty"`
+	// HeuristicsVersion is the HeuristicsVersion of the scan that recorded the findings; 0 for
+	// baselines recorded before the built-in rules were versioned.
+	HeuristicsVersion int             `json:"heuristics_version,omitempty"`
+	GeneratedAt       time.Time       `json:"generated_at"`
+	Findings          []BaselineEntry `json:"findings"`
+
+	counts     map[string]int // Entries per fingerprint, built on first use
+	countsOnce sync.Once
+	suppressed atomic.Int64
+	usedMu     sync.Mutex
+	used       map[string]int // Findings held back per fingerprint
+}
+
+// baselineFingerprint fingerprints a finding of the file at relPath.
+func baselineFingerprint(relPath, content string) string {
+	sum := sha256.Sum256([]byte(relPath + "\x00" + strings.TrimSpace(content)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// NewBaseline records the accepted findings of a scan of root. Rejected candidates are left out.
+func NewBaseline(root string, prompts []FoundPrompt, toolVersion string) *Baseline {
+	b := &Baseline{Version: baselineFormatVersion, ToolVersion: toolVersion, HeuristicsVersion: HeuristicsVersion, GeneratedAt: time.Now().UTC(), Findings: []BaselineEntry{}}
+	for _, p := range prompts {
+		if p.Rejected {
+			continue
+		}
+		relPath := relativeTo(root, p.Filepath)
+		b.Findings = append(b.Findings, baselineEntry(relPath, p))
+	}
+	b.sortFindings()
+	return b
+}
+
+// baselineEntry records p, found in the file at relPath.
+func baselineEntry(relPath string, p FoundPrompt) BaselineEntry {
+	return BaselineEntry{
+		Fingerprint: baselineFingerprint(relPath, p.Content),
+		Path:        relPath,
+		Line:        p.Line,
+		Preview:     previewLine(p.Content),
+	}
+}
+
+// sortFindings orders the entries by path, then line.
+func (b *Baseline) sortFindings() {
+	sort.SliceStable(b.Findings, func(i, j int) bool {
+		if b.Findings[i].Path != b.Findings[j].Path {
+			return b.Findings[i].Path < b.Findings[j].Path
+		}
+		return b.Findings[i].Line < b.Findings[j].Line
+	})
+}
+
+// BaselineFingerprint returns the fingerprint a baseline records p under, for a scan of root.
+func BaselineFingerprint(root string, p FoundPrompt) string {
+	return baselineFingerprint(relativeTo(root, p.Filepath), p.Content)
+}
+
+// Add records p, a finding of a scan of root, so that it is held back from then on, and returns
+// the new entry. Each entry holds back one finding: adding a prompt twice covers two copies of it
+// in the same file. Add, like Remove, must not be called while NewFindings runs.
+func (b *Baseline) Add(root string, p FoundPrompt) BaselineEntry {
+	entry := baselineEntry(relativeTo(root, p.Filepath), p)
+	b.Findings = append(b.Findings, entry)
+	b.sortFindings()
+	b.resetIndex()
+	return entry
+}
+
+// Remove deletes one entry with the given fingerprint, the last one in the file, so that the
+// finding it held back is reported again. It reports whether there was such an entry.
+func (b *Baseline) Remove(fingerprint string) bool {
+	for i := len(b.Findings) - 1; i >= 0; i-- {
+		if b.Findings[i].Fingerprint == fingerprint {
+			b.Findings = append(b.Findings[:i], b.Findings[i+1:]...)
+			b.resetIndex()
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the first entry that covers p, a finding of a scan of root, and whether there is
+// one. Unlike NewFindings it neither counts the finding as suppressed nor as a use of the entry.
+func (b *Baseline) Match(root string, p FoundPrompt) (BaselineEntry, bool) {
+	fingerprint := BaselineFingerprint(root, p)
+	for _, entry := range b.Findings {
+		if entry.Fingerprint == fingerprint {
+			return entry, true
+		}
+	}
+	return BaselineEntry{}, false
+}
+
+// LoadBaseline reads a baseline file written by Write.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline %s: %w", path, err)
+	}
+	b := &Baseline{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if b.Version != baselineFormatVersion {
+		return nil, fmt.Errorf("baseline %s has format version %d, this build reads version %d; recreate it with -update-baseline", path, b.Version, baselineFormatVersion)
+	}
+	return b, nil
+}
+
+// HeuristicsChanged reports whether the baseline was recorded with other built-in rules than this
+// build's, in which case scans may report or drop findings in code nobody changed.
+func (b *Baseline) HeuristicsChanged() bool {
+	return b.HeuristicsVersion != HeuristicsVersion
+}
+
+// Write saves the baseline to path as indented JSON, ordered by path and line so that updates
+// diff cleanly in version control.
+func (b *Baseline) Write(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline %s: %w", path, err)
+	}
+	return nil
+}
+
+// index counts the entries per fingerprint once the findings are final.
+func (b *Baseline) index() {
+	b.countsOnce.Do(func() {
+		b.counts = make(map[string]int, len(b.Findings))
+		for _, entry := range b.Findings {
+			b.counts[entry.Fingerprint]++
+		}
+	})
+}
+
+// resetIndex makes the next index count the entries again, after Add or Remove.
+func (b *Baseline) resetIndex() {
+	b.countsOnce = sync.Once{}
+	b.counts = nil
+}
+
+// NewFindings returns the prompts of a scan of root that are not in the baseline; rejected
+// candidates are passed through. A fingerprint recorded n times covers n findings, so a copy of a
+// baselined prompt pasted elsewhere in the same file is still new. All findings of a file must be
+// passed in the same call. A nil baseline returns prompts unchanged. NewFindings may be called
+// concurrently.
+func (b *Baseline) NewFindings(root string, prompts []FoundPrompt) []FoundPrompt {
+	if b == nil {
+		return prompts
+	}
+	b.index()
+	used := make(map[string]int)
+	kept := prompts[:0:0]
+	for _, p := range prompts {
+		if !p.Rejected {
+			fingerprint := baselineFingerprint(relativeTo(root, p.Filepath), p.Content)
+			if used[fingerprint] < b.counts[fingerprint] {
+				used[fingerprint]++
+				b.suppressed.Add(1)
+				continue
+			}
+		}
+		kept = append(kept, p)
+	}
+	if len(used) > 0 {
+		b.usedMu.Lock()
+		if b.used == nil {
+			b.used = make(map[string]int)
+		}
+		for fingerprint, n := range used {
+			b.used[fingerprint] += n
+		}
+		b.usedMu.Unlock()
+	}
+	return kept
+}
+
+// Suppressed returns how many findings NewFindings has held back so far.
+func (b *Baseline) Suppressed() int {
+	return int(b.suppressed.Load())
+}
+
+// UpdateUsage records the outcome of the scan that went through NewFindings: entries that held
+// back a finding have UnusedRuns reset, the others have it incremented. Of several entries with
+// the same fingerprint, the first ones in the file count as used. It reports whether any entry
+// changed, that is whether the baseline needs to be written again.
+func (b *Baseline) UpdateUsage() bool {
+	b.usedMu.Lock()
+	defer b.usedMu.Unlock()
+	changed := false
+	seen := make(map[string]int)
+	for i := range b.Findings {
+		entry := &b.Findings[i]
+		seen[entry.Fingerprint]++
+		unused := entry.UnusedRuns + 1
+		if seen[entry.Fingerprint] <= b.used[entry.Fingerprint] {
+			unused = 0
+		}
+		if unused != entry.UnusedRuns {
+			entry.UnusedRuns = unused
+			changed = true
+		}
+	}
+	return changed
+}
+
+// StaleEntries returns the entries that held back no finding for at least runs consecutive scans.
+func (b *Baseline) StaleEntries(runs int) []BaselineEntry {
+	var stale []BaselineEntry
+	for _, entry := range b.Findings {
+		if entry.UnusedRuns >= runs {
+			stale = append(stale, entry)
+		}
+	}
+	return stale
+}
+
+// PruneBaseline removes the entries of b whose prompt no longer exists under root: the file is
+// gone, or none of its string literals, reported or not, has the entry's content any more. Only
+// the files named in the baseline are parsed. Entries of files the scanner cannot parse are kept.
+// It returns the removed entries, and must be called before b is used to filter a scan.
+func (s *Scanner) PruneBaseline(b *Baseline, root string) []BaselineEntry {
+	s.rootDir = root
+	present := make(map[string]map[string]int) // Literals per fingerprint, per file
+	var removed []BaselineEntry
+	kept := b.Findings[:0:0]
+	for _, entry := range b.Findings {
+		literals, ok := present[entry.Path]
+		if !ok {
+			literals = s.literalFingerprints(root, entry.Path)
+			present[entry.Path] = literals
+		}
+		// A nil map means the file could not be checked.
+		if literals != nil {
+			if literals[entry.Fingerprint] == 0 {
+				removed = append(removed, entry)
+				continue
+			}
+			literals[entry.Fingerprint]--
+		}
+		kept = append(kept, entry)
+	}
+	b.Findings = kept
+	return removed
+}
+
+// literalFingerprints returns the baseline fingerprints of every candidate string literal of the
+// file at relPath under root, counted per fingerprint. It returns an empty map when the file is
+// gone and nil when it cannot be parsed.
+func (s *Scanner) literalFingerprints(root, relPath string) map[string]int {
+	filePath := filepath.Join(root, filepath.FromSlash(relPath))
+	contentBytes, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]int{}
+	}
+	if err != nil {
+		return nil
+	}
+	lang := s.contentLanguage(filePath, contentBytes)
+	if lang == "" {
+		return nil
+	}
+	fingerprints := make(map[string]int)
+	parser := s.fileParser(filePath, lang, contentBytes)
+	parser.Options.Trace = nil
+	parser.observe = func(ctx PromptContext, fp FoundPrompt, accepted bool) {
+		fingerprints[baselineFingerprint(relPath, fp.Content)]++
+	}
+	if _, err := parser.parseContent(filePath, lang, contentBytes); err != nil {
+		if s.Options.Verbose {
+			log.Printf("Prune: error parsing %q: %v", filePath, err)
+		}
+		return nil
+	}
+	return fingerprints
+}
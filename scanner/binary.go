@@ -0,0 +1,31 @@
+// scanner/binary.go
+package scanner
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// binarySniffLength is how much of a file isBinary looks at, the same block git checks.
+const binarySniffLength = 8000
+
+// isBinary reports whether content looks like a binary file, such as an image or a compiled
+// artifact that happens to have a source extension: its first block holds a NUL byte or is not
+// valid UTF-8. A character cut off by the end of the block does not count as invalid.
+func isBinary(content []byte) bool {
+	head := content[:min(len(content), binarySniffLength)]
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+	if len(head) < len(content) {
+		for cut := len(head) - 1; cut >= 0 && cut >= len(head)-utf8.UTFMax; cut-- {
+			if utf8.RuneStart(head[cut]) {
+				if !utf8.FullRune(head[cut:]) {
+					head = head[:cut]
+				}
+				break
+			}
+		}
+	}
+	return !utf8.Valid(head)
+}
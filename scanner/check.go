@@ -0,0 +1,93 @@
+// scanner/check.go
+package scanner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkExtensions maps the language names accepted by Check to the extension its snippet is
+// parsed as.
+var checkExtensions = map[string]string{
+	"go": ".go", "python": ".py", "javascript": ".js", "typescript": ".ts", "ruby": ".rb",
+	"java": ".java", "php": ".php", "bash": ".sh", "html": ".html", "json": ".json",
+	"yaml": ".yaml", "toml": ".toml", "xml": ".xml", "hcl": ".hcl", "text": ".txt",
+}
+
+// ScoreSignal is one signal weighed by the greedy score, with the points it contributed.
+type ScoreSignal struct {
+	Label  string `json:"label"`
+	Points int    `json:"points"`
+}
+
+// Verdict is the outcome of the heuristics for one candidate string of a checked snippet.
+type Verdict struct {
+	Line         int           `json:"line"`
+	VariableName string        `json:"variable_name,omitempty"`
+	Content      string        `json:"content"`
+	IsPrompt     bool          `json:"is_prompt"`
+	RejectReason string        `json:"reject_reason,omitempty"` // Reject* code when IsPrompt is false
+	Score        int           `json:"score"`
+	ScoreSignals []ScoreSignal `json:"score_signals,omitempty"`
+	// Matched lists what made an accepted candidate a prompt, as in markdown reports.
+	Matched []string `json:"matched,omitempty"`
+}
+
+// Check runs the heuristics over a snippet of code or text and returns a verdict for every
+// candidate string in it, accepted or not. lang is a language name ("typescript", "yaml", ...)
+// or a file extension ("ts", ".py"); an empty lang treats the whole snippet as one bare string.
+// The score is the one greedy mode uses; in the other modes it is informational, as acceptance
+// then depends on content keywords alone.
+func (s *Scanner) Check(snippet []byte, lang string) ([]Verdict, error) {
+	filePath, lang, err := s.checkLanguage(lang)
+	if err != nil {
+		return nil, err
+	}
+	var verdicts []Verdict
+	parser := s.fileParser(filePath, lang, snippet)
+	parser.observe = func(ctx PromptContext, fp FoundPrompt, accepted bool) {
+		var scored FoundPrompt
+		v := Verdict{
+			Line:         fp.Line,
+			VariableName: ctx.VariableName,
+			Content:      fp.Content,
+			IsPrompt:     accepted,
+			Score:        parser.scoreSignals(ctx, &scored),
+			ScoreSignals: parser.scoreBreakdown(ctx, scored),
+		}
+		if accepted {
+			v.Matched = matchedSignals(fp)
+		} else {
+			v.RejectReason = fp.RejectReason
+		}
+		verdicts = append(verdicts, v)
+	}
+	if _, err := parser.parseContent(filePath, lang, snippet); err != nil {
+		return nil, err
+	}
+	return verdicts, nil
+}
+
+// checkLanguage resolves the lang argument of Check to a parser language and the file name the
+// snippet is parsed as.
+func (s *Scanner) checkLanguage(lang string) (string, string, error) {
+	lang = strings.ToLower(strings.TrimPrefix(lang, "."))
+	switch lang {
+	case "":
+		return "snippet.txt", "text", nil
+	case "env":
+		return ".env", "env", nil
+	}
+	if ext, ok := checkExtensions[lang]; ok {
+		return "snippet" + ext, lang, nil
+	}
+	// Resolve extensions like "ts" or "yml" the way a scan would, config formats included.
+	resolver := &Scanner{Options: s.Options, parsers: s.parsers}
+	resolver.Options.ScanConfigs = true
+	resolver.Options.ScanText = true
+	filePath := "snippet." + lang
+	if resolved := resolver.fileLanguage(filePath); resolved != "" {
+		return filePath, resolved, nil
+	}
+	return "", "", fmt.Errorf("unknown language or extension %q", lang)
+}
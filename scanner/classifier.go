@@ -0,0 +1,38 @@
+// scanner/classifier.go
+package scanner
+
+// PromptClassifier decides whether a candidate string is a prompt, in place of the keyword and
+// scoring heuristics of Scanner.IsPotentialPrompt, e.g. with a machine-learned model. Everything
+// around the decision is kept: the parsers and the walker, pragma comments, MultilineOnly and
+// MinLines, RAG scaffolds and constants files, and the severity and other annotations of accepted
+// candidates. Rejected candidates get the RejectClassifier reason; a classifier may set
+// fp.MatchedContentWord or MatchedVariableName to record what made it accept one. Implementations
+// must be safe for concurrent use, since every scan worker classifies the candidates of its files.
+//
+// A *Scanner is the built-in classifier, so a classifier can fall back on the heuristics of a
+// scanner of its own for the candidates it is unsure about.
+type PromptClassifier interface {
+	IsPotentialPrompt(ctx PromptContext, fp *FoundPrompt) bool
+}
+
+// PromptClassifierFunc adapts a function to PromptClassifier.
+type PromptClassifierFunc func(ctx PromptContext, fp *FoundPrompt) bool
+
+// IsPotentialPrompt calls f(ctx, fp).
+func (f PromptClassifierFunc) IsPotentialPrompt(ctx PromptContext, fp *FoundPrompt) bool {
+	return f(ctx, fp)
+}
+
+// classify runs ScanOptions.Classifier over a candidate, or the built-in heuristics when there is
+// none.
+func (s *Scanner) classify(ctx PromptContext, fp *FoundPrompt) bool {
+	classifier := s.Options.Classifier
+	if classifier == nil {
+		return s.IsPotentialPrompt(ctx, fp)
+	}
+	if classifier.IsPotentialPrompt(ctx, fp) {
+		s.tracef("classifier: accept")
+		return true
+	}
+	return s.reject(fp, RejectClassifier, "classifier")
+}
@@ -2,82 +2,182 @@
 package scanner
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
-	"strconv"
 	"strings"
 
-	"github.com/BurntSushi/toml"
+	"github.com/pelletier/go-toml/v2/unstable"
 	"gopkg.in/yaml.v3"
 
 	"github.com/alexferrari88/prompt-scanner/utils" // Adjust import path
 )
 
-// ParseJSONFile parses JSON files for potential prompts.
-// Note: Line numbers for specific values within JSON are hard to get accurately
-// without a more sophisticated streaming parser or custom unmarshaler.
-// Current implementation defaults to line 1 or the line of the containing object if known.
+// ParseJSONFile parses JSON files for potential prompts, reporting the line of each string value.
+// Comments and trailing commas (JSONC, JSON5, tsconfig-style files) are accepted.
 func (s *Scanner) ParseJSONFile(filePath string, contentBytes []byte) ([]FoundPrompt, error) {
-	var data interface{}
-	// Using json.Decoder to potentially get more info in the future, but line numbers are still tricky.
-	decoder := json.NewDecoder(bytes.NewReader(contentBytes))
-	if err := decoder.Decode(&data); err != nil {
+	prompts, err := s.parseJSONValues(filePath, contentBytes)
+	if err != nil {
+		// Retry without comments and trailing commas before giving up on the file. stripJSONC
+		// keeps offsets intact, so line numbers still match the original file.
+		if retried, retryErr := s.parseJSONValues(filePath, stripJSONC(contentBytes)); retryErr == nil {
+			return retried, nil
+		}
 		return nil, fmt.Errorf("unmarshalling JSON from %s: %w", filePath, err)
 	}
+	return prompts, nil
+}
 
+// parseJSONValues walks the JSON token stream, evaluating every string value with its key path
+// (e.g. "agents[0].system_prompt") as the variable name.
+func (s *Scanner) parseJSONValues(filePath string, contentBytes []byte) ([]FoundPrompt, error) {
 	var prompts []FoundPrompt
 	ext := filepath.Ext(filePath)
+	decoder := json.NewDecoder(bytes.NewReader(contentBytes))
+
+	// lineAt returns the line of the token starting at or after offset. The decoder consumes
+	// separators (':' and ',') as part of the next token, so they are skipped too.
+	lineAt := func(offset int64) int {
+		for offset < int64(len(contentBytes)) && strings.IndexByte(" \t\r\n:,", contentBytes[offset]) >= 0 {
+			offset++
+		}
+		return 1 + bytes.Count(contentBytes[:offset], []byte("\n"))
+	}
 
-	// Recursive helper to find strings
-	var findStrings func(currentJSONPath string, node interface{}, lineHint int)
-	findStrings = func(currentJSONPath string, node interface{}, lineHint int) {
-		switch v := node.(type) {
-		case map[string]interface{}:
-			for key, val := range v {
+	var parseValue func(keyPath string, skip bool) error
+	parseValue = func(keyPath string, skip bool) error {
+		start := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch v := token.(type) {
+		case json.Delim:
+			closing := json.Delim('}')
+			if v == '[' {
+				closing = ']'
+			}
+			for i := 0; decoder.More(); i++ {
+				if v == '[' {
+					if err := parseValue(fmt.Sprintf("%s[%d]", keyPath, i), skip); err != nil {
+						return err
+					}
+					continue
+				}
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := keyToken.(string)
 				newPath := key
-				if currentJSONPath != "" {
-					newPath = currentJSONPath + "." + key
+				if keyPath != "" {
+					newPath = keyPath + "." + key
+				}
+				if err := parseValue(newPath, skip || s.isIgnoredKey(newPath)); err != nil {
+					return err
 				}
-				findStrings(newPath, val, lineHint) // Line hint propagation is approximate
 			}
-		case []interface{}:
-			for i, item := range v {
-				newPath := fmt.Sprintf("%s[%d]", currentJSONPath, i)
-				findStrings(newPath, item, lineHint)
+			if end, err := decoder.Token(); err != nil {
+				return err
+			} else if end != closing {
+				return fmt.Errorf("unexpected %v at offset %d", end, decoder.InputOffset())
 			}
 		case string:
-			if v == "" { // Skip empty strings early
-				return
+			if skip || v == "" { // Skip empty strings early
+				return nil
+			}
+			line := lineAt(start)
+			if s.skipLiteral(v, line, keyPath, false) {
+				return nil
+			}
+			lang := s.valueLanguage(v)
+			if isEmbeddedCode(lang) {
+				prompts = append(prompts, s.parseEmbeddedCode(filePath, lang, keyPath, v, line, true)...)
+				return nil
 			}
 			linesInContent := utils.CountNewlines(v) + 1
 			isMultiLineExplicit := strings.Contains(v, "\n") // Simple check for JSON
 
 			fp := FoundPrompt{
 				Filepath:    filePath,
-				Line:        lineHint, // Approximate line number
+				Line:        line,
+				EndLine:     line, // JSON strings cannot span source lines
 				Content:     v,
 				IsMultiLine: isMultiLineExplicit || linesInContent > 1,
+				Embedded:    templateOrigin(lang, keyPath),
 			}
 			context := PromptContext{
 				Text:                v,
-				VariableName:        currentJSONPath, // Using JSON path as "variable name"
+				VariableName:        keyPath, // Using JSON path as "variable name"
 				IsMultiLineExplicit: isMultiLineExplicit,
 				LinesInContent:      linesInContent,
 				FileExtension:       ext,
 			}
-			if s.IsPotentialPrompt(context, &fp) {
+			if s.evaluateCandidate(context, &fp) {
 				prompts = append(prompts, fp)
 			}
 		}
+		return nil
 	}
 
-	findStrings("", data, 1) // Start with line 1 as a general hint
+	if err := parseValue("", false); err != nil {
+		return nil, err
+	}
 	return prompts, nil
 }
 
+// stripJSONC blanks out // and /* */ comments and trailing commas outside string literals. Every
+// removed byte except newlines becomes a space, so offsets and line numbers are preserved.
+func stripJSONC(content []byte) []byte {
+	out := make([]byte, len(content))
+	copy(out, content)
+	inString := false
+	lastSignificant := -1 // Index of the last non-space byte kept outside comments
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+				lastSignificant = i
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+			continue
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+			continue
+		case c == '}' || c == ']':
+			if lastSignificant >= 0 && out[lastSignificant] == ',' {
+				out[lastSignificant] = ' '
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			continue
+		}
+		lastSignificant = i
+	}
+	return out
+}
+
 // ParseYAMLFile parses YAML files using gopkg.in/yaml.v3, which provides line numbers.
 func (s *Scanner) ParseYAMLFile(filePath string, contentBytes []byte) ([]FoundPrompt, error) {
 	var root yaml.Node
@@ -103,12 +203,26 @@ func (s *Scanner) ParseYAMLFile(filePath string, contentBytes []byte) ([]FoundPr
 			linesInContent := utils.CountNewlines(val) + 1
 			// literal style means multi-line, folded also usually implies it with newlines
 			isMultiLineExplicit := node.Style == yaml.LiteralStyle || node.Style == yaml.FoldedStyle || (node.Style == 0 && strings.Contains(val, "\n"))
+			if s.skipLiteral(val, node.Line, currentKeyName, isMultiLineExplicit) {
+				return
+			}
+			lang := s.valueLanguage(val)
+			if isEmbeddedCode(lang) {
+				firstLine := node.Line
+				if node.Style == yaml.LiteralStyle || node.Style == yaml.FoldedStyle {
+					firstLine++ // Block scalars start on the line after their indicator
+				}
+				prompts = append(prompts, s.parseEmbeddedCode(filePath, lang, currentKeyName, val, firstLine, false)...)
+				return
+			}
 
 			fp := FoundPrompt{
 				Filepath:    filePath,
 				Line:        node.Line, // yaml.v3 provides this
+				EndLine:     node.Line + utils.CountNewlines(val),
 				Content:     val,
 				IsMultiLine: isMultiLineExplicit || linesInContent > 1,
+				Embedded:    templateOrigin(lang, currentKeyName),
 			}
 			context := PromptContext{
 				Text:                val,
@@ -117,7 +231,7 @@ func (s *Scanner) ParseYAMLFile(filePath string, contentBytes []byte) ([]FoundPr
 				LinesInContent:      linesInContent,
 				FileExtension:       ext,
 			}
-			if s.IsPotentialPrompt(context, &fp) {
+			if s.evaluateCandidate(context, &fp) {
 				prompts = append(prompts, fp)
 			}
 		} else if node.Kind == yaml.MappingNode {
@@ -128,6 +242,9 @@ func (s *Scanner) ParseYAMLFile(filePath string, contentBytes []byte) ([]FoundPr
 				if keyPath != "" {
 					fullKeyPath = keyPath + "." + keyNode.Value
 				}
+				if s.isIgnoredKey(fullKeyPath) {
+					continue
+				}
 				findYAMLStrings(valueNode, fullKeyPath)
 			}
 		} else if node.Kind == yaml.SequenceNode {
@@ -146,136 +263,211 @@ func (s *Scanner) ParseYAMLFile(filePath string, contentBytes []byte) ([]FoundPr
 	return prompts, nil
 }
 
-// ParseTOMLFile parses TOML files.
-// Note: Line numbers for specific values are not easily available from BurntSushi/toml's basic Decode.
-// Defaults to line 1.
+// ParseTOMLFile parses TOML files with go-toml's position-aware parser, so findings carry the
+// real line of each string value. Dotted key paths, including [table] and [[array]] headers,
+// are used as the variable name, e.g. "agents[1].system_prompt".
 func (s *Scanner) ParseTOMLFile(filePath string, contentBytes []byte) ([]FoundPrompt, error) {
-	var data map[string]interface{}
-	if _, err := toml.Decode(string(contentBytes), &data); err != nil {
-		return nil, fmt.Errorf("decoding TOML from %s: %w", filePath, err)
-	}
-
 	var prompts []FoundPrompt
 	ext := filepath.Ext(filePath)
 
-	var findTOMLStrings func(currentTOMLPath string, node interface{})
-	findTOMLStrings = func(currentTOMLPath string, node interface{}) {
-		switch v := node.(type) {
-		case map[string]interface{}:
-			for key, val := range v {
-				newPath := key
-				if currentTOMLPath != "" {
-					newPath = currentTOMLPath + "." + key
+	parser := unstable.Parser{}
+	parser.Reset(contentBytes)
+
+	var findTOMLStrings func(currentTOMLPath string, node *unstable.Node)
+	findTOMLStrings = func(currentTOMLPath string, node *unstable.Node) {
+		switch node.Kind {
+		case unstable.InlineTable:
+			children := node.Children()
+			for children.Next() {
+				keyValue := children.Node()
+				newPath := joinKeyPath(currentTOMLPath, tomlKeyPath(keyValue.Key()))
+				if s.isIgnoredKey(newPath) {
+					continue
 				}
-				findTOMLStrings(newPath, val)
+				findTOMLStrings(newPath, keyValue.Value())
 			}
-		case []interface{}:
-			for i, item := range v {
+		case unstable.Array:
+			children := node.Children()
+			for i := 0; children.Next(); i++ {
 				newPath := fmt.Sprintf("%s[%d]", currentTOMLPath, i)
-				findTOMLStrings(newPath, item)
+				findTOMLStrings(newPath, children.Node())
 			}
-		case string:
+		case unstable.String:
+			v := string(node.Data)
 			if v == "" {
 				return
 			}
+			raw := parser.Raw(node.Raw)
+			shape := parser.Shape(node.Raw)
+			// Findings are reported on the line of the opening delimiter, as in the Python and Java
+			// parsers. TOML multi-line strings are `"""..."""` or `'''...'''`; a newline right after
+			// the opening delimiter is not part of the value, so code embedded in it starts below.
+			line := shape.Start.Line
+			contentLine := line
+			isMultiLineExplicit := bytes.HasPrefix(raw, []byte(`"""`)) || bytes.HasPrefix(raw, []byte(`'''`))
+			if isMultiLineExplicit && len(raw) > 3 && (raw[3] == '\n' || raw[3] == '\r') {
+				contentLine++
+			}
+			if s.skipLiteral(v, line, currentTOMLPath, isMultiLineExplicit) {
+				return
+			}
+			lang := s.valueLanguage(v)
+			if isEmbeddedCode(lang) {
+				prompts = append(prompts, s.parseEmbeddedCode(filePath, lang, currentTOMLPath, v, contentLine, false)...)
+				return
+			}
 			linesInContent := utils.CountNewlines(v) + 1
-			// TOML multi-line strings are `"""..."""` or `'''...'''`
-			// A simple check for contained newlines can also indicate multi-line presentation.
-			isMultiLineExplicit := strings.Contains(v, "\n")
 
 			fp := FoundPrompt{
 				Filepath:    filePath,
-				Line:        1, // Approximate line number for TOML values
+				Line:        line,
+				EndLine:     shape.End.Line,
 				Content:     v,
 				IsMultiLine: isMultiLineExplicit || linesInContent > 1,
+				Embedded:    templateOrigin(lang, currentTOMLPath),
 			}
 			context := PromptContext{
 				Text:                v,
 				VariableName:        currentTOMLPath,
-				IsMultiLineExplicit: isMultiLineExplicit,
+				IsMultiLineExplicit: isMultiLineExplicit || strings.Contains(v, "\n"),
 				LinesInContent:      linesInContent,
 				FileExtension:       ext,
 			}
-			if s.IsPotentialPrompt(context, &fp) {
+			if s.evaluateCandidate(context, &fp) {
 				prompts = append(prompts, fp)
 			}
 		}
 	}
-	findTOMLStrings("", data)
+
+	tablePath := ""
+	skipTable := false
+	arrayTableCounts := make(map[string]int) // Entries seen per [[array.table]] name
+	for parser.NextExpression() {
+		expr := parser.Expression()
+		switch expr.Kind {
+		case unstable.Table:
+			tablePath = tomlKeyPath(expr.Key())
+			skipTable = s.isIgnoredKey(tablePath)
+		case unstable.ArrayTable:
+			name := tomlKeyPath(expr.Key())
+			tablePath = fmt.Sprintf("%s[%d]", name, arrayTableCounts[name])
+			arrayTableCounts[name]++
+			skipTable = s.isIgnoredKey(name)
+		case unstable.KeyValue:
+			if skipTable {
+				continue
+			}
+			keyPath := joinKeyPath(tablePath, tomlKeyPath(expr.Key()))
+			if s.isIgnoredKey(keyPath) {
+				continue
+			}
+			findTOMLStrings(keyPath, expr.Value())
+		}
+	}
+	if err := parser.Error(); err != nil {
+		return nil, fmt.Errorf("decoding TOML from %s: %w", filePath, err)
+	}
 	return prompts, nil
 }
 
-// ParseEnvFile parses .env files for potential prompts.
+// tomlKeyPath joins the parts of a dotted TOML key.
+func tomlKeyPath(key unstable.Iterator) string {
+	var parts []string
+	for key.Next() {
+		parts = append(parts, string(key.Node().Data))
+	}
+	return strings.Join(parts, ".")
+}
+
+// ParseEnvFile parses .env files for potential prompts. It follows the common dotenv rules:
+// optional `export` prefixes, quoted values that span several lines, escape sequences in
+// double-quoted values and inline `#` comments after unquoted values.
 func (s *Scanner) ParseEnvFile(filePath string, contentBytes []byte) ([]FoundPrompt, error) {
 	var prompts []FoundPrompt
-	scanner := bufio.NewScanner(bytes.NewReader(contentBytes))
-	lineNumber := 0
 	ext := filepath.Ext(filePath) // Though usually no ext, could be .env.local
+	lines := strings.Split(strings.ReplaceAll(string(contentBytes), "\r\n", "\n"), "\n")
 
-	for scanner.Scan() {
-		lineNumber++
-		line := strings.TrimSpace(scanner.Text())
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSpace(lines[i])
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 
 		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			valueStr := strings.TrimSpace(parts[1])
-			actualValue := valueStr
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(parts[0]), "export "))
+		valueStr := strings.TrimSpace(parts[1])
 
-			// Attempt to unquote .env values if they are quoted
-			if (strings.HasPrefix(valueStr, `"`) && strings.HasSuffix(valueStr, `"`)) ||
-				(strings.HasPrefix(valueStr, `'`) && strings.HasSuffix(valueStr, `'`)) {
-				if len(valueStr) >= 2 {
-					parsedVal, err := strconv.Unquote(valueStr) // Handles basic escapes within quotes
-					if err == nil {
-						actualValue = parsedVal
-					} else {
-						// Fallback to simple trim if Unquote fails (e.g. mismatched quotes)
-						actualValue = valueStr[1 : len(valueStr)-1]
-					}
-				} else {
-					actualValue = "" // Empty if just "" or ''
-				}
-			} else {
-				// If not quoted, treat backslash escapes literally as per some .env parsers,
-				// or unescape common ones if that's the desired behavior.
-				// For now, assume standard .env doesn't do much unescaping outside quotes.
-				// Python-dotenv, for example, does unescape \n, \t etc. if value is quoted.
-				// If we want to replicate that for unquoted values, add it here.
-				// Example: actualValue = strings.ReplaceAll(actualValue, "\\n", "\n")
+		var actualValue string
+		isQuoted := valueStr != "" && strings.ContainsRune(`"'`+"`", rune(valueStr[0]))
+		if isQuoted {
+			// Quoted values run to the matching unescaped quote, possibly on a later line.
+			quote := valueStr[0]
+			raw := valueStr[1:]
+			end := closingQuote(raw, quote)
+			for end < 0 && i+1 < len(lines) {
+				i++
+				raw += "\n" + lines[i]
+				end = closingQuote(raw, quote)
 			}
-
-			if actualValue == "" {
-				continue
+			if end < 0 {
+				return prompts, fmt.Errorf("reading .env file %s: unterminated quoted value for %s on line %d", filePath, key, lineNumber)
 			}
-
-			linesInContent := utils.CountNewlines(actualValue) + 1
-			// .env values are typically single line unless explicitly containing \n (from parsing)
-			isMultiLineExplicit := strings.Contains(actualValue, "\n")
-
-			fp := FoundPrompt{
-				Filepath:    filePath,
-				Line:        lineNumber,
-				Content:     actualValue,
-				IsMultiLine: isMultiLineExplicit || linesInContent > 1,
-			}
-			context := PromptContext{
-				Text:                actualValue,
-				VariableName:        key,
-				IsMultiLineExplicit: isMultiLineExplicit,
-				LinesInContent:      linesInContent,
-				FileExtension:       ext, // Could be empty if filename is just ".env"
+			actualValue = raw[:end]
+			if quote == '"' {
+				actualValue = envEscapes.Replace(actualValue)
 			}
-			if s.IsPotentialPrompt(context, &fp) {
-				prompts = append(prompts, fp)
+		} else {
+			// Unquoted values end at an inline comment.
+			actualValue = valueStr
+			if idx := strings.Index(actualValue, " #"); idx >= 0 {
+				actualValue = strings.TrimSpace(actualValue[:idx])
 			}
 		}
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("reading .env file %s: %w", filePath, err)
+
+		if actualValue == "" || s.isIgnoredKey(key) || s.skipLiteral(actualValue, lineNumber, key, false) {
+			continue
+		}
+
+		linesInContent := utils.CountNewlines(actualValue) + 1
+		isMultiLineExplicit := strings.Contains(actualValue, "\n")
+
+		fp := FoundPrompt{
+			Filepath:    filePath,
+			Line:        lineNumber,
+			EndLine:     i + 1,
+			Content:     actualValue,
+			IsMultiLine: isMultiLineExplicit || linesInContent > 1,
+		}
+		context := PromptContext{
+			Text:                actualValue,
+			VariableName:        key,
+			IsMultiLineExplicit: isMultiLineExplicit,
+			LinesInContent:      linesInContent,
+			FileExtension:       ext, // Could be empty if filename is just ".env"
+		}
+		if s.evaluateCandidate(context, &fp) {
+			prompts = append(prompts, fp)
+		}
 	}
 	return prompts, nil
 }
+
+// envEscapes expands the escape sequences dotenv recognizes in double-quoted values.
+var envEscapes = strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+
+// closingQuote returns the index of the first unescaped quote in s, or -1.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,75 @@
+// scanner/constants.go
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// Constants files are modules that consist mostly of string assignments (constants.py,
+// messages.ts, strings.go). Central constants files are where prompts most often hide, and their
+// prompts rarely carry the usual content keywords, so with ConstantsFiles every long,
+// sentence-like string in them is reported.
+const (
+	constantsMinAssignments = 3   // A constants file has at least this many string assignments...
+	constantsMinRatio       = 0.6 // ...making up at least this share of its code lines.
+	constantsMinWords       = 4   // Strings need this many words to be reported from a constants file.
+)
+
+var (
+	// stringAssignmentRe matches a line assigning a string literal to a name or key, e.g.
+	// `SYSTEM_PROMPT = """`, `export const GREETING = "`, `Name string = "`, `  summary: '`.
+	stringAssignmentRe = regexp.MustCompile(`^\s*(?:export\s+)?(?:(?:public|private|protected|static|final|const|let|var|readonly|define)\s+)*(?:[\w$.<>\[\]]+\s+)?["']?[A-Za-z_$][\w$.]*["']?\s*(?::\s*[\w$.\[\]|]+\s*)?(?:=|:=|=>|:|,)\s*[rRfFbBuU]{0,2}(?:"|'|` + "`" + `|<<)`)
+	// neutralLineRe matches lines that neither help nor hurt: imports, package clauses, brackets
+	// and openers such as `MESSAGES = {` or `const (`.
+	neutralLineRe = regexp.MustCompile(`^(?:import\b|from\b|package\b|require\b|use\b|module\b|end\b|<\?php|\?>|["']use strict["'])|^[\s{}()\[\];,]*$|^[^"'` + "`" + `]*[{(\[]\s*$`)
+	// multiLineQuoteRe matches the delimiters of strings that span lines.
+	multiLineQuoteRe = regexp.MustCompile(`"""|'''|` + "`")
+)
+
+// isConstantsFile reports whether source consists mostly of string assignments. Lines inside
+// multi-line strings, comments and neutral lines are not counted.
+func isConstantsFile(source []byte) bool {
+	assignments, codeLines := 0, 0
+	inMultiLineString := false
+	lines := bufio.NewScanner(bytes.NewReader(source))
+	lines.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lines.Scan() {
+		line := lines.Text()
+		trimmed := strings.TrimSpace(line)
+		startsInString := inMultiLineString
+		if len(multiLineQuoteRe.FindAllString(line, -1))%2 == 1 {
+			inMultiLineString = !inMultiLineString
+		}
+		if startsInString || trimmed == "" || isCommentLine(trimmed) || neutralLineRe.MatchString(trimmed) {
+			continue
+		}
+		codeLines++
+		if stringAssignmentRe.MatchString(line) {
+			assignments++
+		}
+	}
+	return assignments >= constantsMinAssignments && float64(assignments) >= constantsMinRatio*float64(codeLines)
+}
+
+func isCommentLine(trimmed string) bool {
+	for _, prefix := range []string{"#", "//", "/*", "*", "--"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isConstantsCandidate reports whether text qualifies as a finding in a constants file: at least
+// MinLength long and sentence-like.
+func (s *Scanner) isConstantsCandidate(ctx PromptContext, fp *FoundPrompt) bool {
+	text := strings.TrimSpace(ctx.Text)
+	if len(text) < s.Options.MinLength || len(strings.Fields(text)) < constantsMinWords {
+		return false
+	}
+	fp.MatchedContentWord = "constants_file"
+	return true
+}
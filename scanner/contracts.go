@@ -0,0 +1,93 @@
+// scanner/contracts.go
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// OutputContract is an output-format instruction embedded in a prompt, such as
+// "Respond only with valid JSON" together with any schema or example block that follows it.
+type OutputContract struct {
+	Format string `json:"format"` // json_schema, json, xml, yaml, csv, markdown or list
+	Line   int    `json:"line"`   // 1-based line within the prompt content where the instruction starts
+	Text   string `json:"text"`   // The instruction, plus the schema/example block that follows it
+}
+
+var (
+	// contractInstructionPattern finds sentences that prescribe the response format.
+	contractInstructionPattern = regexp.MustCompile(`(?i)\b(respond|reply|answer|return|output|format|provide|give|produce|write|generate)\b[^.\n]{0,60}\b(json|xml|yaml|csv|markdown|table|schema|bullet|numbered list|format|following structure)\b|` +
+		`\b(valid|only|strict|raw) (json|xml|yaml)\b|\bjson (object|array|schema)\b|\bin the following (format|structure|schema)\b|\bmust (match|conform to|follow) (the|this) (schema|format)\b`)
+
+	contractFormats = []struct {
+		format  string
+		pattern *regexp.Regexp
+	}{
+		{"json_schema", regexp.MustCompile(`(?i)json[ -]?schema|"\$schema"|"properties"\s*:|"type"\s*:\s*"object"`)},
+		{"json", regexp.MustCompile(`(?i)\bjson\b|^\s*[{\[]`)},
+		{"xml", regexp.MustCompile(`(?i)\bxml\b|<[a-z_]+>`)},
+		{"yaml", regexp.MustCompile(`(?i)\bya?ml\b`)},
+		{"csv", regexp.MustCompile(`(?i)\bcsv\b|comma[- ]separated`)},
+		{"markdown", regexp.MustCompile(`(?i)\bmarkdown\b|\btable\b`)},
+		{"list", regexp.MustCompile(`(?i)\bbullet|numbered list|\blist\b`)},
+	}
+)
+
+// extractOutputContracts finds the output-format instructions in a prompt's content.
+func extractOutputContracts(content string) []OutputContract {
+	lines := strings.Split(content, "\n")
+	var contracts []OutputContract
+	for i := 0; i < len(lines); i++ {
+		if !contractInstructionPattern.MatchString(lines[i]) {
+			continue
+		}
+		end := contractBlockEnd(lines, i)
+		text := strings.TrimSpace(strings.Join(lines[i:end+1], "\n"))
+		contracts = append(contracts, OutputContract{Format: contractFormat(text), Line: i + 1, Text: text})
+		i = end
+	}
+	return contracts
+}
+
+// contractBlockEnd extends an instruction at line start over a schema or example block that
+// follows it: a fenced code block or a balanced {...} / [...] structure. It returns the last line
+// of the contract.
+func contractBlockEnd(lines []string, start int) int {
+	next := start + 1
+	for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+		next++
+	}
+	if next >= len(lines) {
+		return start
+	}
+	first := strings.TrimSpace(lines[next])
+	switch {
+	case strings.HasPrefix(first, "```"):
+		for j := next + 1; j < len(lines); j++ {
+			if strings.HasPrefix(strings.TrimSpace(lines[j]), "```") {
+				return j
+			}
+		}
+		return len(lines) - 1
+	case strings.HasPrefix(first, "{") || strings.HasPrefix(first, "["):
+		depth := 0
+		for j := next; j < len(lines); j++ {
+			depth += strings.Count(lines[j], "{") + strings.Count(lines[j], "[")
+			depth -= strings.Count(lines[j], "}") + strings.Count(lines[j], "]")
+			if depth <= 0 {
+				return j
+			}
+		}
+		return len(lines) - 1
+	}
+	return start
+}
+
+func contractFormat(text string) string {
+	for _, f := range contractFormats {
+		if f.pattern.MatchString(text) {
+			return f.format
+		}
+	}
+	return "text"
+}
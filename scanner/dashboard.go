@@ -0,0 +1,308 @@
+// scanner/dashboard.go
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// dashboardTopPrompts is the number of prompts previewed on each repository card.
+const dashboardTopPrompts = 3
+
+// ReadEnvelope loads a report written with -format envelope.
+func ReadEnvelope(path string) (JSONEnvelope, error) {
+	var envelope JSONEnvelope
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return envelope, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return envelope, fmt.Errorf("failed to parse %s as an envelope report: %w", path, err)
+	}
+	if envelope.SchemaVersion == "" {
+		return envelope, fmt.Errorf("%s is not an envelope report (missing schema_version)", path)
+	}
+	return envelope, nil
+}
+
+// dashboardRepo is the view model of one scanned repository.
+type dashboardRepo struct {
+	Name     string
+	Page     string // Drill-down page, relative to the dashboard directory
+	Envelope JSONEnvelope
+	Top      []JSONOutput
+	Findings []dashboardFinding
+	Shared   int // Number of findings also present in other repositories
+}
+
+// dashboardFinding is a finding on a drill-down page, with links to copies in other repositories.
+type dashboardFinding struct {
+	JSONOutput
+	AlsoIn   []*dashboardRepo
+	Diverged bool
+}
+
+// dashboardShared is the view model of a prompt shared across repositories.
+type dashboardShared struct {
+	SharedPrompt
+	Similarity int // MinSimilarity as a percentage
+	Copies     []dashboardSharedCopy
+}
+
+type dashboardSharedCopy struct {
+	Repo    *dashboardRepo
+	Finding JSONOutput
+}
+
+type dashboardIndex struct {
+	Repos  []*dashboardRepo
+	Shared []dashboardShared
+}
+
+// ScannedRef returns the ref (or short commit) the repository was scanned at.
+func (r *dashboardRepo) ScannedRef() string {
+	ref := r.Envelope.Ref
+	if commit := shortCommit(r.Envelope.Commit); commit != "" {
+		if ref == "" {
+			return commit
+		}
+		return ref + " @ " + commit
+	}
+	return ref
+}
+
+// WriteDashboard writes a static site to dir: index.html with one card per envelope report and
+// a drill-down page per repository under repos/. Prompts copied between repositories (see
+// FindSharedPrompts) are listed on the index and flagged on the drill-down pages.
+func WriteDashboard(dir string, envelopes []JSONEnvelope, similarityThreshold float64) error {
+	if err := os.MkdirAll(filepath.Join(dir, "repos"), 0o755); err != nil {
+		return fmt.Errorf("failed to create dashboard directory: %w", err)
+	}
+
+	repos := make([]*dashboardRepo, 0, len(envelopes))
+	usedPages := make(map[string]int)
+	for _, envelope := range envelopes {
+		name := repoDisplayName(envelope.Target)
+		slug := dashboardSlug(name)
+		usedPages[slug]++
+		if n := usedPages[slug]; n > 1 {
+			slug = fmt.Sprintf("%s-%d", slug, n)
+		}
+		repo := &dashboardRepo{
+			Name:     name,
+			Page:     "repos/" + slug + ".html",
+			Envelope: envelope,
+			Top:      topPrompts(envelope.Findings, dashboardTopPrompts),
+		}
+		for _, finding := range envelope.Findings {
+			repo.Findings = append(repo.Findings, dashboardFinding{JSONOutput: finding})
+		}
+		repos = append(repos, repo)
+	}
+
+	index := dashboardIndex{Repos: append([]*dashboardRepo(nil), repos...)}
+	for _, shared := range FindSharedPrompts(envelopes, similarityThreshold) {
+		view := dashboardShared{SharedPrompt: shared, Similarity: int(shared.MinSimilarity * 100)}
+		for _, c := range shared.Copies {
+			view.Copies = append(view.Copies, dashboardSharedCopy{Repo: repos[c.Repo], Finding: c.Finding})
+		}
+		for _, c := range shared.Copies {
+			finding := findDashboardFinding(repos[c.Repo], c.Finding)
+			if finding == nil {
+				continue
+			}
+			finding.Diverged = shared.Diverged
+			for _, other := range shared.Repos() {
+				if other != c.Repo && !containsRepo(finding.AlsoIn, repos[other]) {
+					finding.AlsoIn = append(finding.AlsoIn, repos[other])
+				}
+			}
+		}
+		index.Shared = append(index.Shared, view)
+	}
+	for _, repo := range repos {
+		for _, finding := range repo.Findings {
+			if len(finding.AlsoIn) > 0 {
+				repo.Shared++
+			}
+		}
+	}
+
+	// Worst hygiene first, so the repositories needing attention lead the page.
+	sort.SliceStable(index.Repos, func(i, j int) bool {
+		return index.Repos[i].Envelope.Summary.HygieneScore < index.Repos[j].Envelope.Summary.HygieneScore
+	})
+
+	if err := writeTemplateFile(filepath.Join(dir, "index.html"), dashboardIndexTemplate, index); err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if err := writeTemplateFile(filepath.Join(dir, filepath.FromSlash(repo.Page)), dashboardRepoTemplate, repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func findDashboardFinding(repo *dashboardRepo, finding JSONOutput) *dashboardFinding {
+	for i := range repo.Findings {
+		if f := repo.Findings[i]; f.Filepath == finding.Filepath && f.Line == finding.Line && f.Content == finding.Content {
+			return &repo.Findings[i]
+		}
+	}
+	return nil
+}
+
+func containsRepo(repos []*dashboardRepo, repo *dashboardRepo) bool {
+	for _, r := range repos {
+		if r == repo {
+			return true
+		}
+	}
+	return false
+}
+
+func writeTemplateFile(path string, tmpl *template.Template, data any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := tmpl.Execute(f, data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to render %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// topPrompts picks the n most severe findings, preferring longer prompts within a severity.
+func topPrompts(findings []JSONOutput, n int) []JSONOutput {
+	rank := map[string]int{SeverityHigh: 0, SeverityMedium: 1, SeverityLow: 2, "": 3}
+	sorted := append([]JSONOutput(nil), findings...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if rank[sorted[i].Severity] != rank[sorted[j].Severity] {
+			return rank[sorted[i].Severity] < rank[sorted[j].Severity]
+		}
+		return len(sorted[i].Content) > len(sorted[j].Content)
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// repoDisplayName shortens a scan target to "owner/repo" for URLs or the base name for paths.
+func repoDisplayName(target string) string {
+	if strings.HasPrefix(target, "git@") {
+		if _, path, ok := strings.Cut(target, ":"); ok {
+			return strings.TrimSuffix(path, ".git")
+		}
+	}
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		return strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	}
+	return filepath.Base(target)
+}
+
+var slugUnsafe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func dashboardSlug(name string) string {
+	slug := strings.Trim(slugUnsafe.ReplaceAllString(name, "-"), "-.")
+	if slug == "" {
+		return "repo"
+	}
+	return slug
+}
+
+const dashboardCSS = `body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem; color: #1f2328; }
+.cards { display: grid; grid-template-columns: repeat(auto-fill, minmax(320px, 1fr)); gap: 1rem; }
+.card, .finding { border: 1px solid #d0d7de; border-radius: 6px; padding: 0.75rem; }
+.card h2 { font-size: 1rem; margin: 0 0 0.5rem; }
+.score { float: right; font-size: 1.4rem; font-weight: bold; }
+.meta { color: #57606a; font-size: 0.85rem; }
+.sev { display: inline-block; border-radius: 4px; padding: 0 0.3rem; font-size: 0.75rem; color: #fff; background: #8c959f; }
+.sev.high { background: #cf222e; } .sev.medium { background: #d4a72c; }
+.card ul { padding-left: 1rem; font-size: 0.85rem; }
+.finding { margin: 1rem 0; }
+.finding pre { white-space: pre-wrap; background: #f6f8fa; padding: 0.5rem; margin: 0.5rem 0 0; }
+.shared { color: #8250df; font-size: 0.85rem; }
+table { border-collapse: collapse; }
+th, td { border-bottom: 1px solid #d0d7de; padding: 0.3rem 0.6rem; text-align: left; vertical-align: top; font-size: 0.85rem; }
+`
+
+var dashboardFuncs = template.FuncMap{
+	"css": func() template.CSS { return template.CSS(dashboardCSS) },
+	"preview": func(content string) string {
+		line, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+		if runes := []rune(line); len(runes) > 100 {
+			return string(runes[:100]) + "…"
+		}
+		return line
+	},
+}
+
+var dashboardIndexTemplate = template.Must(template.New("index").Funcs(dashboardFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Prompt scan dashboard</title>
+<style>{{css}}</style>
+</head>
+<body>
+<h1>Prompt scan dashboard</h1>
+<p class="meta">{{len .Repos}} repositories{{if .Shared}}, {{len .Shared}} prompts shared between them{{end}}.</p>
+<div class="cards">
+{{range .Repos}}<div class="card">
+<span class="score" title="Prompt hygiene score">{{.Envelope.Summary.HygieneScore}}</span>
+<h2><a href="{{.Page}}">{{.Name}}</a></h2>
+<p class="meta">{{.Envelope.Summary.TotalFindings}} findings:
+<span class="sev high">{{index .Envelope.Summary.BySeverity "high"}} high</span>
+<span class="sev medium">{{index .Envelope.Summary.BySeverity "medium"}} medium</span>
+<span class="sev low">{{index .Envelope.Summary.BySeverity "low"}} low</span></p>
+<p class="meta">Scanned {{with .ScannedRef}}<code>{{.}}</code> {{end}}on {{.Envelope.GeneratedAt.Format "2006-01-02"}}</p>
+{{if .Shared}}<p class="shared">{{.Shared}} prompts also found in other repositories</p>
+{{end}}
+{{if .Top}}<ul>
+{{range .Top}}<li><span class="sev {{.Severity}}">{{.Severity}}</span> <code>{{.Filepath}}:{{.Line}}</code> {{preview .Content}}</li>
+{{end}}</ul>
+{{end}}</div>
+{{end}}</div>
+{{if .Shared}}<h2>Prompts shared across repositories</h2>
+<p class="meta">Copied prompts tend to drift apart; diverged copies are similar but no longer identical.</p>
+<table>
+<tr><th>Prompt</th><th>Status</th><th>Copies</th></tr>
+{{range .Shared}}<tr><td>{{preview (index .Copies 0).Finding.Content}}</td>
+<td>{{if .Diverged}}diverged ({{.Similarity}}% similar){{else}}identical{{end}}</td>
+<td>{{range .Copies}}<a href="{{.Repo.Page}}">{{.Repo.Name}}</a> <code>{{.Finding.Filepath}}:{{.Finding.Line}}</code><br>{{end}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+var dashboardRepoTemplate = template.Must(template.New("repo").Funcs(dashboardFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} – prompt scan</title>
+<style>{{css}}</style>
+</head>
+<body>
+<p><a href="../index.html">← All repositories</a></p>
+<h1>{{.Name}}</h1>
+<p class="meta">Target <code>{{.Envelope.Target}}</code>{{with .ScannedRef}} at <code>{{.}}</code>{{end}}, scanned {{.Envelope.GeneratedAt.Format "2006-01-02 15:04"}}.
+Hygiene score {{.Envelope.Summary.HygieneScore}}/100, {{.Envelope.Summary.TotalFindings}} findings.</p>
+{{range .Findings}}<div class="finding">
+<span class="sev {{.Severity}}">{{.Severity}}</span>
+{{if .Permalink}}<a href="{{.Permalink}}"><code>{{.Filepath}}:{{.Line}}</code></a>{{else}}<code>{{.Filepath}}:{{.Line}}</code>{{end}}{{with .Symbol}} <span class="meta">{{.}}</span>{{end}}
+{{if .AlsoIn}}<p class="shared">{{if .Diverged}}Diverged copy{{else}}Also{{end}} in {{range $i, $r := .AlsoIn}}{{if $i}}, {{end}}<a href="../{{$r.Page}}">{{$r.Name}}</a>{{end}}</p>
+{{end}}<pre>{{.Content}}</pre>
+</div>
+{{end}}</body>
+</html>
+`))
@@ -1,11 +1,67 @@
 // scanner/defaults.go
 package scanner
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 // DefaultMinLength is the default minimum character length for a string to be considered a potential prompt.
 const DefaultMinLength = 30
 
+// DefaultMaxFileSize is the default size in bytes above which the command-line scan skips files,
+// such as bundles and data dumps, instead of reading them into memory.
+const DefaultMaxFileSize = 5 << 20
+
+// DefaultParseTimeout is the default time after which the command-line scan gives up parsing a
+// file with tree-sitter.
+const DefaultParseTimeout = 30 * time.Second
+
+// DefaultMaxNestingDepth is how many levels of code held in other code or config values, such as a
+// script in a YAML value, are parsed when ScanOptions.MaxNestingDepth is 0.
+const DefaultMaxNestingDepth = 4
+
+// DefaultExcludeList holds doublestar globs of files that are never scanned unless
+// ScanOptions.NoDefaultExcludes is set: dependency lockfiles, whose package descriptions and
+// resolved metadata read like prose, minified stylesheets, source maps and datasets.
+var DefaultExcludeList = []string{
+	// Lockfiles
+	"**/package-lock.json",
+	"**/npm-shrinkwrap.json",
+	"**/yarn.lock",
+	"**/pnpm-lock.yaml",
+	"**/bun.lock",
+	"**/deno.lock",
+	"**/poetry.lock",
+	"**/Pipfile.lock",
+	"**/uv.lock",
+	"**/Cargo.lock",
+	"**/Gemfile.lock",
+	"**/composer.lock",
+	"**/packages.lock.json",
+	"**/flake.lock",
+	"**/go.sum",
+	// Build artifacts
+	"**/*.min.css",
+	"**/*.map",
+	// Datasets
+	"**/*.csv",
+	"**/*.tsv",
+	"**/*.jsonl",
+	"**/*.ndjson",
+	"**/*.parquet",
+	"**/*.arrow",
+	"**/*.feather",
+	"**/*.avro",
+	"**/*.h5",
+	"**/*.hdf5",
+	"**/*.npy",
+	"**/*.npz",
+	"**/*.pkl",
+	"**/*.sqlite",
+	"**/*.db",
+}
+
 // --- Variable Keywords ---
 
 // DefaultVarKeywordsList provides the default keywords for variable names as a slice for readability and easy management.
@@ -77,3 +133,17 @@ var DefaultPlaceholderPatternsList = []string{
 // DefaultPlaceholderPatterns is the comma-separated string version of DefaultPlaceholderPatternsList, used for flag defaults.
 // This allows users to provide comma-separated regex patterns via the command line.
 var DefaultPlaceholderPatterns = strings.Join(DefaultPlaceholderPatternsList, ",")
+
+// --- Prompt Filename Patterns ---
+
+// DefaultPromptFilenamePatternsList provides file name globs of config files whose names strongly suggest they hold prompts.
+// Matching files are scanned even when config scanning is off.
+var DefaultPromptFilenamePatternsList = []string{
+	"*prompt*.json",
+	"*prompt*.yaml",
+	"*prompt*.yml",
+	"*prompt*.toml",
+}
+
+// DefaultPromptFilenamePatterns is the comma-separated string version of DefaultPromptFilenamePatternsList, used for flag defaults.
+var DefaultPromptFilenamePatterns = strings.Join(DefaultPromptFilenamePatternsList, ",")
@@ -0,0 +1,163 @@
+// scanner/duplicates.go
+package scanner
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultSimilarityThreshold is the word-shingle Jaccard similarity at which two prompts are
+// treated as copies of each other.
+const DefaultSimilarityThreshold = 0.7
+
+// SharedPromptCopy is one occurrence of a prompt that appears in several repositories.
+type SharedPromptCopy struct {
+	Repo    int // Index of the envelope the copy came from
+	Finding JSONOutput
+}
+
+// SharedPrompt groups copies of the same prompt found in at least two repositories.
+type SharedPrompt struct {
+	Copies []SharedPromptCopy
+	// Diverged reports whether the copies have drifted apart (similar but not identical text).
+	Diverged bool
+	// MinSimilarity is the lowest similarity between linked copies, 1 for exact copies.
+	MinSimilarity float64
+}
+
+// Repos returns the distinct envelope indexes the prompt was found in.
+func (sp SharedPrompt) Repos() []int {
+	seen := make(map[int]bool)
+	var repos []int
+	for _, c := range sp.Copies {
+		if !seen[c.Repo] {
+			seen[c.Repo] = true
+			repos = append(repos, c.Repo)
+		}
+	}
+	return repos
+}
+
+type promptItem struct {
+	repo       int
+	finding    JSONOutput
+	normalized string
+	shingles   map[string]struct{}
+}
+
+// FindSharedPrompts clusters findings across envelopes and returns the clusters that span more
+// than one repository, most widespread first. Findings are linked when their normalized text is
+// identical or their word-shingle similarity reaches threshold.
+func FindSharedPrompts(envelopes []JSONEnvelope, threshold float64) []SharedPrompt {
+	var items []promptItem
+	for repo, envelope := range envelopes {
+		for _, finding := range envelope.Findings {
+			normalized := normalizePromptText(finding.Content)
+			items = append(items, promptItem{
+				repo:       repo,
+				finding:    finding,
+				normalized: normalized,
+				shingles:   wordShingles(normalized, 3),
+			})
+		}
+	}
+
+	parent := make([]int, len(items))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	minSimilarity := make(map[[2]int]float64)
+
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if items[i].repo == items[j].repo {
+				continue
+			}
+			similarity := 1.0
+			if items[i].normalized != items[j].normalized {
+				similarity = jaccard(items[i].shingles, items[j].shingles, threshold)
+				if similarity < threshold {
+					continue
+				}
+			}
+			ri, rj := find(i), find(j)
+			minSimilarity[[2]int{i, j}] = similarity
+			parent[ri] = rj
+		}
+	}
+
+	clusters := make(map[int]*SharedPrompt)
+	var order []int
+	for i, item := range items {
+		root := find(i)
+		cluster, ok := clusters[root]
+		if !ok {
+			cluster = &SharedPrompt{MinSimilarity: 1}
+			clusters[root] = cluster
+			order = append(order, root)
+		}
+		cluster.Copies = append(cluster.Copies, SharedPromptCopy{Repo: item.repo, Finding: item.finding})
+	}
+	for pair, similarity := range minSimilarity {
+		cluster := clusters[find(pair[0])]
+		if similarity < cluster.MinSimilarity {
+			cluster.MinSimilarity = similarity
+		}
+		if items[pair[0]].normalized != items[pair[1]].normalized {
+			cluster.Diverged = true
+		}
+	}
+
+	var shared []SharedPrompt
+	for _, root := range order {
+		if cluster := clusters[root]; len(cluster.Repos()) > 1 {
+			shared = append(shared, *cluster)
+		}
+	}
+	sort.SliceStable(shared, func(i, j int) bool { return len(shared[i].Repos()) > len(shared[j].Repos()) })
+	return shared
+}
+
+// normalizePromptText lowercases text and collapses whitespace so formatting changes do not hide copies.
+func normalizePromptText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// wordShingles returns the set of n-word sequences in text; short texts form a single shingle.
+func wordShingles(text string, n int) map[string]struct{} {
+	words := strings.Fields(text)
+	shingles := make(map[string]struct{})
+	if len(words) <= n {
+		shingles[text] = struct{}{}
+		return shingles
+	}
+	for i := 0; i+n <= len(words); i++ {
+		shingles[strings.Join(words[i:i+n], " ")] = struct{}{}
+	}
+	return shingles
+}
+
+// jaccard computes |a∩b| / |a∪b|, returning 0 early when the set sizes alone rule out reaching threshold.
+func jaccard(a, b map[string]struct{}, threshold float64) float64 {
+	small, large := a, b
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	if len(large) == 0 || float64(len(small))/float64(len(large)) < threshold {
+		return 0
+	}
+	intersection := 0
+	for shingle := range small {
+		if _, ok := large[shingle]; ok {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
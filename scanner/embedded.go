@@ -0,0 +1,158 @@
+// scanner/embedded.go
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// EmbeddedOrigin describes the code or template a config value holds, for findings taken from it.
+type EmbeddedOrigin struct {
+	Language string `json:"language"` // "python", "javascript", "typescript", "bash", "ruby", "php" or "jinja"
+	Key      string `json:"key"`      // Key path of the config value, e.g. "jobs.build.steps[2].run"
+}
+
+// embeddedLanguageLines are line patterns typical of each language that config values hold code
+// in. Python and Ruby both start methods with def; only Python ends the line with a colon.
+var embeddedLanguageLines = map[string]*regexp.Regexp{
+	"python":     regexp.MustCompile(`^\s*(def \w+\(.*\).*:\s*$|class \w+.*:\s*$|import \w|from [\w.]+ import |if __name__ ==|print\(|@\w+)`),
+	"javascript": regexp.MustCompile(`^\s*(const \w+ =|let \w+ =|var \w+ =|(async )?function\b|import .* from |export |module\.exports|.*\brequire\(['"]|.*=> \{?\s*$|console\.log\()`),
+	"bash":       regexp.MustCompile(`^\s*(echo |export \w+=|curl |cat <<|if \[|for \w+ in |fi\s*$|done\s*$|set -[euxo]|\w+=\S|.*\$\(|cd |mkdir )`),
+	"ruby":       regexp.MustCompile(`^\s*(require ['"]|def \w+[^:]*$|puts |end\s*$|module \w|class \w+( < [\w:]+)?\s*$)`),
+}
+
+// jinjaStatement matches Jinja or Liquid statement tags such as {% for doc in docs %}.
+var jinjaStatement = regexp.MustCompile(`\{%-?\s*(for|if|set|macro|block|include|raw)\b`)
+
+// embeddedLanguage returns the language of code held in a multi-line config value, or "jinja" for
+// a template, or "" for anything else. A shebang or "<?php" tag decides on its own; otherwise at
+// least two lines must look like one language and more of them like it than like any other.
+func embeddedLanguage(text string) string {
+	if !strings.Contains(strings.TrimSpace(text), "\n") {
+		return ""
+	}
+	firstLine, _, _ := strings.Cut(strings.TrimLeft(text, " \t\r\n"), "\n")
+	if rest, ok := strings.CutPrefix(firstLine, "#!"); ok {
+		if lang := interpreterLanguage(strings.Fields(rest)); lang != "" {
+			return lang
+		}
+	}
+	if strings.HasPrefix(firstLine, "<?php") {
+		return "php"
+	}
+	if jinjaStatement.MatchString(text) {
+		return "jinja"
+	}
+	counts := make(map[string]int)
+	for _, line := range strings.Split(text, "\n") {
+		for lang, re := range embeddedLanguageLines {
+			if re.MatchString(line) {
+				counts[lang]++
+			}
+		}
+	}
+	best, bestCount, tied := "", 0, false
+	for lang, n := range counts {
+		switch {
+		case n > bestCount:
+			best, bestCount, tied = lang, n, false
+		case n == bestCount:
+			tied = true
+		}
+	}
+	if bestCount < 2 || tied {
+		return ""
+	}
+	return best
+}
+
+// valueLanguage is embeddedLanguage for a config value, or "" when MaxNestingDepth turns off the
+// parsing of embedded code and templates.
+func (s *Scanner) valueLanguage(text string) string {
+	if s.Options.MaxNestingDepth < 0 {
+		return ""
+	}
+	return embeddedLanguage(text)
+}
+
+// isEmbeddedCode reports whether lang, as returned by embeddedLanguage, is parsed on its own.
+// Templates are prompts themselves, so they are evaluated as a whole.
+func isEmbeddedCode(lang string) bool {
+	return lang != "" && lang != "jinja"
+}
+
+// templateOrigin returns the EmbeddedOrigin of a config value holding a template, or nil.
+func templateOrigin(lang, keyPath string) *EmbeddedOrigin {
+	if lang != "jinja" {
+		return nil
+	}
+	return &EmbeddedOrigin{Language: lang, Key: keyPath}
+}
+
+// parseEmbeddedCode runs the parser of lang over code held in the config value at keyPath, whose
+// first line is line firstLine of the file; with sameLine, as for JSON strings, the whole value
+// is on that line. The findings point into the config file and record their EmbeddedOrigin.
+func (s *Scanner) parseEmbeddedCode(filePath, lang, keyPath, code string, firstLine int, sameLine bool) []FoundPrompt {
+	origin := &EmbeddedOrigin{Language: lang, Key: keyPath}
+	place := func(p *FoundPrompt) {
+		if sameLine {
+			p.Line, p.EndLine = firstLine, firstLine
+		} else {
+			p.Line += firstLine - 1
+			p.EndLine += firstLine - 1
+		}
+		p.Embedded = origin
+	}
+	if reason := s.nestingLimit(lang, code); reason != "" {
+		log.Printf("Warning: not parsing %s code at %s in %s: %s", lang, keyPath, filePath, reason)
+		return nil
+	}
+	parser := s.fileParser(filePath, lang, []byte(code))
+	parser.grammars = s.grammars
+	parser.nesting = append(slices.Clip(s.nesting), nestingKey(lang, code))
+	if s.observe != nil {
+		parser.observe = func(ctx PromptContext, fp FoundPrompt, accepted bool) {
+			place(&fp)
+			s.observe(ctx, fp, accepted)
+		}
+	}
+	prompts, err := parser.parseContent(filePath, lang, []byte(code))
+	if err != nil {
+		if s.Options.Verbose {
+			log.Printf("Warning: error parsing %s code at %s in %s: %v", lang, keyPath, filePath, err)
+		}
+		return nil
+	}
+	for i := range prompts {
+		place(&prompts[i])
+	}
+	return prompts
+}
+
+// nestingLimit returns why code held in the code being parsed must not be parsed in turn, or "":
+// it is nested deeper than MaxNestingDepth, or it is the same code as one of the levels holding
+// it, which would loop forever.
+func (s *Scanner) nestingLimit(lang, code string) string {
+	maxDepth := s.Options.MaxNestingDepth
+	if maxDepth == 0 {
+		maxDepth = DefaultMaxNestingDepth
+	}
+	if len(s.nesting) >= maxDepth {
+		return fmt.Sprintf("nested deeper than the limit of %d levels", maxDepth)
+	}
+	if slices.Contains(s.nesting, nestingKey(lang, code)) {
+		return "it holds itself"
+	}
+	return ""
+}
+
+// nestingKey identifies code of lang in Scanner.nesting.
+func nestingKey(lang, code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return lang + ":" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,152 @@
+// scanner/export.go
+package scanner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	Extension string // ".md" (the default) or ".txt"
+	Overwrite bool   // Replace files that already exist instead of keeping them
+}
+
+// ExportedPrompt is the front matter of a prompt file written by Export.
+type ExportedPrompt struct {
+	Source       string   `yaml:"source"` // Relative to the scanned root, with forward slashes
+	Line         int      `yaml:"line"`
+	EndLine      int      `yaml:"end_line,omitempty"`
+	Variable     string   `yaml:"variable,omitempty"`
+	Symbol       string   `yaml:"symbol,omitempty"`
+	ID           string   `yaml:"id,omitempty"`
+	SHA256       string   `yaml:"sha256"` // PromptHash of the text below the front matter
+	Fingerprint  string   `yaml:"fingerprint"`
+	Commit       string   `yaml:"commit,omitempty"`
+	Permalink    string   `yaml:"permalink,omitempty"`
+	Placeholders []string `yaml:"placeholders,omitempty"`
+
+	File string `yaml:"-"` // Relative to the export directory
+	Kept bool   `yaml:"-"` // The file already existed and was left alone
+}
+
+// Export writes every accepted prompt to its own file in dir, named after its source file and
+// variable (the SYSTEM_PROMPT of src/agent.py becomes agent-system_prompt.md), so that prompts can
+// be moved into a prompt library. Each file starts with a YAML front matter block describing where
+// the prompt came from, followed by the prompt's exact text. Files that already exist are kept
+// unless opts.Overwrite is set, so prompts edited since an earlier export are not lost.
+func Export(dir string, meta ReportMeta, prompts []FoundPrompt, opts ExportOptions) ([]ExportedPrompt, error) {
+	ext := opts.Extension
+	if ext == "" {
+		ext = ".md"
+	}
+	if ext != ".md" && ext != ".txt" {
+		return nil, fmt.Errorf("unsupported export extension '%s': expected .md or .txt", ext)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	var exported []ExportedPrompt
+	used := make(map[string]bool)
+	for _, p := range prompts {
+		if p.Rejected {
+			continue
+		}
+		source := filepath.ToSlash(meta.DisplayPath(p.Filepath))
+		stem := exportStem(source, p)
+		file := stem + ext
+		for n := 2; used[file]; n++ {
+			file = fmt.Sprintf("%s-%d%s", stem, n, ext)
+		}
+		used[file] = true
+		header := ExportedPrompt{
+			Source:       source,
+			Line:         p.Line,
+			EndLine:      p.EndLine,
+			Variable:     p.VariableName,
+			Symbol:       p.Symbol,
+			ID:           p.ID,
+			SHA256:       PromptHash(p.Content),
+			Fingerprint:  PromptFingerprint(p.Content),
+			Commit:       meta.Commit,
+			Permalink:    meta.Permalink(p),
+			Placeholders: p.Slots,
+			File:         file,
+		}
+		path := filepath.Join(dir, file)
+		if _, err := os.Stat(path); err == nil && !opts.Overwrite {
+			header.Kept = true
+			exported = append(exported, header)
+			continue
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to check %s: %w", path, err)
+		}
+		data, err := exportFile(header, p.Content)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		exported = append(exported, header)
+	}
+	return exported, nil
+}
+
+// exportFile renders a prompt file: the front matter, then the prompt text as found.
+func exportFile(header ExportedPrompt, content string) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(header); err != nil {
+		return nil, fmt.Errorf("marshalling front matter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("marshalling front matter: %w", err)
+	}
+	b.WriteString("---\n")
+	b.WriteString(content)
+	return b.Bytes(), nil
+}
+
+// exportStem names the file of a prompt after its source file and the variable, or the
+// innermost enclosing symbol, holding it: "agent-system_prompt". Prompts with neither are named
+// after their line, "agent-l12".
+func exportStem(source string, p FoundPrompt) string {
+	base := filepath.Base(filepath.FromSlash(source))
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	name := p.VariableName
+	if name == "" && p.Symbol != "" {
+		name = p.Symbol[strings.LastIndex(p.Symbol, ".")+1:]
+	}
+	if name == "" {
+		name = fmt.Sprintf("L%d", p.Line)
+	}
+	return exportSlug(base) + "-" + exportSlug(name)
+}
+
+// exportSlug lowercases s and replaces the characters that are awkward in file names with dashes.
+func exportSlug(s string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.':
+			return unicode.ToLower(r)
+		default:
+			return '-'
+		}
+	}, s)
+	slug = strings.Trim(slug, "-.")
+	if slug == "" {
+		return "prompt"
+	}
+	return slug
+}
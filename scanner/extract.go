@@ -0,0 +1,165 @@
+// scanner/extract.go
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExtractManifestFile is the manifest Extract writes next to the extracted prompt files.
+const ExtractManifestFile = "manifest.json"
+
+// extractFormatVersion is bumped whenever the layout of extract manifests changes.
+const extractFormatVersion = 1
+
+// ExtractManifest maps every prompt file written by Extract back to the literal it was taken
+// from, so that a migration of inline prompts to a prompt registry stays traceable.
+type ExtractManifest struct {
+	Version     int               `json:"version"`
+	Tool        ToolInfo          `json:"tool"`
+	Target      string            `json:"target"`
+	Commit      string            `json:"commit,omitempty"` // Commit the target was at, when it is a git checkout
+	Ref         string            `json:"ref,omitempty"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Prompts     []ExtractedPrompt `json:"prompts"`
+}
+
+// ExtractedPrompt is one prompt file of an extract.
+type ExtractedPrompt struct {
+	File   string        `json:"file"`   // Relative to the manifest's directory, with forward slashes
+	ID     string        `json:"id"`     // Finding ID, as in JSON reports
+	SHA256 string        `json:"sha256"` // Hex SHA-256 of the file, which holds the literal's exact text
+	Source ExtractSource `json:"source"`
+	// Placeholders are the template slot names found in the prompt, e.g. ["context", "question"].
+	Placeholders []string `json:"placeholders"`
+}
+
+// ExtractSource is where an extracted prompt was found.
+type ExtractSource struct {
+	Path      string `json:"path"` // Relative to the scanned root, with forward slashes
+	Line      int    `json:"line"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Symbol    string `json:"symbol,omitempty"`
+	Permalink string `json:"permalink,omitempty"`
+}
+
+// PromptHash returns the hex SHA-256 that ExtractedPrompt.SHA256 records for a prompt's content.
+func PromptHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Extract writes the text of every accepted prompt to its own file under dir, mirroring the
+// source tree: the prompt on line 12 of src/agent.py is written to src/agent.py.L12.txt. It then
+// writes the ExtractManifestFile. Files listed by a manifest already in dir are removed first, so
+// that extracting again leaves no stale prompts behind; other files in dir are left alone.
+func Extract(dir string, meta ReportMeta, toolVersion string, prompts []FoundPrompt) (*ExtractManifest, error) {
+	if toolVersion == "" {
+		toolVersion = "dev"
+	}
+	manifestPath := filepath.Join(dir, ExtractManifestFile)
+	if err := removeExtractedFiles(dir, manifestPath); err != nil {
+		return nil, err
+	}
+	manifest := &ExtractManifest{
+		Version:     extractFormatVersion,
+		Tool:        ToolInfo{Name: "prompt-scanner", Version: toolVersion, HeuristicsVersion: HeuristicsVersion},
+		Target:      meta.Target,
+		Commit:      meta.Commit,
+		Ref:         meta.Ref,
+		GeneratedAt: time.Now().UTC(),
+		Prompts:     []ExtractedPrompt{},
+	}
+	if meta.Reproducible {
+		manifest.GeneratedAt = time.Time{}
+	}
+	used := make(map[string]bool)
+	for _, p := range prompts {
+		if p.Rejected {
+			continue
+		}
+		source := filepath.ToSlash(meta.DisplayPath(p.Filepath))
+		// Several literals can start on the same line.
+		file := fmt.Sprintf("%s.L%d.txt", source, p.Line)
+		for n := 2; used[file]; n++ {
+			file = fmt.Sprintf("%s.L%d-%d.txt", source, p.Line, n)
+		}
+		used[file] = true
+		if filepath.IsAbs(filepath.FromSlash(file)) || strings.HasPrefix(file, "../") {
+			return nil, fmt.Errorf("cannot extract %s: not under the scanned directory", source)
+		}
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, []byte(p.Content), 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		manifest.Prompts = append(manifest.Prompts, ExtractedPrompt{
+			File:   file,
+			ID:     p.ID,
+			SHA256: PromptHash(p.Content),
+			Source: ExtractSource{
+				Path:      source,
+				Line:      p.Line,
+				EndLine:   p.EndLine,
+				Symbol:    p.Symbol,
+				Permalink: meta.Permalink(p),
+			},
+			Placeholders: append([]string{}, p.Slots...),
+		})
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshalling manifest: %w", err)
+	}
+	if err := os.WriteFile(manifestPath, append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write manifest %s: %w", manifestPath, err)
+	}
+	return manifest, nil
+}
+
+// LoadExtractManifest reads a manifest written by Extract.
+func LoadExtractManifest(path string) (*ExtractManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+	manifest := &ExtractManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if manifest.Version != extractFormatVersion {
+		return nil, fmt.Errorf("manifest %s has format version %d, this build reads version %d; extract the prompts again", path, manifest.Version, extractFormatVersion)
+	}
+	return manifest, nil
+}
+
+// removeExtractedFiles deletes the prompt files listed by the manifest at manifestPath, if any.
+func removeExtractedFiles(dir, manifestPath string) error {
+	if _, err := os.Stat(manifestPath); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	previous, err := LoadExtractManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	for _, prompt := range previous.Prompts {
+		file := filepath.FromSlash(prompt.File)
+		if filepath.IsAbs(file) || file == ".." || strings.HasPrefix(file, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, file)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove previously extracted %s: %w", prompt.File, err)
+		}
+	}
+	return nil
+}
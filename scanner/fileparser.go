@@ -0,0 +1,76 @@
+// scanner/fileparser.go
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// FileParser finds the prompts in files of a language or format the scanner does not handle
+// itself. Parse is called with a scanner holding the state of the one file being parsed, whose
+// EvaluateCandidate runs each candidate string through the pragmas, filters and heuristics, and
+// whose built-in parsers (ParseYAMLFile, ParseTextFile, ...) can handle embedded content such as
+// front matter. Parse may be called concurrently for different files.
+type FileParser interface {
+	// Language names the files the parser handles in LanguageOverrides, progress events and
+	// statistics, e.g. "markdown".
+	Language() string
+	Parse(s *Scanner, filePath string, contentBytes []byte) ([]FoundPrompt, error)
+}
+
+// FileMatcher reports whether a registered FileParser handles the file at filePath.
+type FileMatcher func(filePath string) bool
+
+// MatchExtensions returns a FileMatcher for files with one of exts, such as ".md", in any case.
+func MatchExtensions(exts ...string) FileMatcher {
+	return func(filePath string) bool {
+		ext := filepath.Ext(filePath)
+		for _, e := range exts {
+			if strings.EqualFold(ext, e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// registeredParser is a FileParser added with RegisterParser.
+type registeredParser struct {
+	match  FileMatcher
+	parser FileParser
+}
+
+// RegisterParser makes the scanner parse the files matched by matcher with parser, before the
+// built-in parsers are considered, so it can also take over files they handle. Parsers registered
+// first win. RegisterParser must not be called while the scanner is scanning.
+func (s *Scanner) RegisterParser(matcher FileMatcher, parser FileParser) {
+	s.parsers = append(s.parsers, registeredParser{match: matcher, parser: parser})
+}
+
+// registeredLanguage returns the language of the registered parser for filePath, or "".
+func (s *Scanner) registeredLanguage(filePath string) string {
+	for _, p := range s.parsers {
+		if p.match(filePath) {
+			return p.parser.Language()
+		}
+	}
+	return ""
+}
+
+// registeredParser returns the registered parser for lang, or nil.
+func (s *Scanner) registeredParser(lang string) FileParser {
+	for _, p := range s.parsers {
+		if p.parser.Language() == lang {
+			return p.parser
+		}
+	}
+	return nil
+}
+
+// EvaluateCandidate decides whether a candidate string found by a FileParser is reported. fp holds
+// at least the Filepath, Line, EndLine and Content of the string, and ctx its Text along with
+// whatever context the parser knows, such as the VariableName. Accepted candidates are annotated
+// with their kind, severity and the other fields of built-in findings.
+func (s *Scanner) EvaluateCandidate(ctx PromptContext, fp *FoundPrompt) bool {
+	return s.evaluateCandidate(ctx, fp)
+}
@@ -0,0 +1,45 @@
+// scanner/findingid.go
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// assignFindingIDs gives each finding of one file a stable ID. The ID hashes the file path
+// relative to the scanned root, the enclosing symbol and the variable or key name, but not the
+// content or line, so a prompt keeps its ID across commits while its text is edited. Findings
+// sharing all three are told apart by their order in the file.
+func assignFindingIDs(relPath string, prompts []FoundPrompt) {
+	seen := make(map[string]int)
+	for i := range prompts {
+		base := relPath + "\x00" + prompts[i].Symbol + "\x00" + prompts[i].VariableName
+		key := base
+		if n := seen[base]; n > 0 {
+			key += fmt.Sprintf("\x00%d", n)
+		}
+		seen[base]++
+		sum := sha256.Sum256([]byte(key))
+		prompts[i].ID = hex.EncodeToString(sum[:6])
+	}
+}
+
+// PromptFingerprint returns the fingerprint of a prompt's text: a hash of the text with leading and
+// trailing whitespace dropped and every other run of whitespace collapsed to one space. Unlike IDs,
+// it does not depend on where the prompt is, so copies of a prompt share it even when they are
+// indented or wrapped differently, and it changes whenever the words do.
+func PromptFingerprint(content string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(content), " ")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// fingerprintTag returns the " [fp:<fingerprint>]" suffix the line-oriented formats add to the
+// message of a finding, or "" for a finding without a fingerprint.
+func fingerprintTag(p FoundPrompt) string {
+	if p.Fingerprint == "" {
+		return ""
+	}
+	return " [fp:" + p.Fingerprint + "]"
+}
@@ -0,0 +1,110 @@
+// scanner/fsys.go
+package scanner
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+// ScanFS recursively scans root in fsys, such as an embed.FS, an fstest.MapFS or a zip.Reader,
+// like ScanDirectory scans a directory on disk. root is a slash-separated fs.FS path ("." for the
+// whole of fsys), and findings are reported under it: scanning "." reports "prompts/system.py".
+// The .gitignore and ScannerIgnoreFile files of fsys are honored.
+func (s *Scanner) ScanFS(fsys fs.FS, root string) ([]FoundPrompt, error) {
+	return s.ScanFSContext(context.Background(), fsys, root)
+}
+
+// ScanFSContext is ScanFS until ctx is done, returning the prompts of the files scanned so far
+// along with ctx's error, like ScanDirectoryContext.
+func (s *Scanner) ScanFSContext(ctx context.Context, fsys fs.FS, root string) ([]FoundPrompt, error) {
+	if root == "" {
+		root = "."
+	}
+	// The .gitignore cache is keyed by directory, which names different files on disk and in fsys.
+	s.resetGitIgnoreCache()
+	s.fsys = fsys
+	defer func() {
+		s.fsys = nil
+		s.resetGitIgnoreCache()
+	}()
+	return s.ScanDirectoryContext(ctx, filepath.FromSlash(root))
+}
+
+// fsPath converts a scanned path, possibly rooted by absPath, to the slash-separated, unrooted
+// form fs.FS expects.
+func fsPath(path string) string {
+	path = strings.TrimLeft(filepath.ToSlash(filepath.Clean(path)), "/")
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+// readFile reads a scanned file from fsys during ScanFS, or from disk.
+func (s *Scanner) readFile(path string) ([]byte, error) {
+	if s.fsys != nil {
+		return fs.ReadFile(s.fsys, fsPath(path))
+	}
+	return os.ReadFile(path)
+}
+
+// openFile opens a scanned file from fsys during ScanFS, or from disk.
+func (s *Scanner) openFile(path string) (io.ReadCloser, error) {
+	if s.fsys != nil {
+		return s.fsys.Open(fsPath(path))
+	}
+	return os.Open(path)
+}
+
+// stat describes a scanned path in fsys during ScanFS, or on disk.
+func (s *Scanner) stat(path string) (fs.FileInfo, error) {
+	if s.fsys != nil {
+		return fs.Stat(s.fsys, fsPath(path))
+	}
+	return os.Stat(path)
+}
+
+// walkDir walks root in fsys during ScanFS, or on disk. Paths passed to fn use the OS separator
+// either way, so the rest of the scanner handles them alike.
+func (s *Scanner) walkDir(root string, fn fs.WalkDirFunc) error {
+	if s.fsys != nil {
+		return fs.WalkDir(s.fsys, fsPath(root), func(path string, d fs.DirEntry, err error) error {
+			return fn(filepath.FromSlash(path), d, err)
+		})
+	}
+	return filepath.WalkDir(root, fn)
+}
+
+// absPath returns the absolute form of a scanned path. Paths in fsys are rooted at the root of
+// fsys, so that "." becomes "/".
+func (s *Scanner) absPath(path string) (string, error) {
+	if s.fsys != nil {
+		return filepath.Join(string(filepath.Separator), path), nil
+	}
+	return filepath.Abs(path)
+}
+
+// compileIgnoreFile compiles the ignore file at path, in fsys during ScanFS or on disk.
+func (s *Scanner) compileIgnoreFile(path string) (*gitignore.GitIgnore, error) {
+	if s.fsys == nil {
+		return gitignore.CompileIgnoreFile(path)
+	}
+	data, err := s.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return gitignore.CompileIgnoreLines(strings.Split(string(data), "\n")...), nil
+}
+
+// resetGitIgnoreCache forgets the compiled .gitignore files.
+func (s *Scanner) resetGitIgnoreCache() {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+	s.gitIgnoreCache = make(map[string]gitignore.IgnoreParser)
+}
@@ -0,0 +1,36 @@
+// scanner/gate.go
+package scanner
+
+// Exit codes of a scan gated by a FailPolicy, as used by the command line tool.
+const (
+	ExitClean    = 0 // The findings are within the policy
+	ExitFindings = 1 // The scan reported more findings than the policy allows
+	ExitError    = 2 // The scan could not be completed
+	// ExitInterrupted is returned when the scan is interrupted (SIGINT) and reports partial results.
+	ExitInterrupted = 130
+)
+
+// FailPolicy decides whether the findings of a scan fail a CI gate.
+type FailPolicy struct {
+	// Threshold is the number of findings a scan may report and still pass: 0 fails on any
+	// finding, a negative threshold disables the gate. Rejected candidates are not counted.
+	Threshold int
+}
+
+// ScanResult is the outcome of a FailPolicy for the summary of a scan.
+type ScanResult struct {
+	Summary   ScanSummary `json:"summary"`
+	Threshold int         `json:"threshold"`
+	Failed    bool        `json:"failed"`
+	ExitCode  int         `json:"exit_code"`
+}
+
+// Evaluate applies the policy to summary.
+func (p FailPolicy) Evaluate(summary ScanSummary) ScanResult {
+	result := ScanResult{Summary: summary, Threshold: p.Threshold, ExitCode: ExitClean}
+	if p.Threshold >= 0 && summary.TotalFindings > p.Threshold {
+		result.Failed = true
+		result.ExitCode = ExitFindings
+	}
+	return result
+}
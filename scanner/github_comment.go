@@ -0,0 +1,112 @@
+// scanner/github_comment.go
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultGitHubAPIURL is the REST API of github.com.
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// PRCommenter posts pr-comment bodies to pull requests through the GitHub REST API. Each pull
+// request gets one comment: when it already has one starting with PRCommentMarker, that comment is
+// edited instead of a new one being posted, so pushes do not flood the conversation.
+type PRCommenter struct {
+	Repo      string // "owner/name"
+	Token     string // Token allowed to write pull request comments, e.g. GITHUB_TOKEN in Actions
+	APIURL    string // Empty uses DefaultGitHubAPIURL; GitHub Enterprise Server uses https://HOST/api/v3
+	UserAgent string
+	Client    *http.Client // nil uses a client with a 30 second timeout
+}
+
+// gitHubComment is the part of a GitHub issue comment the commenter reads.
+type gitHubComment struct {
+	ID      int64  `json:"id"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Post creates or updates the scan comment on pull request number and returns its web URL.
+func (c *PRCommenter) Post(ctx context.Context, number int, body string) (string, error) {
+	existing, err := c.findComment(ctx, number)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return "", fmt.Errorf("marshalling comment: %w", err)
+	}
+	var comment gitHubComment
+	if existing != nil {
+		err = c.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/issues/comments/%d", c.Repo, existing.ID), payload, &comment)
+	} else {
+		err = c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", c.Repo, number), payload, &comment)
+	}
+	if err != nil {
+		return "", err
+	}
+	return comment.HTMLURL, nil
+}
+
+// findComment returns the earlier scan comment on pull request number, or nil.
+func (c *PRCommenter) findComment(ctx context.Context, number int) (*gitHubComment, error) {
+	for page := 1; ; page++ {
+		var comments []gitHubComment
+		path := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=100&page=%d", c.Repo, number, page)
+		if err := c.do(ctx, http.MethodGet, path, nil, &comments); err != nil {
+			return nil, err
+		}
+		for i := range comments {
+			if strings.HasPrefix(comments[i].Body, PRCommentMarker) {
+				return &comments[i], nil
+			}
+		}
+		if len(comments) < 100 {
+			return nil, nil
+		}
+	}
+}
+
+// do sends a request to the API and decodes the JSON response into out.
+func (c *PRCommenter) do(ctx context.Context, method, path string, payload []byte, out any) error {
+	apiURL := c.APIURL
+	if apiURL == "" {
+		apiURL = DefaultGitHubAPIURL
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(apiURL, "/")+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("invalid GitHub API URL %s: %w", apiURL, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub API %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GitHub API %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode GitHub API response: %w", err)
+	}
+	return nil
+}
@@ -106,10 +106,16 @@ func (s *Scanner) ParseGoFile(filePath string, contentBytes []byte) ([]FoundProm
 			// `throw` is not a Go keyword.
 		}
 	foundPrimaryContext:
+		if s.skipLiteral(val, startLine, varName, isMultiLineExplicit) {
+			return true
+		}
+		symbol := goSymbolBreadcrumb(node.Name.Name, varPath)
 
 		fp := FoundPrompt{
 			Filepath:    filePath,
 			Line:        startLine,
+			EndLine:     fset.Position(basicLit.End()).Line,
+			Symbol:      symbol,
 			Content:     val,
 			IsMultiLine: isMultiLineExplicit || linesInContent > 1,
 		}
@@ -123,10 +129,35 @@ func (s *Scanner) ParseGoFile(filePath string, contentBytes []byte) ([]FoundProm
 			InvocationReceiverName: invReceiverName,
 		}
 
-		if s.IsPotentialPrompt(context, &fp) {
+		if s.evaluateCandidate(context, &fp) {
 			prompts = append(prompts, fp)
 		}
 		return true
 	})
 	return prompts, nil
 }
+
+// goSymbolBreadcrumb returns "package.Func" or "package.Type.Method" for the function enclosing the
+// innermost node of path, or just the package name at file scope.
+func goSymbolBreadcrumb(packageName string, path []ast.Node) string {
+	for _, n := range path {
+		funcDecl, ok := n.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+			recvType := funcDecl.Recv.List[0].Type
+			if star, isStar := recvType.(*ast.StarExpr); isStar {
+				recvType = star.X
+			}
+			if generic, isGeneric := recvType.(*ast.IndexExpr); isGeneric {
+				recvType = generic.X
+			}
+			if ident, isIdent := recvType.(*ast.Ident); isIdent {
+				return packageName + "." + ident.Name + "." + funcDecl.Name.Name
+			}
+		}
+		return packageName + "." + funcDecl.Name.Name
+	}
+	return packageName
+}
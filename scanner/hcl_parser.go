@@ -0,0 +1,190 @@
+// scanner/hcl_parser.go
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/hcl"
+
+	"github.com/alexferrari88/prompt-scanner/utils"
+)
+
+// ParseHCLFile parses HCL and Terraform files. Quoted strings and heredocs in attribute values
+// are candidates; the dotted path of block labels and attribute or object keys is used as the
+// variable name, e.g. `variable.system_prompt.default`. Interpolations such as ${var.company}
+// are kept verbatim.
+func (s *Scanner) ParseHCLFile(filePath string, contentBytes []byte) ([]FoundPrompt, error) {
+	tree, err := s.grammars.parse(s.context(), "hcl", hcl.GetLanguage(), contentBytes, s.Options.ParseTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("ts parsing error for %s: %w", filePath, err)
+	}
+	defer tree.Close()
+
+	var prompts []FoundPrompt
+	ext := filepath.Ext(filePath)
+
+	evaluate := func(keyPath, val string, line int, isHeredoc bool) {
+		if val == "" || s.skipLiteral(val, line, keyPath, isHeredoc) {
+			return
+		}
+		linesInContent := utils.CountNewlines(val) + 1
+		isMultiLineExplicit := isHeredoc || strings.Contains(val, "\n")
+		fp := FoundPrompt{
+			Filepath:    filePath,
+			Line:        line,
+			EndLine:     line + utils.CountNewlines(val),
+			Content:     val,
+			IsMultiLine: isMultiLineExplicit || linesInContent > 1,
+		}
+		ctx := PromptContext{
+			Text:                val,
+			VariableName:        keyPath,
+			IsMultiLineExplicit: isMultiLineExplicit,
+			LinesInContent:      linesInContent,
+			FileExtension:       ext,
+		}
+		if s.evaluateCandidate(ctx, &fp) {
+			prompts = append(prompts, fp)
+		}
+	}
+
+	var walk func(node *sitter.Node, keyPath string)
+	walk = func(node *sitter.Node, keyPath string) {
+		switch node.Type() {
+		case "block":
+			// Block type and labels form the path: resource "aws_lambda_function" "bot" {...}
+			blockPath := keyPath
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				child := node.NamedChild(i)
+				switch child.Type() {
+				case "identifier":
+					blockPath = joinKeyPath(blockPath, child.Content(contentBytes))
+				case "string_lit":
+					label, _ := hclStringContent(child, contentBytes)
+					blockPath = joinKeyPath(blockPath, label)
+				case "body":
+					if !s.isIgnoredKey(blockPath) {
+						walk(child, blockPath)
+					}
+				}
+			}
+			return
+		case "attribute":
+			name := node.NamedChild(0)
+			if name == nil {
+				return
+			}
+			attrPath := joinKeyPath(keyPath, name.Content(contentBytes))
+			if s.isIgnoredKey(attrPath) {
+				return
+			}
+			for i := 1; i < int(node.NamedChildCount()); i++ {
+				walk(node.NamedChild(i), attrPath)
+			}
+			return
+		case "object_elem":
+			key, val := node.ChildByFieldName("key"), node.ChildByFieldName("val")
+			if key == nil || val == nil {
+				return
+			}
+			keyText := key.Content(contentBytes)
+			if literal := hclFirstOfType(key, "string_lit"); literal != nil {
+				keyText, _ = hclStringContent(literal, contentBytes)
+			}
+			elemPath := joinKeyPath(keyPath, keyText)
+			if !s.isIgnoredKey(elemPath) {
+				walk(val, elemPath)
+			}
+			return
+		case "tuple":
+			index := 0
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				if child := node.NamedChild(i); child.Type() == "expression" {
+					walk(child, fmt.Sprintf("%s[%d]", keyPath, index))
+					index++
+				}
+			}
+			return
+		case "string_lit":
+			val, line := hclStringContent(node, contentBytes)
+			evaluate(keyPath, val, line, false)
+			return
+		case "heredoc_template":
+			val, line := hclHeredocContent(node, contentBytes)
+			evaluate(keyPath, val, line, true)
+			return
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i), keyPath)
+		}
+	}
+	walk(tree.RootNode(), "")
+	return prompts, nil
+}
+
+// joinKeyPath appends a segment to a dotted key path.
+func joinKeyPath(keyPath, segment string) string {
+	if keyPath == "" {
+		return segment
+	}
+	return keyPath + "." + segment
+}
+
+// hclFirstOfType returns the first node of the given type in node's subtree.
+func hclFirstOfType(node *sitter.Node, nodeType string) *sitter.Node {
+	if node.Type() == nodeType {
+		return node
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if found := hclFirstOfType(node.NamedChild(i), nodeType); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// hclStringContent returns the text between the quotes of a string_lit and its 1-based line.
+func hclStringContent(node *sitter.Node, contentBytes []byte) (string, int) {
+	start, end := node.StartByte(), node.EndByte()
+	if startDelim := node.Child(0); startDelim != nil && startDelim.Type() == "quoted_template_start" {
+		start = startDelim.EndByte()
+	}
+	if endDelim := node.Child(int(node.ChildCount()) - 1); endDelim != nil && endDelim.Type() == "quoted_template_end" {
+		end = endDelim.StartByte()
+	}
+	raw := string(contentBytes[start:end])
+	val := strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(raw)
+	return val, int(node.StartPoint().Row) + 1
+}
+
+// hclHeredocContent returns the body of a heredoc and the 1-based line it starts on. Indented
+// heredocs (<<-EOT) are dedented like Terraform does.
+func hclHeredocContent(node *sitter.Node, contentBytes []byte) (string, int) {
+	var opening, closing *sitter.Node
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "heredoc_identifier" {
+			if opening == nil {
+				opening = child
+			} else {
+				closing = child
+			}
+		}
+	}
+	if opening == nil || closing == nil {
+		return "", 0
+	}
+	body := string(contentBytes[opening.EndByte():closing.StartByte()])
+	// Drop the rest of the opening line and the indentation before the closing marker.
+	if newline := strings.Index(body, "\n"); newline >= 0 {
+		body = body[newline+1:]
+	}
+	body = strings.TrimRight(body, " \t")
+	body = strings.TrimSuffix(body, "\n")
+	if start := node.Child(0); start != nil && strings.HasSuffix(start.Content(contentBytes), "-") {
+		body = dedentLines(body)
+	}
+	return body, int(opening.StartPoint().Row) + 2
+}
@@ -3,8 +3,11 @@ package scanner
 
 import (
 	"fmt"
+	"path"
 	"regexp"
 	"strings"
+
+	"github.com/alexferrari88/prompt-scanner/utils"
 )
 
 var (
@@ -14,7 +17,7 @@ var (
 		"debug": true, "fatal": true, "trace": true, "print": true, "println": true,
 		"printf": true, "exception": true, "verbose": true, "notice": true,
 		"critical": true, "alert": true, "emerg": true, "emergency": true,
-		"write": true,
+		"write": true, "puts": true,
 	}
 	// Common logger object/receiver names or prefixes (case-insensitive)
 	loggingReceiverNames = map[string]bool{
@@ -28,9 +31,19 @@ var (
 		"failed to", "unable to", "could not", "exception:", "uncaught", "unhandled",
 		"trace:", "notice:", "critical:", "alert:", "emerg:", "emergency:",
 	}
-	compiledLogMessagePrefixes []*regexp.Regexp
+	compiledLogMessagePrefixes = compileLogMessagePrefixes()
 )
 
+// compileLogMessagePrefixes compiles logMessagePrefixes once for the package, so that scanners
+// created and run concurrently share them without writing package state.
+func compileLogMessagePrefixes() []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(logMessagePrefixes))
+	for _, prefix := range logMessagePrefixes {
+		compiled = append(compiled, regexp.MustCompile(`(?i)^\s*`+regexp.QuoteMeta(prefix)))
+	}
+	return compiled
+}
+
 func (so *ScanOptions) compileMatchers() error {
 	if len(so.VariableKeywords) > 0 {
 		pattern := `(?i)\b(` + strings.Join(so.VariableKeywords, "|") + `)\b`
@@ -60,50 +73,186 @@ func (so *ScanOptions) compileMatchers() error {
 		so.compiledPlaceholders = append(so.compiledPlaceholders, re)
 	}
 
-	// Compile log message prefixes
-	compiledLogMessagePrefixes = make([]*regexp.Regexp, 0, len(logMessagePrefixes))
-	for _, prefix := range logMessagePrefixes {
-		re, err := regexp.Compile(`(?i)^\s*` + regexp.QuoteMeta(prefix))
+	for _, pattern := range so.PromptFilenamePatterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid prompt filename pattern '%s': %w", pattern, err)
+		}
+	}
+
+	so.compiledIgnoreKeys = make([]*regexp.Regexp, 0, len(so.IgnoreKeys))
+	for _, pattern := range so.IgnoreKeys {
+		if pattern == "" {
+			continue
+		}
+		quoted := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, `.*`)
+		re, err := regexp.Compile(`(?i)(?:^|\.)` + quoted + `$`)
+		if err != nil {
+			return fmt.Errorf("compiling ignore key pattern '%s': %w", pattern, err)
+		}
+		so.compiledIgnoreKeys = append(so.compiledIgnoreKeys, re)
+	}
+
+	if so.TraceLocation != "" {
+		loc, err := parseTraceLocation(so.TraceLocation)
 		if err != nil {
-			return fmt.Errorf("compiling log message prefix '%s': %w", prefix, err)
+			return err
 		}
-		compiledLogMessagePrefixes = append(compiledLogMessagePrefixes, re)
+		so.compiledTrace = &loc
 	}
 	return nil
 }
 
+// isIgnoredKey reports whether a config key path (e.g. "tools[0].description") matches one of
+// the -ignore-keys patterns. A pattern matches the whole path or its trailing dotted segments, so
+// "description" ignores the key at any depth and "changelog.*" everything below "changelog"; "*"
+// matches any characters. Parsers skip ignored keys together with everything nested below them.
+func (s *Scanner) isIgnoredKey(keyPath string) bool {
+	for _, re := range s.Options.compiledIgnoreKeys {
+		if re.MatchString(keyPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateCandidate decides whether a candidate string is reported. Pragma comments and scan-level
+// filters that don't depend on heuristics (line counts) are applied first, then IsPotentialPrompt
+// or the Classifier. Accepted findings are annotated with their kind, slots, output contracts,
+// severity and audience.
+func (s *Scanner) evaluateCandidate(ctx PromptContext, fp *FoundPrompt) bool {
+	ctx.FileName, ctx.DirNames = s.pathContext(fp.Filepath)
+	s.tracing = s.tracesCandidate(fp)
+	if s.tracing {
+		location := fmt.Sprintf("%s:%d", s.relativePath(fp.Filepath), fp.Line)
+		if ctx.VariableName != "" {
+			location += fmt.Sprintf(" (%s)", ctx.VariableName)
+		}
+		s.tracef("candidate at %s: %q", location, strings.TrimSpace(ctx.Text))
+	}
+	accepted := s.acceptCandidate(ctx, fp)
+	if s.tracing {
+		verdict := "rejected"
+		if accepted {
+			verdict = "reported"
+		}
+		s.tracef("verdict: %s", verdict)
+		s.tracing = false
+	}
+	if s.observe != nil {
+		s.observe(ctx, *fp, accepted)
+	}
+	return accepted
+}
+
+func (s *Scanner) acceptCandidate(ctx PromptContext, fp *FoundPrompt) bool {
+	pragma := s.pragmas[fp.Line]
+	if pragma == PragmaIgnore {
+		return s.reject(fp, RejectPragma, "pragma %s on line %d", PragmaIgnore, fp.Line)
+	}
+	if !s.passesLineFilters(fp) {
+		return false
+	}
+	slots := extractSlots(ctx.Text)
+	kind := classifyKind(ctx.Text, slots)
+	// Literals marked with PragmaPrompt skip the heuristics. RAG scaffolds are reported even when
+	// they contain none of the content keywords. In constants files every long, sentence-like
+	// string is reported.
+	fp.Marked = pragma == PragmaPrompt
+	switch {
+	case fp.Marked:
+		s.tracef("pragma %s on line %d: accept without heuristics", PragmaPrompt, fp.Line)
+	case s.classify(ctx, fp):
+	case kind == KindRAGScaffold:
+		s.tracef("RAG scaffold (slots %s): accept despite the heuristics", strings.Join(slots, ", "))
+	case s.constantsFile && s.isConstantsCandidate(ctx, fp):
+		s.tracef("constants file: at least %d characters and %d words: accept", s.Options.MinLength, constantsMinWords)
+	default:
+		if s.constantsFile {
+			s.tracef("constants file: shorter than %d characters or fewer than %d words", s.Options.MinLength, constantsMinWords)
+		}
+		return false
+	}
+	fp.RejectReason = ""
+	fp.VariableName = ctx.VariableName
+	fp.Slots = slots
+	fp.Kind = kind
+	fp.OutputContracts = extractOutputContracts(ctx.Text)
+	fp.Severity = s.assessSeverity(ctx, fp)
+	fp.Audience = s.classifyAudience(ctx)
+	return true
+}
+
+// passesLineFilters enforces the MultilineOnly and MinLines options.
+func (s *Scanner) passesLineFilters(fp *FoundPrompt) bool {
+	if s.Options.MultilineOnly && !fp.IsMultiLine {
+		return s.reject(fp, RejectMultilineOnly, "multiline-only: single-line string")
+	}
+	if s.Options.MinLines > 1 {
+		lines := utils.CountNewlines(strings.TrimSpace(fp.Content)) + 1
+		if lines < s.Options.MinLines {
+			return s.reject(fp, RejectMinLines, "min-lines: %d lines < %d", lines, s.Options.MinLines)
+		}
+	}
+	return true
+}
+
 func (s *Scanner) IsPotentialPrompt(ctx PromptContext, fp *FoundPrompt) bool {
 	text := strings.TrimSpace(ctx.Text)
 	if text == "" {
-		return false
+		return s.reject(fp, RejectEmpty, "empty after trimming whitespace")
 	}
 
-	// New logic for the 'greedy' flag
-	if !s.Options.Greedy {
+	preset := s.Options.preset()
+	if !preset.Greedy {
 		lowerText := strings.ToLower(text)
 		isMultiLine := ctx.IsMultiLineExplicit || ctx.LinesInContent > 1
+		pathScore, pathLabel := pathSignal(ctx)
+		s.tracef("%s mode: multi-line=%v, path score %+d", preset.Name, isMultiLine, pathScore)
 
-		// Condition 1: String starts with a content keyword
+		// Condition 1: String starts with a content keyword (and, in strict mode, is long enough)
 		for _, keyword := range s.Options.ContentKeywords {
 			if strings.HasPrefix(lowerText, strings.ToLower(keyword)) {
+				if preset.PrefixNeedsMinLength && len(text) < s.Options.MinLength {
+					s.tracef("condition 1: starts with content keyword %q but %d characters < min-len %d", keyword, len(text), s.Options.MinLength)
+					break
+				}
 				fp.MatchedContentWord = keyword // Record the keyword that matched
+				s.tracef("condition 1: starts with content keyword %q: accept", keyword)
 				return true
 			}
 		}
+		s.tracef("condition 1: does not start with a content keyword")
 
-		// Condition 2: String contains a content keyword AND is multi-line
-		if isMultiLine {
+		// Condition 2: String contains a content keyword AND is multi-line. Under prompt-indicative
+		// paths containing the keyword is enough (except in strict mode); under locale or fixture
+		// paths it never is.
+		if (isMultiLine || (preset.PathShortcut && pathScore > 0)) && pathScore >= 0 {
 			for _, keyword := range s.Options.ContentKeywords {
 				if strings.Contains(lowerText, strings.ToLower(keyword)) {
 					fp.MatchedContentWord = keyword // Record the keyword that matched
+					if !isMultiLine {
+						fp.MatchedPath = pathLabel
+					}
+					s.tracef("condition 2: contains content keyword %q: accept", keyword)
 					return true
 				}
 			}
+			return s.reject(fp, RejectNoKeyword, "condition 2: contains no content keyword")
 		}
-		// If neither of the greedy=false conditions are met, it's not a prompt under this mode.
-		return false
+		// If neither of the keyword conditions are met, it's not a prompt under this mode.
+		if s.Options.compiledContentWords == nil || !s.Options.compiledContentWords.MatchString(text) {
+			return s.reject(fp, RejectNoKeyword, "condition 2: contains no content keyword")
+		}
+		if pathScore < 0 {
+			return s.reject(fp, RejectDemotedPath, "condition 2: skipped under %s", pathLabel)
+		}
+		if !preset.PathShortcut && pathScore > 0 {
+			return s.reject(fp, RejectSingleLine, "condition 2: single-line string (%s mode ignores %s)", preset.Name, pathLabel)
+		}
+		return s.reject(fp, RejectSingleLine, "condition 2: single-line string outside a prompt path")
 	} else {
-		// Original heuristic logic (when greedy is true)
+		// Score-based rules of the greedy preset
+		s.tracef("%s mode", preset.Name)
 		for _, re := range compiledLogMessagePrefixes {
 			if re.MatchString(text) {
 				placeholderFound := false
@@ -114,7 +263,7 @@ func (s *Scanner) IsPotentialPrompt(ctx PromptContext, fp *FoundPrompt) bool {
 					}
 				}
 				if len(text) < 150 && !placeholderFound {
-					return false
+					return s.reject(fp, RejectLogMessage, "starts like a log message (%q), %d characters < 150 and no placeholder", strings.TrimSpace(re.FindString(text)), len(text))
 				}
 			}
 		}
@@ -123,11 +272,15 @@ func (s *Scanner) IsPotentialPrompt(ctx PromptContext, fp *FoundPrompt) bool {
 		lowerReceiverName := strings.ToLower(ctx.InvocationReceiverName)
 
 		if lowerFuncName != "" {
+			s.tracef("argument of call %s", strings.TrimPrefix(ctx.InvocationReceiverName+"."+ctx.InvocationFunctionName, "."))
+			isExceptionType := strings.HasSuffix(lowerFuncName, "exception") || strings.HasSuffix(lowerFuncName, "error")
 			if (lowerFuncName == "error" && (lowerReceiverName == "" || lowerReceiverName == "new")) ||
+				(lowerReceiverName == "new" && isExceptionType) || // e.g. Java: new IllegalStateException("...")
 				lowerFuncName == "throw" || // Added for JS 'throw "string"' which might be captured by parent type
+				(lowerReceiverName == "" && lowerFuncName == "raise") || // Ruby: raise "message" is a plain method call
 				(lowerReceiverName == "" && lowerFuncName == "throw_literal") { // Special marker for throw "literal"
 				if len(text) < 150 && !strings.Contains(text, "{") {
-					return false
+					return s.reject(fp, RejectErrorMessage, "error or exception message, %d characters < 150 and no '{'", len(text))
 				}
 			}
 
@@ -140,78 +293,137 @@ func (s *Scanner) IsPotentialPrompt(ctx PromptContext, fp *FoundPrompt) bool {
 					}
 				}
 				if len(text) < 200 && !placeholderFound {
-					return false
+					return s.reject(fp, RejectLoggingCall, "logging method %s, %d characters < 200 and no placeholder", ctx.InvocationFunctionName, len(text))
 				}
 			}
 			if loggingReceiverNames[lowerReceiverName] && (loggingMethodNames[lowerFuncName] || lowerFuncName == "write") {
 				if len(text) < 100 && !strings.Contains(text, "{") {
-					return false
+					return s.reject(fp, RejectLoggingCall, "logger %s, %d characters < 100 and no '{'", ctx.InvocationReceiverName, len(text))
 				}
 			}
 		}
 
-		score := 0
-		if ctx.VariableName != "" && s.Options.compiledVarKeywords != nil {
-			match := s.Options.compiledVarKeywords.FindString(ctx.VariableName)
-			if match != "" {
-				fp.MatchedVariableName = match
-				score += 3
-			}
-		}
-		if s.Options.compiledContentWords != nil {
-			match := s.Options.compiledContentWords.FindString(text)
-			if match != "" {
-				fp.MatchedContentWord = match
-				score += 2
-			}
-		}
-		for _, re := range s.Options.compiledPlaceholders {
-			match := re.FindString(text)
-			if match != "" {
-				fp.MatchedPlaceholder = match
-				score += 2
-				break
-			}
-		}
-
+		score := s.scoreSignals(ctx, fp)
 		isLongEnough := len(text) >= s.Options.MinLength
 		isMultiLine := ctx.IsMultiLineExplicit || ctx.LinesInContent > 1
-
-		if isMultiLine {
-			score += 1
+		if s.tracing {
+			for _, signal := range s.scoreBreakdown(ctx, *fp) {
+				s.tracef("score %+d: %s", signal.Points, signal.Label)
+			}
+			s.tracef("score %d (%d characters, min-len %d)", score, len(text), s.Options.MinLength)
 		}
-		if isLongEnough {
-			score += 1
+
+		// Under locale or fixture paths only the overall score counts; the shortcuts below
+		// would otherwise accept most translated sentences.
+		if pathScore, pathLabel := pathSignal(ctx); pathScore < 0 {
+			if score < preset.AcceptScore {
+				return s.reject(fp, RejectDemotedPath, "under %s only the score counts: score %d < %d", pathLabel, score, preset.AcceptScore)
+			}
+			s.tracef("under %s only the score counts: score %d >= %d: accept", pathLabel, score, preset.AcceptScore)
+			return true
 		}
 
 		if fp.MatchedVariableName != "" && (isLongEnough || isMultiLine || fp.MatchedContentWord != "" || fp.MatchedPlaceholder != "") {
+			s.tracef("variable name matches and the string is long, multi-line or has a keyword or placeholder: accept")
 			return true
 		}
 		if fp.MatchedContentWord != "" && (isLongEnough || isMultiLine || fp.MatchedPlaceholder != "") {
+			s.tracef("content keyword and the string is long, multi-line or has a placeholder: accept")
 			return true
 		}
 		if fp.MatchedPlaceholder != "" && (isLongEnough || isMultiLine) {
+			s.tracef("placeholder and the string is long or multi-line: accept")
 			return true
 		}
 		if isMultiLine && isLongEnough && score >= 1 {
+			s.tracef("multi-line, long and score >= 1: accept")
 			return true
 		}
 		if isLongEnough && (fp.MatchedContentWord != "" || fp.MatchedPlaceholder != "") {
+			s.tracef("long with a keyword or placeholder: accept")
 			return true
 		}
-		if score >= 2 && isLongEnough {
+		if score >= preset.LongAcceptScore && isLongEnough {
+			s.tracef("long and score >= %d: accept", preset.LongAcceptScore)
 			return true
 		}
-		if score >= 3 {
+		if score >= preset.AcceptScore {
+			s.tracef("score >= %d: accept", preset.AcceptScore)
 			return true
 		}
 
 		if len(text) > s.Options.MinLength*3 && (isMultiLine || strings.ContainsAny(text, ".?!:")) {
 			if score < 2 {
 				fp.MatchedContentWord = "long_string"
+				s.tracef("longer than 3x min-len (%d) and sentence-like: accept", s.Options.MinLength*3)
 				return true
 			}
 		}
-		return false
-	} // End of else (greedy == true)
+		return s.reject(fp, RejectLowScore, "no acceptance rule matched (score %d < %d, or < %d with a string shorter than min-len)", score, preset.AcceptScore, preset.LongAcceptScore)
+	} // End of else (score-based rules)
+}
+
+// scoreSignals adds up the signals greedy mode weighs: a matching variable name (3), content
+// keyword (2) or placeholder (2), multiple lines (1), reaching MinLength (1) and the file's
+// location (see pathSignal). Matched signals are recorded on fp.
+func (s *Scanner) scoreSignals(ctx PromptContext, fp *FoundPrompt) int {
+	text := strings.TrimSpace(ctx.Text)
+	score := 0
+	if ctx.VariableName != "" && s.Options.compiledVarKeywords != nil {
+		match := s.Options.compiledVarKeywords.FindString(ctx.VariableName)
+		if match != "" {
+			fp.MatchedVariableName = match
+			score += 3
+		}
+	}
+	if s.Options.compiledContentWords != nil {
+		match := s.Options.compiledContentWords.FindString(text)
+		if match != "" {
+			fp.MatchedContentWord = match
+			score += 2
+		}
+	}
+	for _, re := range s.Options.compiledPlaceholders {
+		match := re.FindString(text)
+		if match != "" {
+			fp.MatchedPlaceholder = match
+			score += 2
+			break
+		}
+	}
+	if ctx.IsMultiLineExplicit || ctx.LinesInContent > 1 {
+		score += 1
+	}
+	if len(text) >= s.Options.MinLength {
+		score += 1
+	}
+	if pathScore, pathLabel := pathSignal(ctx); pathScore != 0 {
+		fp.MatchedPath = pathLabel
+		score += pathScore
+	}
+	return score
+}
+
+// scoreBreakdown lists the signals scoreSignals counted for ctx, given the fields it set on fp.
+func (s *Scanner) scoreBreakdown(ctx PromptContext, fp FoundPrompt) []ScoreSignal {
+	var signals []ScoreSignal
+	if fp.MatchedVariableName != "" {
+		signals = append(signals, ScoreSignal{fmt.Sprintf("variable name matches %q", fp.MatchedVariableName), 3})
+	}
+	if fp.MatchedContentWord != "" {
+		signals = append(signals, ScoreSignal{fmt.Sprintf("content keyword %q", fp.MatchedContentWord), 2})
+	}
+	if fp.MatchedPlaceholder != "" {
+		signals = append(signals, ScoreSignal{fmt.Sprintf("placeholder %q", fp.MatchedPlaceholder), 2})
+	}
+	if ctx.IsMultiLineExplicit || ctx.LinesInContent > 1 {
+		signals = append(signals, ScoreSignal{"multi-line", 1})
+	}
+	if len(strings.TrimSpace(ctx.Text)) >= s.Options.MinLength {
+		signals = append(signals, ScoreSignal{fmt.Sprintf("at least %d characters", s.Options.MinLength), 1})
+	}
+	if pathScore, pathLabel := pathSignal(ctx); pathScore != 0 {
+		signals = append(signals, ScoreSignal{fmt.Sprintf("path %q", pathLabel), pathScore})
+	}
+	return signals
 }
@@ -0,0 +1,226 @@
+// scanner/history.go
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HistoryRecord is one scan run as stored in a history file (one JSON object per line).
+type HistoryRecord struct {
+	Target     string            `json:"target"`
+	Commit     string            `json:"commit,omitempty"`
+	Project    string            `json:"project,omitempty"`
+	Team       string            `json:"team,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	RecordedAt time.Time         `json:"recorded_at"`
+	Summary    ScanSummary       `json:"summary"`
+}
+
+// AppendHistory appends a record to the history file at path, creating it if needed.
+func AppendHistory(path string, record HistoryRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode history record: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history file %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadHistory loads all records from the history file at path, oldest first.
+func ReadHistory(path string) ([]HistoryRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var records []HistoryRecord
+	lineScanner := bufio.NewScanner(f)
+	lineScanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	for lineScanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(lineScanner.Text())
+		if line == "" {
+			continue
+		}
+		var record HistoryRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid history record: %w", path, lineNum, err)
+		}
+		records = append(records, record)
+	}
+	if err := lineScanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file %s: %w", path, err)
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].RecordedAt.Before(records[j].RecordedAt) })
+	return records, nil
+}
+
+// trendSeries groups the history of a single target.
+type trendSeries struct {
+	Target  string
+	Records []HistoryRecord
+}
+
+// groupByTarget splits records into per-target series, ordered by target.
+func groupByTarget(records []HistoryRecord) []trendSeries {
+	byTarget := make(map[string][]HistoryRecord)
+	for _, record := range records {
+		byTarget[record.Target] = append(byTarget[record.Target], record)
+	}
+	series := make([]trendSeries, 0, len(byTarget))
+	for target, recs := range byTarget {
+		series = append(series, trendSeries{Target: target, Records: recs})
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Target < series[j].Target })
+	return series
+}
+
+// WriteTrend renders findings over time, per target and per severity, as a "markdown" section or
+// an "html" page.
+func WriteTrend(w io.Writer, records []HistoryRecord, format string) error {
+	series := groupByTarget(records)
+	switch format {
+	case "markdown":
+		return writeTrendMarkdown(w, series)
+	case "html":
+		return trendHTMLTemplate.Execute(w, newTrendView(series))
+	default:
+		return fmt.Errorf("unsupported trend format '%s' (use markdown or html)", format)
+	}
+}
+
+func writeTrendMarkdown(w io.Writer, series []trendSeries) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Prompt trend\n")
+	if len(series) == 0 {
+		fmt.Fprintf(&b, "\nNo scan runs recorded yet.\n")
+	}
+	for _, s := range series {
+		fmt.Fprintf(&b, "\n## `%s`\n\n", s.Target)
+		first, last := s.Records[0].Summary, s.Records[len(s.Records)-1].Summary
+		fmt.Fprintf(&b, "%d runs. Findings %d → %d (%+d), hygiene score %d → %d (%+d).\n\n",
+			len(s.Records), first.TotalFindings, last.TotalFindings, last.TotalFindings-first.TotalFindings,
+			first.HygieneScore, last.HygieneScore, last.HygieneScore-first.HygieneScore)
+		fmt.Fprintf(&b, "| Date | Commit | Findings | High | Medium | Low | Score | |\n")
+		fmt.Fprintf(&b, "|---|---|---:|---:|---:|---:|---:|---|\n")
+		maxFindings := maxTotalFindings(s.Records)
+		for _, r := range s.Records {
+			fmt.Fprintf(&b, "| %s | %s | %d | %d | %d | %d | %d | `%s` |\n",
+				r.RecordedAt.Format("2006-01-02 15:04"), shortCommit(r.Commit), r.Summary.TotalFindings,
+				r.Summary.BySeverity[SeverityHigh], r.Summary.BySeverity[SeverityMedium], r.Summary.BySeverity[SeverityLow],
+				r.Summary.HygieneScore, trendBar(r.Summary.TotalFindings, maxFindings, 20))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func maxTotalFindings(records []HistoryRecord) int {
+	maxFindings := 0
+	for _, r := range records {
+		maxFindings = max(maxFindings, r.Summary.TotalFindings)
+	}
+	return maxFindings
+}
+
+// trendBar draws a text bar of value scaled to width columns.
+func trendBar(value, maxValue, width int) string {
+	if maxValue == 0 {
+		return ""
+	}
+	return strings.Repeat("█", value*width/maxValue)
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// trendRowView is one run in the HTML trend chart; the percentages size the stacked bar.
+type trendRowView struct {
+	Date, Commit                   string
+	Summary                        ScanSummary
+	HighPct, MediumPct, LowPct     int
+	High, Medium, Low, ScoreChange int
+}
+
+type trendSeriesView struct {
+	Target string
+	Rows   []trendRowView
+}
+
+func newTrendView(series []trendSeries) []trendSeriesView {
+	views := make([]trendSeriesView, 0, len(series))
+	for _, s := range series {
+		maxFindings := maxTotalFindings(s.Records)
+		view := trendSeriesView{Target: s.Target}
+		for i, r := range s.Records {
+			row := trendRowView{
+				Date:    r.RecordedAt.Format("2006-01-02 15:04"),
+				Commit:  shortCommit(r.Commit),
+				Summary: r.Summary,
+				High:    r.Summary.BySeverity[SeverityHigh],
+				Medium:  r.Summary.BySeverity[SeverityMedium],
+				Low:     r.Summary.BySeverity[SeverityLow],
+			}
+			if maxFindings > 0 {
+				row.HighPct = row.High * 100 / maxFindings
+				row.MediumPct = row.Medium * 100 / maxFindings
+				row.LowPct = row.Low * 100 / maxFindings
+			}
+			if i > 0 {
+				row.ScoreChange = r.Summary.HygieneScore - s.Records[i-1].Summary.HygieneScore
+			}
+			view.Rows = append(view.Rows, row)
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+var trendHTMLTemplate = template.Must(template.New("trend").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Prompt trend</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem; color: #1f2328; }
+table { border-collapse: collapse; margin-bottom: 2rem; }
+th, td { border-bottom: 1px solid #d0d7de; padding: 0.3rem 0.6rem; text-align: right; }
+th:first-child, td:first-child, td.bar { text-align: left; }
+td.bar { width: 300px; }
+.bar span { display: inline-block; height: 0.8rem; }
+.high { background: #cf222e; } .medium { background: #d4a72c; } .low { background: #8c959f; }
+</style>
+</head>
+<body>
+<h1>Prompt trend</h1>
+{{if not .}}<p>No scan runs recorded yet.</p>
+{{end}}{{range .}}<h2><code>{{.Target}}</code></h2>
+<table>
+<tr><th>Date</th><th>Commit</th><th>Findings</th><th>High</th><th>Medium</th><th>Low</th><th>Score</th><th></th></tr>
+{{range .Rows}}<tr><td>{{.Date}}</td><td><code>{{.Commit}}</code></td><td>{{.Summary.TotalFindings}}</td><td>{{.High}}</td><td>{{.Medium}}</td><td>{{.Low}}</td><td>{{.Summary.HygieneScore}}{{if .ScoreChange}} ({{printf "%+d" .ScoreChange}}){{end}}</td>
+<td class="bar"><span class="high" style="width: {{.HighPct}}%"></span><span class="medium" style="width: {{.MediumPct}}%"></span><span class="low" style="width: {{.LowPct}}%"></span></td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
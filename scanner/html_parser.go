@@ -0,0 +1,76 @@
+// scanner/html_parser.go
+package scanner
+
+import (
+	"fmt"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/html"
+)
+
+// ParseHTMLFile scans the inline <script> blocks of an HTML file as JavaScript. Everything outside
+// the scripts is blanked out (newlines are kept), so reported line numbers match the HTML file.
+func (s *Scanner) ParseHTMLFile(filePath string, contentBytes []byte) ([]FoundPrompt, error) {
+	tree, err := s.grammars.parse(s.context(), "html", html.GetLanguage(), contentBytes, s.Options.ParseTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("ts parsing error for %s: %w", filePath, err)
+	}
+	defer tree.Close()
+
+	scriptBytes := make([]byte, len(contentBytes))
+	for i, b := range contentBytes {
+		if b == '\n' {
+			scriptBytes[i] = '\n'
+		} else {
+			scriptBytes[i] = ' '
+		}
+	}
+	hasScript := false
+	var visit func(node *sitter.Node)
+	visit = func(node *sitter.Node) {
+		if node.Type() == "script_element" {
+			if !isInlineJavaScript(node, contentBytes) {
+				return
+			}
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				if child := node.NamedChild(i); child.Type() == "raw_text" {
+					copy(scriptBytes[child.StartByte():child.EndByte()], contentBytes[child.StartByte():child.EndByte()])
+					hasScript = true
+				}
+			}
+			return
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			visit(node.NamedChild(i))
+		}
+	}
+	visit(tree.RootNode())
+
+	if !hasScript {
+		return nil, nil
+	}
+	return s.ParseTreeSitterFile(filePath, scriptBytes, "javascript")
+}
+
+// isInlineJavaScript reports whether a script element holds JavaScript: no type, or a JavaScript,
+// module or JSX (babel) type. JSON data blocks and client-side templates are skipped.
+func isInlineJavaScript(scriptNode *sitter.Node, contentBytes []byte) bool {
+	startTag := scriptNode.NamedChild(0)
+	if startTag == nil || startTag.Type() != "start_tag" {
+		return true
+	}
+	for i := 0; i < int(startTag.NamedChildCount()); i++ {
+		attr := startTag.NamedChild(i)
+		if attr.Type() != "attribute" || attr.NamedChildCount() < 2 {
+			continue
+		}
+		if !strings.EqualFold(attr.NamedChild(0).Content(contentBytes), "type") {
+			continue
+		}
+		value := strings.ToLower(strings.Trim(attr.NamedChild(1).Content(contentBytes), `"'`))
+		return value == "" || value == "module" || strings.Contains(value, "javascript") ||
+			strings.Contains(value, "ecmascript") || strings.Contains(value, "babel") || strings.Contains(value, "jsx")
+	}
+	return true
+}
@@ -0,0 +1,327 @@
+// scanner/index.go
+package scanner
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// indexFormatVersion is bumped whenever the on-disk layout of a LiteralIndex changes.
+const indexFormatVersion = 1
+
+// IndexedFile is a scanned file recorded in a LiteralIndex, with the size and modification time
+// it had when it was parsed.
+type IndexedFile struct {
+	Path    string // Slash-separated path relative to the index root
+	Size    int64
+	ModTime time.Time
+}
+
+// IndexedLiteral is one candidate string literal recorded in a LiteralIndex.
+type IndexedLiteral struct {
+	File         int // Position of the file in LiteralIndex.Files
+	Line         int
+	EndLine      int
+	VariableName string
+	Content      string
+	Prompt       bool // Accepted by the heuristics of the scan that built the index
+}
+
+// LiteralIndex is an inverted index of the string literals of a scanned tree. A scan with
+// ScanOptions.Index set fills it in; Save and LoadLiteralIndex store it on disk so that Query can
+// answer searches over the same tree without parsing it again.
+type LiteralIndex struct {
+	Version  int
+	Target   string // Scan target as given by the user
+	Root     string // Local directory the file paths are relative to, empty for cloned repositories
+	BuiltAt  time.Time
+	Files    []IndexedFile
+	Literals []IndexedLiteral
+	// Tokens maps each lower-cased word of two or more letters or digits to the ascending
+	// positions in Literals of the literals containing it.
+	Tokens map[string][]int
+
+	mu sync.Mutex
+}
+
+// NewLiteralIndex returns an empty index. Callers set Target and Root once the scan target is
+// resolved.
+func NewLiteralIndex() *LiteralIndex {
+	return &LiteralIndex{Version: indexFormatVersion, BuiltAt: time.Now().UTC(), Tokens: make(map[string][]int)}
+}
+
+// add records the literals of one parsed file. It is called from the scan workers.
+func (ix *LiteralIndex) add(filePath, relPath string, literals []IndexedLiteral) {
+	file := IndexedFile{Path: relPath}
+	if info, err := os.Stat(filePath); err == nil {
+		file.Size, file.ModTime = info.Size(), info.ModTime().UTC()
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	fileID := len(ix.Files)
+	ix.Files = append(ix.Files, file)
+	for _, literal := range literals {
+		literal.File = fileID
+		id := len(ix.Literals)
+		ix.Literals = append(ix.Literals, literal)
+		for _, token := range indexTokens(literal.Content) {
+			ix.Tokens[token] = append(ix.Tokens[token], id)
+		}
+	}
+}
+
+// indexTokens returns the distinct lower-cased words of text that are at least two letters or
+// digits long.
+func indexTokens(text string) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isNotWordRune) {
+		if len(word) >= 2 && !seen[word] {
+			seen[word] = true
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
+
+// Save writes the index to path, replacing any previous index there.
+func (ix *LiteralIndex) Save(path string) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create index file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write index %s: %w", path, err)
+	}
+	if err := gob.NewEncoder(tmp).Encode(ix); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write index %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write index %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write index %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadLiteralIndex reads an index written by Save.
+func LoadLiteralIndex(path string) (*LiteralIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index %s: %w", path, err)
+	}
+	defer f.Close()
+	ix := &LiteralIndex{}
+	if err := gob.NewDecoder(f).Decode(ix); err != nil {
+		return nil, fmt.Errorf("failed to read index %s: %w", path, err)
+	}
+	if ix.Version != indexFormatVersion {
+		return nil, fmt.Errorf("index %s has format version %d, this build reads version %d; rebuild it with -index", path, ix.Version, indexFormatVersion)
+	}
+	return ix, nil
+}
+
+// IndexQuery selects literals from a LiteralIndex. Text and Pattern may be combined; a literal
+// must match both.
+type IndexQuery struct {
+	Text        string         // Case-insensitive substring, answered through the word index
+	Pattern     *regexp.Regexp // Regular expression matched against the literal content
+	PromptsOnly bool           // Only literals the indexing scan reported as prompts
+	Limit       int            // Maximum number of matches, 0 for no limit
+}
+
+// IndexMatch is a literal matching an IndexQuery.
+type IndexMatch struct {
+	Path         string `json:"path"`
+	Line         int    `json:"line"`
+	EndLine      int    `json:"end_line"`
+	VariableName string `json:"variable_name,omitempty"`
+	Content      string `json:"content"`
+	Prompt       bool   `json:"prompt"`
+	// MatchLine is the source line of the first match inside the literal and MatchText the
+	// content line it falls on.
+	MatchLine int    `json:"match_line"`
+	MatchText string `json:"match_text"`
+}
+
+// Query returns the literals matching q, ordered by path and line. Text queries only look at the
+// literals that contain all of its words, so they do not scan the whole index.
+func (ix *LiteralIndex) Query(q IndexQuery) []IndexMatch {
+	lowerText := strings.ToLower(q.Text)
+	candidates := ix.candidates(lowerText)
+	var matches []IndexMatch
+	for _, id := range candidates {
+		literal := ix.Literals[id]
+		if q.PromptsOnly && !literal.Prompt {
+			continue
+		}
+		offset := -1
+		if lowerText != "" {
+			if offset = strings.Index(strings.ToLower(literal.Content), lowerText); offset < 0 {
+				continue
+			}
+		}
+		if q.Pattern != nil {
+			loc := q.Pattern.FindStringIndex(literal.Content)
+			if loc == nil {
+				continue
+			}
+			if offset < 0 {
+				offset = loc[0]
+			}
+		}
+		// Lower-casing can change the byte length of some characters, so the offset is clamped.
+		matches = append(matches, ix.match(literal, min(max(offset, 0), len(literal.Content))))
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Path != matches[j].Path {
+			return matches[i].Path < matches[j].Path
+		}
+		return matches[i].Line < matches[j].Line
+	})
+	if q.Limit > 0 && len(matches) > q.Limit {
+		matches = matches[:q.Limit]
+	}
+	return matches
+}
+
+// candidates returns the ascending positions of the literals that contain every word of
+// lowerText, or of all literals when lowerText has no indexed words. A word at either end of the
+// text may be cut off inside a longer word of the literal ("ompt" in "prompt"), so it is looked up
+// among all tokens containing it rather than as a whole token.
+func (ix *LiteralIndex) candidates(lowerText string) []int {
+	words := strings.FieldsFunc(lowerText, isNotWordRune)
+	var sets [][]int
+	for i, word := range words {
+		if len(word) < 2 {
+			continue
+		}
+		partial := (i == 0 && !startsWithSeparator(lowerText)) || (i == len(words)-1 && !endsWithSeparator(lowerText))
+		if partial {
+			sets = append(sets, ix.containingTokens(word))
+		} else {
+			sets = append(sets, ix.Tokens[word])
+		}
+	}
+	if len(sets) == 0 {
+		all := make([]int, len(ix.Literals))
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+	result := sets[0]
+	for _, set := range sets[1:] {
+		result = intersectSorted(result, set)
+	}
+	return result
+}
+
+// containingTokens returns the ascending positions of the literals with a token containing word.
+func (ix *LiteralIndex) containingTokens(word string) []int {
+	var ids []int
+	for token, postings := range ix.Tokens {
+		if strings.Contains(token, word) {
+			ids = append(ids, postings...)
+		}
+	}
+	sort.Ints(ids)
+	return slices.Compact(ids)
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// startsWithSeparator and endsWithSeparator report whether text begins or ends outside a word.
+func startsWithSeparator(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	return isNotWordRune(r)
+}
+
+func endsWithSeparator(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return isNotWordRune(r)
+}
+
+// intersectSorted returns the values present in both ascending slices.
+func intersectSorted(a, b []int) []int {
+	var result []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// match builds the IndexMatch for literal, locating the content line at byte offset.
+func (ix *LiteralIndex) match(literal IndexedLiteral, offset int) IndexMatch {
+	start := strings.LastIndex(literal.Content[:offset], "\n") + 1
+	end := strings.Index(literal.Content[offset:], "\n")
+	if end < 0 {
+		end = len(literal.Content)
+	} else {
+		end += offset
+	}
+	return IndexMatch{
+		Path:         ix.Files[literal.File].Path,
+		Line:         literal.Line,
+		EndLine:      literal.EndLine,
+		VariableName: literal.VariableName,
+		Content:      literal.Content,
+		Prompt:       literal.Prompt,
+		MatchLine:    literal.Line + strings.Count(literal.Content[:offset], "\n"),
+		MatchText:    strings.TrimSpace(literal.Content[start:end]),
+	}
+}
+
+// StaleFiles returns the paths among paths whose size or modification time changed since the index
+// was built, or that no longer exist. It returns nil when the index has no local root.
+func (ix *LiteralIndex) StaleFiles(paths []string) []string {
+	if ix.Root == "" {
+		return nil
+	}
+	byPath := make(map[string]IndexedFile, len(ix.Files))
+	for _, f := range ix.Files {
+		byPath[f.Path] = f
+	}
+	var stale []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		f, ok := byPath[path]
+		if !ok || seen[path] {
+			continue
+		}
+		seen[path] = true
+		info, err := os.Stat(filepath.Join(ix.Root, filepath.FromSlash(path)))
+		if err != nil || info.Size() != f.Size || !info.ModTime().UTC().Equal(f.ModTime) {
+			stale = append(stale, path)
+		}
+	}
+	return stale
+}
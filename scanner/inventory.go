@@ -0,0 +1,154 @@
+// scanner/inventory.go
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Inventory is a catalog of the distinct prompts of a scan: findings with the same fingerprint
+// are one prompt, listed once with all of its locations.
+type Inventory struct {
+	Tool          ToolInfo         `json:"tool"`
+	Target        string           `json:"target"`
+	Commit        string           `json:"commit,omitempty"`
+	Ref           string           `json:"ref,omitempty"`
+	GeneratedAt   time.Time        `json:"generated_at"`
+	TotalFindings int              `json:"total_findings"`
+	Prompts       []InventoryEntry `json:"prompts"`
+}
+
+// InventoryEntry is one distinct prompt of an Inventory.
+type InventoryEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Content     string `json:"content"`  // Text of the first location; the others differ at most in whitespace
+	Severity    string `json:"severity"` // Highest severity among the locations
+	Tokens      int    `json:"tokens,omitempty"`
+	// Slots are the template slot names of the prompt, e.g. ["context", "question"].
+	Slots     []string            `json:"slots,omitempty"`
+	Locations []InventoryLocation `json:"locations"`
+}
+
+// InventoryLocation is one place an inventoried prompt was found.
+type InventoryLocation struct {
+	Filepath  string `json:"filepath"`
+	Line      int    `json:"line"`
+	ID        string `json:"id,omitempty"`
+	Symbol    string `json:"symbol,omitempty"`
+	Variable  string `json:"variable,omitempty"`
+	Permalink string `json:"permalink,omitempty"`
+}
+
+// BuildInventory groups the accepted findings of a scan by fingerprint. Prompts found in the most
+// places come first, then the most severe; ties keep the order of their first finding.
+func BuildInventory(meta ReportMeta, toolVersion string, prompts []FoundPrompt) Inventory {
+	if toolVersion == "" {
+		toolVersion = "dev"
+	}
+	inventory := Inventory{
+		Tool:    ToolInfo{Name: "prompt-scanner", Version: toolVersion, HeuristicsVersion: HeuristicsVersion},
+		Target:  meta.Target,
+		Commit:  meta.Commit,
+		Ref:     meta.Ref,
+		Prompts: []InventoryEntry{},
+	}
+	if !meta.Reproducible {
+		inventory.GeneratedAt = time.Now().UTC()
+	}
+	rank := map[string]int{SeverityHigh: 3, SeverityMedium: 2, SeverityLow: 1}
+	entries := make(map[string]int)
+	for _, p := range prompts {
+		if p.Rejected {
+			continue
+		}
+		inventory.TotalFindings++
+		fingerprint := p.Fingerprint
+		if fingerprint == "" {
+			fingerprint = PromptFingerprint(p.Content)
+		}
+		i, ok := entries[fingerprint]
+		if !ok {
+			i = len(inventory.Prompts)
+			entries[fingerprint] = i
+			inventory.Prompts = append(inventory.Prompts, InventoryEntry{
+				Fingerprint: fingerprint,
+				Content:     p.Content,
+				Severity:    p.Severity,
+				Tokens:      p.Tokens,
+				Slots:       p.Slots,
+			})
+		}
+		entry := &inventory.Prompts[i]
+		if rank[p.Severity] > rank[entry.Severity] {
+			entry.Severity = p.Severity
+		}
+		entry.Locations = append(entry.Locations, InventoryLocation{
+			Filepath:  meta.DisplayPath(p.Filepath),
+			Line:      p.Line,
+			ID:        p.ID,
+			Symbol:    p.Symbol,
+			Variable:  p.VariableName,
+			Permalink: meta.Permalink(p),
+		})
+	}
+	sort.SliceStable(inventory.Prompts, func(i, j int) bool {
+		a, b := inventory.Prompts[i], inventory.Prompts[j]
+		if len(a.Locations) != len(b.Locations) {
+			return len(a.Locations) > len(b.Locations)
+		}
+		return rank[a.Severity] > rank[b.Severity]
+	})
+	return inventory
+}
+
+// WriteText writes the inventory as plain text: one block per prompt, with its fingerprint,
+// number of locations, severity and first line, followed by its locations.
+func (inv Inventory) WriteText(w io.Writer) error {
+	for _, entry := range inv.Prompts {
+		if _, err := fmt.Fprintf(w, "%s  %d× %s  %s\n", entry.Fingerprint, len(entry.Locations), entry.Severity, previewLine(entry.Content)); err != nil {
+			return err
+		}
+		for _, loc := range entry.Locations {
+			line := fmt.Sprintf("    %s:%d", loc.Filepath, loc.Line)
+			if loc.Variable != "" {
+				line += " " + loc.Variable
+			} else if loc.Symbol != "" {
+				line += " " + loc.Symbol
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteMarkdown writes the inventory as a Markdown document with a section per prompt.
+func (inv Inventory) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Prompt inventory\n\nTarget: `%s`", inv.Target)
+	if inv.Commit != "" {
+		fmt.Fprintf(&b, " at `%s`", inv.Commit)
+	}
+	fmt.Fprintf(&b, "\n\n%d distinct prompts in %d findings.\n", len(inv.Prompts), inv.TotalFindings)
+	for _, entry := range inv.Prompts {
+		fmt.Fprintf(&b, "\n## `%s`\n\n%s severity, found in %d places:\n\n", entry.Fingerprint, entry.Severity, len(entry.Locations))
+		for _, loc := range entry.Locations {
+			location := fmt.Sprintf("`%s:%d`", loc.Filepath, loc.Line)
+			if loc.Permalink != "" {
+				location = fmt.Sprintf("[%s](%s)", location, loc.Permalink)
+			}
+			if loc.Variable != "" {
+				location += " `" + loc.Variable + "`"
+			}
+			fmt.Fprintf(&b, "- %s\n", location)
+		}
+		fence := markdownFence(entry.Content)
+		fmt.Fprintf(&b, "\n%s\n%s\n%s\n", fence, strings.TrimRight(entry.Content, "\n"), fence)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,157 @@
+// scanner/keywordstats.go
+package scanner
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// KeywordUsage is how many reported findings one keyword or placeholder pattern contributed to.
+type KeywordUsage struct {
+	Keyword  string `json:"keyword"`
+	Findings int    `json:"findings"`
+	// Configured is false for keywords that only come from language or path overrides.
+	Configured bool `json:"configured"`
+}
+
+// KeywordReport lists the configured keywords and placeholder patterns with the number of
+// findings each contributed to, most effective first; those that never fired come last in their
+// configured order.
+type KeywordReport struct {
+	Mode                string         `json:"mode"`
+	Findings            int            `json:"findings"`
+	VariableKeywords    []KeywordUsage `json:"variable_keywords"`
+	ContentKeywords     []KeywordUsage `json:"content_keywords"`
+	PlaceholderPatterns []KeywordUsage `json:"placeholder_patterns"`
+}
+
+// UnusedKeywords returns the configured entries of usages that contributed to no finding.
+func UnusedKeywords(usages []KeywordUsage) []string {
+	var unused []string
+	for _, usage := range usages {
+		if usage.Configured && usage.Findings == 0 {
+			unused = append(unused, usage.Keyword)
+		}
+	}
+	return unused
+}
+
+// KeywordStats attributes reported findings to the keywords and placeholder pattern that matched
+// them. Only the signals recorded on a finding count: in the keyword modes that is the content
+// keyword that accepted it, in greedy mode every scored keyword and the first matching
+// placeholder pattern. Add is not safe for concurrent use.
+type KeywordStats struct {
+	mode         string
+	findings     int
+	variable     *keywordCounts
+	content      *keywordCounts
+	placeholders *keywordCounts
+}
+
+// NewKeywordStats returns empty KeywordStats for the scanner's configured keywords.
+func (s *Scanner) NewKeywordStats() *KeywordStats {
+	return &KeywordStats{
+		mode:         s.Options.preset().Name,
+		variable:     newKeywordCounts(s.Options.VariableKeywords, `(?i)^(?:%s)$`),
+		content:      newKeywordCounts(s.Options.ContentKeywords, `(?i)^(?:%s)$`),
+		placeholders: newKeywordCounts(s.Options.PlaceholderPatterns, `^(?:%s)$`),
+	}
+}
+
+// Add counts the keywords behind a reported finding. Rejected candidates are ignored.
+func (k *KeywordStats) Add(p FoundPrompt) {
+	if p.Rejected {
+		return
+	}
+	k.findings++
+	k.variable.add(p.MatchedVariableName)
+	// Findings accepted by the long-string and constants-file rules record a marker, not a keyword.
+	if p.MatchedContentWord != "long_string" && p.MatchedContentWord != "constants_file" {
+		k.content.add(p.MatchedContentWord)
+	}
+	k.placeholders.add(p.MatchedPlaceholder)
+}
+
+// Report returns the counts gathered so far.
+func (k *KeywordStats) Report() KeywordReport {
+	return KeywordReport{
+		Mode:                k.mode,
+		Findings:            k.findings,
+		VariableKeywords:    k.variable.usages(),
+		ContentKeywords:     k.content.usages(),
+		PlaceholderPatterns: k.placeholders.usages(),
+	}
+}
+
+// keywordCounts counts findings per configured keyword. Recorded matches are the matched text, so
+// they are mapped back to the keyword (or pattern) that matches them as a whole; matches of no
+// configured keyword come from overrides and are counted under their lowercased text.
+type keywordCounts struct {
+	keywords []string
+	matchers []*regexp.Regexp // Per keyword; nil if the keyword does not compile
+	counts   map[string]int
+	resolved map[string]string // Matched text to keyword
+}
+
+func newKeywordCounts(keywords []string, wholeMatch string) *keywordCounts {
+	kc := &keywordCounts{counts: make(map[string]int), resolved: make(map[string]string)}
+	for _, keyword := range keywords {
+		if keyword == "" || slices.Contains(kc.keywords, keyword) {
+			continue
+		}
+		kc.keywords = append(kc.keywords, keyword)
+		re, err := regexp.Compile(strings.Replace(wholeMatch, "%s", keyword, 1))
+		if err != nil {
+			re = nil
+		}
+		kc.matchers = append(kc.matchers, re)
+	}
+	return kc
+}
+
+func (kc *keywordCounts) add(match string) {
+	if match == "" {
+		return
+	}
+	keyword, ok := kc.resolved[match]
+	if !ok {
+		keyword = kc.resolve(match)
+		kc.resolved[match] = keyword
+	}
+	kc.counts[keyword]++
+}
+
+func (kc *keywordCounts) resolve(match string) string {
+	for _, keyword := range kc.keywords {
+		if strings.EqualFold(keyword, match) {
+			return keyword
+		}
+	}
+	for i, re := range kc.matchers {
+		if re != nil && re.MatchString(match) {
+			return kc.keywords[i]
+		}
+	}
+	return strings.ToLower(match)
+}
+
+func (kc *keywordCounts) usages() []KeywordUsage {
+	usages := make([]KeywordUsage, 0, len(kc.counts))
+	configured := make(map[string]bool, len(kc.keywords))
+	for _, keyword := range kc.keywords {
+		configured[keyword] = true
+		usages = append(usages, KeywordUsage{Keyword: keyword, Findings: kc.counts[keyword], Configured: true})
+	}
+	var extra []KeywordUsage
+	for keyword, n := range kc.counts {
+		if !configured[keyword] {
+			extra = append(extra, KeywordUsage{Keyword: keyword, Findings: n})
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].Keyword < extra[j].Keyword })
+	usages = append(usages, extra...)
+	sort.SliceStable(usages, func(i, j int) bool { return usages[i].Findings > usages[j].Findings })
+	return usages
+}
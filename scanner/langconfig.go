@@ -0,0 +1,165 @@
+// scanner/langconfig.go
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// LanguageOverride replaces scan options for one language or file extension. Unset fields keep
+// the scan-wide value.
+type LanguageOverride struct {
+	MinLength           *int     `yaml:"min_length"`
+	VariableKeywords    []string `yaml:"var_keywords"`
+	ContentKeywords     []string `yaml:"content_keywords"`
+	PlaceholderPatterns []string `yaml:"placeholder_patterns"`
+}
+
+// PathOverride replaces scan options for the files matching any of its Paths, or skips them. A
+// matching path override takes precedence over LanguageOverrides; the first match wins.
+type PathOverride struct {
+	// Paths are globs relative to the scanned root, with forward slashes and "**" matching any
+	// number of directories, e.g. "tests/**" or "**/*_test.py".
+	Paths []string `yaml:"paths"`
+	// Skip excludes the matching files from the scan.
+	Skip             bool `yaml:"skip"`
+	LanguageOverride `yaml:",inline"`
+}
+
+// matches reports whether relPath, relative to the scanned root with forward slashes, matches one
+// of the override's Paths.
+func (o PathOverride) matches(relPath string) bool {
+	for _, pattern := range o.Paths {
+		if matched, _ := doublestar.Match(pattern, relPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// languageConfigFile is the layout of a -lang-config file:
+//
+//	languages:
+//	  go:                 # a language name (go, python, javascript, ...)
+//	    min_length: 60
+//	  ".prompt":          # or a file extension
+//	    min_length: 10
+//	    content_keywords: ["you are", "your task"]
+type languageConfigFile struct {
+	Languages map[string]LanguageOverride `yaml:"languages"`
+}
+
+// LoadLanguageOverrides reads per-language option overrides from a YAML file.
+func LoadLanguageOverrides(configPath string) (map[string]LanguageOverride, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read language config %s: %w", configPath, err)
+	}
+	var config languageConfigFile
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse language config %s: %w", configPath, err)
+	}
+	overrides := make(map[string]LanguageOverride, len(config.Languages))
+	for key, override := range config.Languages {
+		overrides[strings.ToLower(key)] = override
+	}
+	return overrides, nil
+}
+
+// compileLanguageOptions builds the effective options for every language override.
+func (s *Scanner) compileLanguageOptions(overrides map[string]LanguageOverride) error {
+	s.languageOptions = make(map[string]*ScanOptions, len(overrides))
+	for key, override := range overrides {
+		opts, err := s.Options.withOverride(override)
+		if err != nil {
+			return fmt.Errorf("language override '%s': %w", key, err)
+		}
+		s.languageOptions[key] = opts
+	}
+	return nil
+}
+
+// compilePathOptions builds the effective options for every path override; skipping overrides
+// get nil.
+func (s *Scanner) compilePathOptions(overrides []PathOverride) error {
+	s.pathOptions = make([]*ScanOptions, len(overrides))
+	for i, override := range overrides {
+		for _, pattern := range override.Paths {
+			if !doublestar.ValidatePattern(pattern) {
+				return fmt.Errorf("path override %d: invalid glob '%s'", i+1, pattern)
+			}
+		}
+		if override.Skip {
+			continue
+		}
+		opts, err := s.Options.withOverride(override.LanguageOverride)
+		if err != nil {
+			return fmt.Errorf("path override %d (%s): %w", i+1, strings.Join(override.Paths, ", "), err)
+		}
+		s.pathOptions[i] = opts
+	}
+	return nil
+}
+
+// withOverride returns a copy of so with the fields set in override replaced and its matchers
+// compiled.
+func (so ScanOptions) withOverride(override LanguageOverride) (*ScanOptions, error) {
+	if override.MinLength != nil {
+		so.MinLength = *override.MinLength
+	}
+	if override.VariableKeywords != nil {
+		so.VariableKeywords = override.VariableKeywords
+	}
+	if override.ContentKeywords != nil {
+		so.ContentKeywords = override.ContentKeywords
+	}
+	if override.PlaceholderPatterns != nil {
+		so.PlaceholderPatterns = override.PlaceholderPatterns
+	}
+	if err := so.compileMatchers(); err != nil {
+		return nil, err
+	}
+	return &so, nil
+}
+
+// pathOverride returns the position of the first PathOverride matching filePath, or -1.
+func (s *Scanner) pathOverride(filePath string) int {
+	if len(s.Options.PathOverrides) == 0 {
+		return -1
+	}
+	relPath := s.relativePath(filePath)
+	for i, override := range s.Options.PathOverrides {
+		if override.matches(relPath) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isPathSkipped reports whether the first path override matching filePath skips it.
+func (s *Scanner) isPathSkipped(filePath string) bool {
+	i := s.pathOverride(filePath)
+	return i >= 0 && s.Options.PathOverrides[i].Skip
+}
+
+// forFile returns the scanner to parse filePath with: s itself, or a scanner carrying the
+// options of the first matching path override or else of the matching language override (an
+// extension key wins over a language key).
+func (s *Scanner) forFile(filePath, lang string) *Scanner {
+	if i := s.pathOverride(filePath); i >= 0 && s.pathOptions[i] != nil {
+		return &Scanner{Options: *s.pathOptions[i], rootDir: s.rootDir}
+	}
+	opts, ok := s.languageOptions[strings.ToLower(filepath.Ext(filePath))]
+	if !ok {
+		opts, ok = s.languageOptions[lang]
+	}
+	if !ok {
+		return s
+	}
+	return &Scanner{Options: *opts, rootDir: s.rootDir}
+}
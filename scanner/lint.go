@@ -0,0 +1,226 @@
+// scanner/lint.go
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Lint is a problem found in a prompt, reported alongside the finding.
+type Lint struct {
+	Rule    string `json:"rule"`
+	Level   string `json:"level"` // LintLevelWarning or LintLevelAdvisory
+	Message string `json:"message"`
+}
+
+// Lint levels. Warnings are likely bugs; advisory lints are prompt-quality suggestions.
+const (
+	LintLevelWarning  = "warning"
+	LintLevelAdvisory = "advisory"
+)
+
+// Lint rules.
+const (
+	LintUnusedInputVariable   = "unused-input-variable"    // Declared in input_variables but absent from the template
+	LintUndeclaredPlaceholder = "undeclared-placeholder"   // Used in the template but missing from input_variables
+	LintDuplicateVariable     = "duplicate-input-variable" // Declared more than once in input_variables
+	LintBrokenPlaceholder     = "broken-placeholder"       // Unbalanced braces such as "{user_input" or "{{name}"
+
+	// Prompt-quality lints, enabled with ScanOptions.QualityLints.
+	LintLongSentence      = "long-sentence"              // A sentence too long to follow reliably
+	LintContradiction     = "contradictory-instructions" // Conflicting instructions such as "be concise" and "be detailed"
+	LintControlCharacters = "control-characters"         // Invisible control, zero-width or bidirectional characters
+)
+
+// maxSentenceWords is the sentence length above which LintLongSentence is reported.
+const maxSentenceWords = 60
+
+var (
+	inputVariablesPattern = regexp.MustCompile(`\b(?:input_variables|inputVariables)\s*[=:]\s*\[`)
+	quotedNamePattern     = regexp.MustCompile(`["']([^"']+)["']`)
+
+	halfClosedMustache = regexp.MustCompile(`(\{\{\s*[A-Za-z_][\w.]*\s*\})(?:[^}]|$)`)
+	halfOpenedMustache = regexp.MustCompile(`(?:^|[^{])(\{\s*[A-Za-z_][\w.]*\s*\}\})`)
+	placeholderOpening = regexp.MustCompile(`\{\{?\s*[A-Za-z_][\w.]*`)
+)
+
+// lintPrompt checks a finding's placeholders against the input_variables declared for it in
+// source (LangChain's PromptTemplate(input_variables=[...], template=...)) and for broken
+// placeholder syntax.
+func lintPrompt(p FoundPrompt, source []byte) []Lint {
+	var lints []Lint
+	for _, fragment := range brokenPlaceholders(p.Content) {
+		lints = append(lints, Lint{Rule: LintBrokenPlaceholder, Level: LintLevelWarning, Message: fmt.Sprintf("placeholder %q is not closed properly", fragment)})
+	}
+
+	declared, found := declaredInputVariables(p, source)
+	if !found {
+		return lints
+	}
+	used := make(map[string]bool)
+	for _, slot := range p.Slots {
+		used[rootName(slot)] = true
+	}
+	seen := make(map[string]bool)
+	for _, name := range declared {
+		if seen[name] {
+			lints = append(lints, Lint{Rule: LintDuplicateVariable, Level: LintLevelWarning, Message: fmt.Sprintf("input variable %q is declared more than once", name)})
+			continue
+		}
+		seen[name] = true
+		if !used[name] {
+			lints = append(lints, Lint{Rule: LintUnusedInputVariable, Level: LintLevelWarning, Message: fmt.Sprintf("input variable %q is declared but never used in the template", name)})
+		}
+	}
+	for _, slot := range p.Slots {
+		if name := rootName(slot); !seen[name] {
+			seen[name] = true
+			lints = append(lints, Lint{Rule: LintUndeclaredPlaceholder, Level: LintLevelWarning, Message: fmt.Sprintf("placeholder {%s} is not listed in input_variables", name)})
+		}
+	}
+	return lints
+}
+
+// declaredInputVariables finds an input_variables list in a call that contains the finding, or
+// that passes the finding's variable as its template.
+func declaredInputVariables(p FoundPrompt, source []byte) ([]string, bool) {
+	src := string(source)
+	varName := p.VariableName[strings.LastIndexAny(p.VariableName, ".>:")+1:]
+	for _, loc := range inputVariablesPattern.FindAllStringIndex(src, -1) {
+		callStart := enclosingCallStart(src, loc[0])
+		if callStart < 0 {
+			continue
+		}
+		args := balancedArgs(src[callStart+1:])
+		startLine := strings.Count(src[:callStart], "\n") + 1
+		endLine := startLine + strings.Count(args, "\n")
+
+		linked := p.Line >= startLine && p.Line <= endLine
+		if !linked && isIdentifier(varName) {
+			linked = regexp.MustCompile(`\btemplate\s*[=:]\s*` + regexp.QuoteMeta(varName) + `\b`).MatchString(args)
+		}
+		if !linked {
+			continue
+		}
+		list := balancedArgs(src[loc[1]:])
+		var names []string
+		for _, m := range quotedNamePattern.FindAllStringSubmatch(list, -1) {
+			names = append(names, m[1])
+		}
+		return names, true
+	}
+	return nil, false
+}
+
+// enclosingCallStart returns the index of the "(" opening the call that contains offset, or -1.
+func enclosingCallStart(src string, offset int) int {
+	depth := 0
+	for i := offset - 1; i >= 0; i-- {
+		switch src[i] {
+		case ')', ']', '}':
+			depth++
+		case '[', '{':
+			depth--
+		case '(':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// brokenPlaceholders returns placeholder fragments with unbalanced braces: an opening brace
+// followed by a name that is never closed on the same line, or mismatched {{name} / {name}}.
+func brokenPlaceholders(content string) []string {
+	var broken []string
+	for _, line := range strings.Split(content, "\n") {
+		for _, loc := range placeholderOpening.FindAllStringIndex(line, -1) {
+			rest := line[loc[1]:]
+			closing := strings.IndexByte(rest, '}')
+			nextOpening := strings.IndexByte(rest, '{')
+			if closing < 0 || (nextOpening >= 0 && nextOpening < closing) {
+				broken = append(broken, strings.TrimSpace(line[loc[0]:loc[1]]))
+			}
+		}
+		for _, re := range []*regexp.Regexp{halfClosedMustache, halfOpenedMustache} {
+			for _, m := range re.FindAllStringSubmatch(line, -1) {
+				broken = append(broken, m[1])
+			}
+		}
+	}
+	return broken
+}
+
+// contradictoryInstructions pairs instructions that should not appear in the same prompt.
+var contradictoryInstructions = []struct {
+	a, b *regexp.Regexp
+}{
+	{regexp.MustCompile(`(?i)\b(be (concise|brief|succinct)|keep (it|your (answer|response)) (short|brief)|short answers?)\b`),
+		regexp.MustCompile(`(?i)\b(be (detailed|thorough|comprehensive|exhaustive|verbose)|in (great )?detail|long[- ]form)\b`)},
+	{regexp.MustCompile(`(?i)\b(be (formal|professional))\b`),
+		regexp.MustCompile(`(?i)\b(be (casual|informal|playful)|use slang)\b`)},
+	{regexp.MustCompile(`(?i)\b(use|include) (emojis?|emoticons?)\b`),
+		regexp.MustCompile(`(?i)\b(do not|don't|never) (use|include) (any )?(emojis?|emoticons?)\b`)},
+	{regexp.MustCompile(`(?i)\b(respond|reply|answer) (only )?in json\b`),
+		regexp.MustCompile(`(?i)\b(respond|reply|answer) (only )?in (plain text|prose|markdown)\b`)},
+}
+
+var sentenceEnd = regexp.MustCompile(`[.!?]+(\s|$)|\n\s*\n`)
+
+// qualityLints returns advisory prompt-quality lints: very long sentences, contradictory
+// instructions and invisible control characters.
+func qualityLints(content string) []Lint {
+	var lints []Lint
+	for _, sentence := range sentenceEnd.Split(content, -1) {
+		if words := strings.Fields(sentence); len(words) > maxSentenceWords {
+			lints = append(lints, Lint{Rule: LintLongSentence, Level: LintLevelAdvisory,
+				Message: fmt.Sprintf("sentence of %d words starting %q; consider splitting it", len(words), strings.Join(words[:6], " ")+"...")})
+		}
+	}
+	for _, pair := range contradictoryInstructions {
+		if a, b := pair.a.FindString(content), pair.b.FindString(content); a != "" && b != "" {
+			lints = append(lints, Lint{Rule: LintContradiction, Level: LintLevelAdvisory,
+				Message: fmt.Sprintf("%q conflicts with %q", a, b)})
+		}
+	}
+	var invisible []string
+	for _, r := range content {
+		if isInvisibleControl(r) {
+			invisible = append(invisible, fmt.Sprintf("U+%04X", r))
+		}
+	}
+	if len(invisible) > 0 {
+		lints = append(lints, Lint{Rule: LintControlCharacters, Level: LintLevelAdvisory,
+			Message: fmt.Sprintf("contains %d invisible control characters (%s)", len(invisible), strings.Join(uniqueStrings(invisible), ", "))})
+	}
+	return lints
+}
+
+// isInvisibleControl reports control characters other than common whitespace, plus zero-width
+// and bidirectional formatting characters that can hide text from reviewers.
+func isInvisibleControl(r rune) bool {
+	switch {
+	case r == '\n' || r == '\t' || r == '\r':
+		return false
+	case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+		return true
+	case r >= 0x200b && r <= 0x200f, r >= 0x202a && r <= 0x202e, r >= 0x2066 && r <= 0x2069, r == 0xfeff:
+		return true
+	}
+	return false
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,35 @@
+// scanner/minified.go
+package scanner
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// minifiedLineLength is the length above which a line is taken for minified code.
+const minifiedLineLength = 1000
+
+// sourceMapComment matches the comment bundlers and compilers end their output with.
+var sourceMapComment = regexp.MustCompile(`(?m)^\s*//[#@] sourceMappingURL=\S+\s*$`)
+
+// isMinified reports whether a JavaScript or TypeScript file looks minified or bundled: it is
+// named like "app.min.js", it ends with a sourceMappingURL comment, or most of its bytes are on
+// lines longer than minifiedLineLength.
+func isMinified(filePath string, content []byte) bool {
+	name := strings.ToLower(filepath.Base(filePath))
+	if strings.Contains(name, ".min.") || strings.Contains(name, "-min.") {
+		return true
+	}
+	if sourceMapComment.Match(content[max(0, len(content)-1024):]) {
+		return true
+	}
+	longBytes := 0
+	for line := range bytes.Lines(content) {
+		if len(line) > minifiedLineLength {
+			longBytes += len(line)
+		}
+	}
+	return longBytes > len(content)/2
+}
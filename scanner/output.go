@@ -0,0 +1,89 @@
+// scanner/output.go
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Output is the destination of a report: standard output, or a file that only appears, complete,
+// once the report has been written. File output goes to a temporary file next to the target, which
+// Commit renames over it, so readers never see a partial report and a failed run leaves the
+// previous file in place. The temporary file is created on the first Write, so an Output that is
+// abandoned before the report starts leaves nothing behind.
+type Output struct {
+	path string   // Target file, empty for standard output
+	file *os.File // Standard output, or the temporary file once created
+}
+
+// CreateOutput returns the Output for path; "" and "-" mean standard output. The directory of path
+// must exist.
+func CreateOutput(path string) (*Output, error) {
+	if path == "" || path == "-" {
+		return &Output{file: os.Stdout}, nil
+	}
+	if info, err := os.Stat(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("invalid output path %s: %w", path, err)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("invalid output path %s: %s is not a directory", path, filepath.Dir(path))
+	}
+	return &Output{path: path}, nil
+}
+
+// Write implements io.Writer.
+func (o *Output) Write(p []byte) (int, error) {
+	if o.file == nil {
+		if err := o.create(); err != nil {
+			return 0, err
+		}
+	}
+	return o.file.Write(p)
+}
+
+func (o *Output) create() error {
+	tmp, err := os.CreateTemp(filepath.Dir(o.path), "."+filepath.Base(o.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", o.path, err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to create %s: %w", o.path, err)
+	}
+	o.file = tmp
+	return nil
+}
+
+// Commit finishes the report, moving a file output into place; a report that wrote nothing
+// leaves an empty file. It does nothing for standard output.
+func (o *Output) Commit() error {
+	if o.path == "" {
+		return nil
+	}
+	if o.file == nil {
+		if err := o.create(); err != nil {
+			return err
+		}
+	}
+	if err := o.file.Close(); err != nil {
+		os.Remove(o.file.Name())
+		return fmt.Errorf("failed to write %s: %w", o.path, err)
+	}
+	if err := os.Rename(o.file.Name(), o.path); err != nil {
+		os.Remove(o.file.Name())
+		return fmt.Errorf("failed to write %s: %w", o.path, err)
+	}
+	return nil
+}
+
+// Abort discards a file output, leaving any existing file at the target untouched. It does
+// nothing for standard output.
+func (o *Output) Abort() {
+	if o.path == "" || o.file == nil {
+		return
+	}
+	o.file.Close()
+	os.Remove(o.file.Name())
+	o.file = nil
+}
@@ -0,0 +1,76 @@
+// scanner/pathsignal.go
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// promptDirs are directory names under which strings are more likely to be prompts.
+var promptDirs = map[string]bool{
+	"prompts":   true,
+	"prompt":    true,
+	"templates": true,
+	"agents":    true,
+}
+
+// demotedDirs are directory names whose strings are mostly translations or test data.
+var demotedDirs = map[string]bool{
+	"locales":      true,
+	"locale":       true,
+	"i18n":         true,
+	"l10n":         true,
+	"fixtures":     true,
+	"__fixtures__": true,
+}
+
+// relativePath returns filePath relative to the scanned root, with forward slashes. A scanned
+// single file is reduced to its base name.
+func (s *Scanner) relativePath(filePath string) string {
+	return relativeTo(s.rootDir, filePath)
+}
+
+// relativeTo returns filePath relative to root with forward slashes. Paths outside root are
+// returned as they are, and a root that is the file itself yields its base name.
+func relativeTo(root, filePath string) string {
+	rel := filePath
+	if root != "" {
+		if r, err := filepath.Rel(root, filePath); err == nil && r == "." {
+			rel = filepath.Base(filePath)
+		} else if err == nil && !strings.HasPrefix(r, "..") {
+			rel = r
+		}
+	}
+	return filepath.ToSlash(rel)
+}
+
+// pathContext splits filePath, relative to the scanned root, into its base name and directory names.
+func (s *Scanner) pathContext(filePath string) (fileName string, dirNames []string) {
+	parts := strings.Split(s.relativePath(filePath), "/")
+	for _, dir := range parts[:len(parts)-1] {
+		if dir != "" && dir != "." {
+			dirNames = append(dirNames, dir)
+		}
+	}
+	return parts[len(parts)-1], dirNames
+}
+
+// pathSignal scores where a candidate lives: +2 under a prompt-indicative directory or in a file
+// named like a prompt, -2 under a locale or fixture directory. The returned label describes the
+// signal for reports.
+func pathSignal(ctx PromptContext) (int, string) {
+	for _, dir := range ctx.DirNames {
+		if demotedDirs[strings.ToLower(dir)] {
+			return -2, dir + "/"
+		}
+	}
+	for _, dir := range ctx.DirNames {
+		if promptDirs[strings.ToLower(dir)] {
+			return 2, dir + "/"
+		}
+	}
+	if strings.Contains(strings.ToLower(ctx.FileName), "prompt") {
+		return 2, ctx.FileName
+	}
+	return 0, ""
+}
@@ -0,0 +1,146 @@
+// scanner/policy.go
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is a set of token-budget rules loaded from a YAML file:
+//
+//	rules:
+//	  - name: small-model-system-prompts
+//	    model: "gpt-4o-mini*"   # glob on the inferred model; omit to match any
+//	    role: system            # system or any (default)
+//	    max_tokens: 2000
+//	    severity: high          # high, medium (default) or low
+type Policy struct {
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+// PolicyRule limits the size of prompts, optionally only those for a given model or role.
+type PolicyRule struct {
+	Name      string `yaml:"name"`
+	Model     string `yaml:"model"`
+	Provider  string `yaml:"provider"`
+	Role      string `yaml:"role"`
+	MaxTokens int    `yaml:"max_tokens"`
+	Severity  string `yaml:"severity"`
+}
+
+// PolicyViolation is a policy rule broken by a finding.
+type PolicyViolation struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// LoadPolicy reads and validates a policy file.
+func LoadPolicy(policyPath string) (*Policy, error) {
+	data, err := os.ReadFile(policyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy %s: %w", policyPath, err)
+	}
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", policyPath, err)
+	}
+	for i := range policy.Rules {
+		rule := &policy.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if rule.MaxTokens <= 0 {
+			return nil, fmt.Errorf("policy rule '%s' needs a positive max_tokens", rule.Name)
+		}
+		if rule.Severity == "" {
+			rule.Severity = SeverityMedium
+		}
+		if _, known := DefaultSeverityWeights[rule.Severity]; !known {
+			return nil, fmt.Errorf("policy rule '%s' has unknown severity '%s'", rule.Name, rule.Severity)
+		}
+		if rule.Role != "" && rule.Role != "system" && rule.Role != "any" {
+			return nil, fmt.Errorf("policy rule '%s' has unknown role '%s' (use system or any)", rule.Name, rule.Role)
+		}
+		if _, err := path.Match(rule.Model, ""); err != nil {
+			return nil, fmt.Errorf("policy rule '%s' has an invalid model pattern: %w", rule.Name, err)
+		}
+	}
+	return &policy, nil
+}
+
+// Evaluate returns the rules a finding violates.
+func (p *Policy) Evaluate(fp FoundPrompt) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, rule := range p.Rules {
+		if rule.Model != "" {
+			if matched, _ := path.Match(strings.ToLower(rule.Model), strings.ToLower(fp.Model)); !matched {
+				continue
+			}
+		}
+		if rule.Provider != "" && !strings.EqualFold(rule.Provider, fp.Provider) {
+			continue
+		}
+		if rule.Role == "system" && !isSystemPrompt(fp) {
+			continue
+		}
+		if fp.Tokens > rule.MaxTokens {
+			target := "prompt"
+			if fp.Model != "" {
+				target += " for " + fp.Model
+			}
+			violations = append(violations, PolicyViolation{
+				Rule:     rule.Name,
+				Severity: rule.Severity,
+				Message:  fmt.Sprintf("%s is ~%d tokens, over the %d-token budget", target, fp.Tokens, rule.MaxTokens),
+			})
+		}
+	}
+	return violations
+}
+
+// isSystemPrompt reports whether a finding looks like a system prompt: held in a "system"
+// variable or opening with a role statement.
+func isSystemPrompt(fp FoundPrompt) bool {
+	text := strings.ToLower(strings.TrimSpace(fp.Content))
+	return strings.Contains(strings.ToLower(fp.VariableName), "system") ||
+		strings.HasPrefix(text, "you are") || strings.HasPrefix(text, "act as")
+}
+
+var (
+	modelLiteralPattern = regexp.MustCompile(`["'\x60]((?:gpt-[\w.-]+|o[134](?:-mini|-preview|-pro)?|chatgpt-[\w.-]+|claude-[\w.-]+|gemini-[\w.-]+|(?:meta-)?llama[\w./-]*|mistral[\w.-]*|mixtral[\w.-]*|command-r[\w+-]*|deepseek-[\w.-]+))["'\x60]`)
+	modelProviders      = []struct{ prefix, provider string }{
+		{"gpt-", "openai"}, {"chatgpt-", "openai"}, {"o1", "openai"}, {"o3", "openai"}, {"o4", "openai"},
+		{"claude-", "anthropic"}, {"gemini-", "google"}, {"llama", "meta"}, {"meta-llama", "meta"},
+		{"mistral", "mistral"}, {"mixtral", "mistral"}, {"command-r", "cohere"}, {"deepseek-", "deepseek"},
+	}
+)
+
+// inferModel finds the model name literal in source closest to line, and its provider.
+func inferModel(source []byte, line int) (model, provider string) {
+	src := string(source)
+	bestDistance := -1
+	for _, m := range modelLiteralPattern.FindAllStringSubmatchIndex(src, -1) {
+		modelLine := strings.Count(src[:m[2]], "\n") + 1
+		distance := modelLine - line
+		if distance < 0 {
+			distance = -distance
+		}
+		if bestDistance < 0 || distance < bestDistance {
+			bestDistance = distance
+			model = src[m[2]:m[3]]
+		}
+	}
+	lower := strings.ToLower(model)
+	for _, mp := range modelProviders {
+		if strings.HasPrefix(lower, mp.prefix) {
+			return model, mp.provider
+		}
+	}
+	return model, ""
+}
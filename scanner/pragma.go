@@ -0,0 +1,46 @@
+// scanner/pragma.go
+package scanner
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Pragmas are comments that override the heuristics for one literal. They apply to a literal
+// starting on the same line (a trailing comment) or on the line after a comment-only line:
+//
+//	# prompt-scanner:prompt
+//	GREETING = "Hi there, how can I help?"
+//	label = "You are about to delete the file" // prompt-scanner:ignore
+const (
+	PragmaPrompt = "prompt-scanner:prompt" // Always report the literal
+	PragmaIgnore = "prompt-scanner:ignore" // Never report the literal
+)
+
+// filePragmas maps 1-based line numbers to the pragma applying to literals starting there.
+type filePragmas map[int]string
+
+// parsePragmas finds the pragma comments in source. It returns nil if there are none.
+func parsePragmas(source []byte) filePragmas {
+	if !bytes.Contains(source, []byte("prompt-scanner:")) {
+		return nil
+	}
+	pragmas := filePragmas{}
+	for i, line := range strings.Split(string(source), "\n") {
+		var pragma string
+		switch {
+		case strings.Contains(line, PragmaIgnore):
+			pragma = PragmaIgnore
+		case strings.Contains(line, PragmaPrompt):
+			pragma = PragmaPrompt
+		default:
+			continue
+		}
+		lineNumber := i + 1
+		if isCommentLine(strings.TrimSpace(line)) || strings.HasPrefix(strings.TrimSpace(line), "<!--") {
+			lineNumber++ // A comment-only line marks the next line
+		}
+		pragmas[lineNumber] = pragma
+	}
+	return pragmas
+}
@@ -0,0 +1,66 @@
+// scanner/prefilter.go
+package scanner
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// skipLiteral reports whether a candidate literal cannot be a prompt under any acceptance rule, so
+// parsers can drop it before building its FoundPrompt and PromptContext. Most literals in code are
+// short keys, identifiers, paths and numbers; for them the check is a byte scan plus, for the few
+// that pass it, one keyword match.
+//
+// A literal is skipped when it is empty, or when it is a single line shorter than MinLength that
+// either contains no letters or is identifier-like (ASCII letters, digits and _-./:) and contains
+// no content keyword. In greedy mode, which also scores variable names, placeholders and multi-line
+// syntax, it must in addition not be assigned to a variable with a prompt-like name, contain no
+// placeholder and not use multi-line syntax (multiLine). Such a literal fails every acceptance
+// rule. Literals on a line marked with PragmaPrompt are never skipped, and nothing is skipped
+// while an observer or a trace needs to see every candidate.
+func (s *Scanner) skipLiteral(text string, line int, varName string, multiLine bool) bool {
+	if s.observe != nil || s.Options.compiledTrace != nil || s.pragmas[line] == PragmaPrompt {
+		return false
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return true
+	}
+	if len(text) >= s.Options.MinLength || !trivialLiteral(text) {
+		return false
+	}
+	if s.Options.compiledContentWords != nil && s.Options.compiledContentWords.MatchString(text) {
+		return false
+	}
+	if !s.Options.preset().Greedy {
+		return true
+	}
+	if multiLine || (varName != "" && s.Options.compiledVarKeywords != nil && s.Options.compiledVarKeywords.MatchString(varName)) {
+		return false
+	}
+	for _, re := range s.Options.compiledPlaceholders {
+		if re.MatchString(text) {
+			return false
+		}
+	}
+	return true
+}
+
+// trivialLiteral reports whether a single-line text has no letters at all or looks like an
+// identifier, path or version rather than prose. Non-ASCII text is never trivial.
+func trivialLiteral(text string) bool {
+	hasLetter, identifier := false, true
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c >= utf8.RuneSelf || c == '\n':
+			return false
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+			hasLetter = true
+		case '0' <= c && c <= '9', c == '_', c == '-', c == '.', c == '/', c == ':':
+		default:
+			identifier = false
+		}
+	}
+	return !hasLetter || identifier
+}
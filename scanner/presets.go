@@ -0,0 +1,152 @@
+// scanner/presets.go
+package scanner
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Heuristic modes, as set in ScanOptions.Mode.
+const (
+	ModeStrict   = "strict"
+	ModeBalanced = "balanced"
+	ModeGreedy   = "greedy"
+)
+
+// HeuristicsVersion identifies the built-in rules: the presets, the default keyword lists and the
+// rejection rules. It is bumped whenever a change to them changes which strings are reported, and
+// recorded in baselines and reports, so that a baseline recorded with other rules is noticed and
+// can be migrated instead of failing CI with findings nobody added.
+const HeuristicsVersion = 3
+
+// HeuristicsChange describes what one HeuristicsVersion changed.
+type HeuristicsChange struct {
+	Version int
+	Summary string
+}
+
+// heuristicsChanges lists every HeuristicsVersion, oldest first.
+var heuristicsChanges = []HeuristicsChange{
+	{1, "first versioned rules: strict, balanced and greedy presets, log, error and logging-call rejections"},
+	{2, "code held in YAML, JSON and TOML values, such as CI scripts, is parsed in its own language instead of being evaluated as one string"},
+	{3, "extensionless scripts whose shebang or <?php tag names a supported language, such as bin/deploy, are scanned"},
+}
+
+// HeuristicsChangesSince returns the changes made to the built-in rules after version, oldest
+// first. Version 0 stands for rules that were not versioned yet and returns every change.
+func HeuristicsChangesSince(version int) []HeuristicsChange {
+	var changes []HeuristicsChange
+	for _, change := range heuristicsChanges {
+		if change.Version > version {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// Preset is a named set of heuristic settings. The rule settings are read from the preset named by
+// ScanOptions.Mode during the scan; MinLength and ContentKeywords are the defaults a preset
+// suggests for the corresponding options, which Apply copies.
+type Preset struct {
+	Name string
+	// Greedy selects the score-based rules instead of the content keyword rules.
+	Greedy          bool
+	MinLength       int
+	ContentKeywords []string
+	// Keyword rules: PrefixNeedsMinLength makes strings that start with a content keyword also
+	// need MinLength characters, and PathShortcut accepts a single-line string containing a
+	// keyword under prompt directories such as prompts/.
+	PrefixNeedsMinLength bool
+	PathShortcut         bool
+	// Score rules: a string is accepted with AcceptScore points, or with LongAcceptScore points
+	// when it has at least MinLength characters (see scoreSignals for the points).
+	AcceptScore     int
+	LongAcceptScore int
+}
+
+var (
+	// PresetStrict reports only clear prompts: strings that start with a strong content keyword
+	// and are long, or multi-line strings containing one.
+	PresetStrict = Preset{
+		Name:                 ModeStrict,
+		MinLength:            40,
+		ContentKeywords:      strictContentKeywords(),
+		PrefixNeedsMinLength: true,
+		AcceptScore:          4,
+		LongAcceptScore:      3,
+	}
+	// PresetBalanced is the default: strings that start with a content keyword, or contain one and
+	// span several lines or sit under a prompt directory.
+	PresetBalanced = Preset{
+		Name:            ModeBalanced,
+		MinLength:       DefaultMinLength,
+		ContentKeywords: DefaultContentKeywordsList,
+		PathShortcut:    true,
+		AcceptScore:     3,
+		LongAcceptScore: 2,
+	}
+	// PresetGreedy scores variable names, keywords, placeholders, length and location, and
+	// reports anything scoring high enough. It finds more prompts and more false positives.
+	PresetGreedy = Preset{
+		Name:            ModeGreedy,
+		Greedy:          true,
+		MinLength:       DefaultMinLength,
+		ContentKeywords: DefaultContentKeywordsList,
+		PathShortcut:    true,
+		AcceptScore:     3,
+		LongAcceptScore: 2,
+	}
+
+	// Presets lists the built-in presets from strictest to most permissive.
+	Presets = []Preset{PresetStrict, PresetBalanced, PresetGreedy}
+)
+
+// weakContentKeywords are default content keywords that often start ordinary prose, which the
+// strict preset leaves out.
+var weakContentKeywords = []string{
+	"from this", "break down", "given the", "what is the", "explain the",
+	"here's", "here is", "here are", "consider this",
+}
+
+func strictContentKeywords() []string {
+	var keywords []string
+	for _, keyword := range DefaultContentKeywordsList {
+		if !slices.Contains(weakContentKeywords, keyword) {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// LookupPreset returns the built-in preset called name.
+func LookupPreset(name string) (Preset, error) {
+	for _, preset := range Presets {
+		if preset.Name == name {
+			return preset, nil
+		}
+	}
+	return Preset{}, fmt.Errorf("unknown mode '%s' (use %s, %s or %s)", name, ModeStrict, ModeBalanced, ModeGreedy)
+}
+
+// Apply sets the options to the preset: Mode, MinLength and ContentKeywords.
+func (p Preset) Apply(opts *ScanOptions) {
+	opts.Mode = p.Name
+	opts.MinLength = p.MinLength
+	opts.ContentKeywords = slices.Clone(p.ContentKeywords)
+}
+
+// preset returns the preset the options select: the one named by Mode, or for an empty Mode the
+// greedy preset if Greedy is set and the balanced one otherwise. Mode is validated by New.
+func (so *ScanOptions) preset() Preset {
+	if so.Mode == "" {
+		if so.Greedy {
+			return PresetGreedy
+		}
+		return PresetBalanced
+	}
+	preset, err := LookupPreset(so.Mode)
+	if err != nil {
+		return PresetBalanced
+	}
+	return preset
+}
@@ -0,0 +1,25 @@
+// scanner/reject.go
+package scanner
+
+// Reason codes for rejected candidates, reported with ScanOptions.IncludeRejected.
+const (
+	RejectPragma        = "pragma_ignore"      // A PragmaIgnore comment marks the string
+	RejectMultilineOnly = "multiline_only"     // Single-line string with MultilineOnly set
+	RejectMinLines      = "min_lines"          // Fewer lines than MinLines
+	RejectEmpty         = "empty"              // Only whitespace
+	RejectNoKeyword     = "no_content_keyword" // Default mode: no content keyword in the string
+	RejectSingleLine    = "single_line"        // Default mode: a content keyword, but neither at the start nor in a multi-line string
+	RejectDemotedPath   = "demoted_path"       // Under a locale or fixture directory
+	RejectLogMessage    = "log_message"        // Starts like a log message ("Error:", "failed to", ...)
+	RejectErrorMessage  = "error_message"      // Short argument of an error or exception constructor
+	RejectLoggingCall   = "logging_call"       // Short argument of a logging call
+	RejectLowScore      = "low_score"          // Greedy mode: no acceptance rule reached
+	RejectClassifier    = "classifier"         // ScanOptions.Classifier rejected the string
+)
+
+// reject records why fp was rejected, traces the step and returns false.
+func (s *Scanner) reject(fp *FoundPrompt, reason, format string, args ...any) bool {
+	fp.RejectReason = reason
+	s.tracef(format+": reject", args...)
+	return false
+}
@@ -0,0 +1,45 @@
+// scanner/report_azure.go
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	RegisterReporter("azure-devops", func(opts ReporterOptions) Reporter {
+		return &azureReporter{w: opts.Writer}
+	})
+}
+
+// azureReporter streams Azure Pipelines logging commands, one warning per finding, which the
+// pipeline shows as annotations on the build and its pull request:
+//
+//	##vso[task.logissue type=warning;sourcepath=app.py;linenumber=12;columnnumber=1;code=prompt-scanner][high] Potential prompt: … [fp:537d7d2a5c0f1e9b]
+type azureReporter struct {
+	w    io.Writer
+	meta ReportMeta
+}
+
+// azureEscapeMessage and azureEscapeProperty escape text for the message and the properties of a
+// logging command.
+var (
+	azureEscapeMessage  = strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A")
+	azureEscapeProperty = strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A", "]", "%5D", ";", "%3B")
+)
+
+func (r *azureReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	return nil
+}
+
+func (r *azureReporter) Report(p FoundPrompt) error {
+	message := fmt.Sprintf("[%s] Potential prompt: %s%s", p.Severity, previewLine(p.Content), fingerprintTag(p))
+	_, err := fmt.Fprintf(r.w, "##vso[task.logissue type=warning;sourcepath=%s;linenumber=%d;columnnumber=1;code=prompt-scanner]%s\n",
+		azureEscapeProperty.Replace(filepath.ToSlash(r.meta.DisplayPath(p.Filepath))), p.Line, azureEscapeMessage.Replace(message))
+	return err
+}
+
+func (r *azureReporter) Finish() error { return nil }
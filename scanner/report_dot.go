@@ -0,0 +1,198 @@
+// scanner/report_dot.go
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	RegisterReporter("dot", func(opts ReporterOptions) Reporter {
+		return &dotReporter{w: opts.Writer}
+	})
+}
+
+// dotLabelLength is the number of characters of a prompt shown in its graph node.
+const dotLabelLength = 40
+
+// dotReporter buffers findings and writes a Graphviz graph of where prompts are used, for
+// rendering with e.g. `dot -Tsvg`:
+//
+//   - each file is a cluster holding its symbols (functions, methods, classes) that contain
+//     findings, and a node for the file itself if findings are outside any symbol;
+//   - each distinct prompt text (by fingerprint) is one note node, so a prompt used in several places has an edge
+//     from every symbol using it, labelled with the line and the variable or config key;
+//   - models the prompts are sent to are ellipses, with dashed edges from the prompts.
+type dotReporter struct {
+	w    io.Writer
+	meta ReportMeta
+
+	files     []*dotFile
+	fileIDs   map[string]*dotFile // By display path
+	prompts   []dotPrompt
+	promptIDs map[string]int // By fingerprint
+	models    []string
+	modelIDs  map[string]int // By model name
+	uses      []dotUse
+	sentTo    map[[2]int]bool // Prompt and model indexes linked by an edge
+}
+
+// dotFile is the cluster of one file and the symbols in it that hold findings.
+type dotFile struct {
+	path    string
+	index   int
+	symbols []string
+	symIDs  map[string]int
+	direct  bool // Some findings are outside any symbol
+}
+
+// dotPrompt is the node of one prompt text, colored by the severity of its first finding.
+type dotPrompt struct {
+	label    string
+	severity string
+}
+
+// dotUse is an edge from a symbol, or the file when symbol is -1, to a prompt.
+type dotUse struct {
+	file   *dotFile
+	symbol int
+	prompt int
+	label  string
+}
+
+func (r *dotReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	r.fileIDs = make(map[string]*dotFile)
+	r.promptIDs = make(map[string]int)
+	r.modelIDs = make(map[string]int)
+	r.sentTo = make(map[[2]int]bool)
+	return nil
+}
+
+func (r *dotReporter) Report(p FoundPrompt) error {
+	path := filepath.ToSlash(r.meta.DisplayPath(p.Filepath))
+	file, ok := r.fileIDs[path]
+	if !ok {
+		file = &dotFile{path: path, index: len(r.files), symIDs: make(map[string]int)}
+		r.fileIDs[path] = file
+		r.files = append(r.files, file)
+	}
+	symbol := -1
+	if p.Symbol == "" {
+		file.direct = true
+	} else {
+		if symbol, ok = file.symIDs[p.Symbol]; !ok {
+			symbol = len(file.symbols)
+			file.symIDs[p.Symbol] = symbol
+			file.symbols = append(file.symbols, p.Symbol)
+		}
+	}
+
+	content := strings.TrimSpace(p.Content)
+	fingerprint := PromptFingerprint(content)
+	prompt, ok := r.promptIDs[fingerprint]
+	if !ok {
+		prompt = len(r.prompts)
+		r.promptIDs[fingerprint] = prompt
+		r.prompts = append(r.prompts, dotPrompt{label: dotPromptLabel(content), severity: p.Severity})
+	}
+
+	label := fmt.Sprintf("L%d", p.Line)
+	// Code held in a config value names its own variables; templates are named by their key.
+	if p.Embedded != nil && isEmbeddedCode(p.Embedded.Language) {
+		label += " " + p.Embedded.Key + ":"
+	}
+	if p.VariableName != "" {
+		label += " " + p.VariableName
+	}
+	r.uses = append(r.uses, dotUse{file: file, symbol: symbol, prompt: prompt, label: label})
+
+	if p.Model != "" {
+		model, ok := r.modelIDs[p.Model]
+		if !ok {
+			model = len(r.models)
+			r.modelIDs[p.Model] = model
+			name := p.Model
+			if p.Provider != "" {
+				name += "\n(" + p.Provider + ")"
+			}
+			r.models = append(r.models, name)
+		}
+		r.sentTo[[2]int{prompt, model}] = true
+	}
+	return nil
+}
+
+func (r *dotReporter) Finish() error {
+	var b strings.Builder
+	b.WriteString("digraph prompts {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\", fontsize=10];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=8];\n")
+	for _, file := range r.files {
+		fmt.Fprintf(&b, "  subgraph \"cluster_f%d\" {\n", file.index)
+		fmt.Fprintf(&b, "    label=%s;\n", dotQuote(file.path))
+		if file.direct {
+			fmt.Fprintf(&b, "    \"f%d\" [label=%s, shape=folder];\n", file.index, dotQuote(filepath.Base(file.path)))
+		}
+		for i, symbol := range file.symbols {
+			fmt.Fprintf(&b, "    \"f%d_s%d\" [label=%s, shape=box];\n", file.index, i, dotQuote(symbol))
+		}
+		b.WriteString("  }\n")
+	}
+	for i, prompt := range r.prompts {
+		fmt.Fprintf(&b, "  \"p%d\" [label=%s, shape=note, style=filled, fillcolor=%s];\n", i, dotQuote(prompt.label), dotSeverityColor(prompt.severity))
+	}
+	for i, model := range r.models {
+		fmt.Fprintf(&b, "  \"m%d\" [label=%s, shape=ellipse];\n", i, dotQuote(model))
+	}
+	for _, use := range r.uses {
+		from := fmt.Sprintf("f%d", use.file.index)
+		if use.symbol >= 0 {
+			from += fmt.Sprintf("_s%d", use.symbol)
+		}
+		fmt.Fprintf(&b, "  \"%s\" -> \"p%d\" [label=%s];\n", from, use.prompt, dotQuote(use.label))
+	}
+	for prompt := range r.prompts {
+		for model := range r.models {
+			if r.sentTo[[2]int{prompt, model}] {
+				fmt.Fprintf(&b, "  \"p%d\" -> \"m%d\" [style=dashed];\n", prompt, model)
+			}
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
+
+// dotPromptLabel shortens a prompt to the first dotLabelLength characters of its first line.
+func dotPromptLabel(content string) string {
+	line, _, more := strings.Cut(content, "\n")
+	if runes := []rune(line); len(runes) > dotLabelLength {
+		line, more = string(runes[:dotLabelLength]), true
+	}
+	if more {
+		line += "…"
+	}
+	return line
+}
+
+// dotSeverityColor is the fill color of prompts of a severity.
+func dotSeverityColor(severity string) string {
+	switch severity {
+	case SeverityHigh:
+		return `"#f4cccc"`
+	case SeverityMedium:
+		return `"#fff2cc"`
+	default:
+		return `"#eeeeee"`
+	}
+}
+
+// dotQuote quotes text as a DOT string; newlines become centered line breaks.
+func dotQuote(text string) string {
+	text = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`).Replace(text)
+	return `"` + text + `"`
+}
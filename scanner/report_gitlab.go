@@ -0,0 +1,89 @@
+// scanner/report_gitlab.go
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+func init() {
+	RegisterReporter("gitlab-codequality", func(opts ReporterOptions) Reporter {
+		return &gitlabReporter{w: opts.Writer}
+	})
+}
+
+// gitlabIssue is one entry of a GitLab code quality report (a subset of the Code Climate format).
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+type gitlabLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end,omitempty"`
+}
+
+// gitlabSeverities maps finding severities to code quality severities.
+var gitlabSeverities = map[string]string{
+	SeverityHigh:   "major",
+	SeverityMedium: "minor",
+	SeverityLow:    "info",
+}
+
+// gitlabReporter buffers findings and writes a GitLab code quality report, shown inline in merge
+// requests when uploaded as the artifacts:reports:codequality of a job.
+type gitlabReporter struct {
+	w      io.Writer
+	meta   ReportMeta
+	issues []gitlabIssue
+}
+
+func (r *gitlabReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	r.issues = []gitlabIssue{}
+	return nil
+}
+
+func (r *gitlabReporter) Report(p FoundPrompt) error {
+	severity, ok := gitlabSeverities[p.Severity]
+	if !ok {
+		severity = "info"
+	}
+	// Code quality fingerprints must be unique per issue, which the prompt fingerprint shared by
+	// copies of a prompt is not, so the issue is identified by the finding ID and the prompt
+	// fingerprint goes in the description.
+	fingerprint := p.ID
+	if fingerprint == "" {
+		fingerprint = fmt.Sprintf("%s:%d", r.meta.DisplayPath(p.Filepath), p.Line)
+	}
+	r.issues = append(r.issues, gitlabIssue{
+		Description: "Potential prompt: " + previewLine(p.Content) + fingerprintTag(p),
+		CheckName:   "prompt-scanner",
+		Fingerprint: fingerprint,
+		Severity:    severity,
+		Location: gitlabLocation{
+			Path:  filepath.ToSlash(r.meta.DisplayPath(p.Filepath)),
+			Lines: gitlabLines{Begin: p.Line, End: p.EndLine},
+		},
+	})
+	return nil
+}
+
+func (r *gitlabReporter) Finish() error {
+	data, err := json.MarshalIndent(r.issues, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling code quality report: %w", err)
+	}
+	_, err = fmt.Fprintf(r.w, "%s\n", data)
+	return err
+}
@@ -0,0 +1,101 @@
+// scanner/report_html.go
+package scanner
+
+import (
+	"html"
+	"html/template"
+	"io"
+	"log"
+	"strings"
+)
+
+func init() {
+	RegisterReporter("html", func(opts ReporterOptions) Reporter {
+		return &htmlReporter{w: opts.Writer, contextLines: opts.SnippetLines}
+	})
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Prompt scan report</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem; color: #1f2328; }
+.finding { border: 1px solid #d0d7de; border-radius: 6px; margin: 1rem 0; }
+.finding h2 { font-size: 0.95rem; margin: 0; padding: 0.5rem 0.75rem; background: #f6f8fa; border-bottom: 1px solid #d0d7de; }
+.finding pre { margin: 0; padding: 0.75rem; white-space: pre-wrap; }
+.finding .signals { margin: 0; padding: 0.5rem 0.75rem 0; font-size: 0.85rem; color: #57606a; }
+.finding .symbol { font-weight: normal; color: #57606a; margin-left: 0.5rem; }
+.finding .fingerprint { float: right; font-weight: normal; color: #57606a; }
+mark { background-color: #ffd33d; }
+{{.CSS}}</style>
+</head>
+<body>
+<h1>Prompt scan report</h1>
+<p>Target: <code>{{.Target}}</code>{{if .Commit}} at <code>{{.Commit}}</code>{{end}}</p>
+<p>Found {{len .Findings}} potential prompts.</p>
+{{range .Findings}}<div class="finding">
+<h2>{{if .Permalink}}<a href="{{.Permalink}}">{{.Filepath}}:{{.Line}}</a>{{else}}{{.Filepath}}:{{.Line}}{{end}}{{if .Symbol}} <code class="symbol">{{.Symbol}}</code>{{end}}{{if .Fingerprint}} <code class="fingerprint" title="Prompt fingerprint">{{.Fingerprint}}</code>{{end}}</h2>
+{{if .Signals}}<p class="signals">Matched: {{range $i, $s := .Signals}}{{if $i}}, {{end}}{{$s}}{{end}}</p>
+{{end}}{{.Snippet}}
+</div>
+{{end}}</body>
+</html>
+`))
+
+// htmlFinding is the view model of a single finding in the HTML report.
+type htmlFinding struct {
+	JSONOutput
+	Signals []string
+	Snippet template.HTML // Pre-rendered, already escaped snippet markup
+}
+
+// htmlReporter buffers findings and writes a standalone HTML page with highlighted snippets.
+type htmlReporter struct {
+	w            io.Writer
+	contextLines int
+	meta         ReportMeta
+	prompts      []FoundPrompt
+}
+
+func (r *htmlReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	return nil
+}
+
+func (r *htmlReporter) Report(p FoundPrompt) error {
+	r.prompts = append(r.prompts, p)
+	return nil
+}
+
+func (r *htmlReporter) Finish() error {
+	sourceCache := make(map[string][]string)
+	findings := make([]htmlFinding, len(r.prompts))
+	for i, p := range r.prompts {
+		var signals []string
+		for _, signal := range matchedSignals(p) {
+			signals = append(signals, strings.ReplaceAll(signal, "`", ""))
+		}
+		snippet, err := readSnippet(p, r.contextLines, sourceCache)
+		var rendered string
+		if err != nil {
+			log.Printf("Warning: %v. Showing extracted content instead.", err)
+			rendered = "<pre>" + html.EscapeString(p.Content) + "</pre>"
+		} else {
+			rendered = highlightHTML(snippet, p)
+		}
+		findings[i] = htmlFinding{
+			JSONOutput: r.meta.JSONFinding(p),
+			Signals:    signals,
+			Snippet:    template.HTML(rendered),
+		}
+	}
+	data := struct {
+		Target   string
+		Commit   string
+		CSS      template.CSS
+		Findings []htmlFinding
+	}{r.meta.Target, r.meta.Commit, template.CSS(snippetCSS()), findings}
+	return htmlReportTemplate.Execute(r.w, data)
+}
@@ -0,0 +1,156 @@
+// scanner/report_json.go
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+func init() {
+	RegisterReporter("json", func(opts ReporterOptions) Reporter {
+		return &jsonReporter{w: opts.Writer}
+	})
+	RegisterReporter("ndjson", func(opts ReporterOptions) Reporter {
+		return &ndjsonReporter{w: opts.Writer}
+	})
+	RegisterReporter("envelope", func(opts ReporterOptions) Reporter {
+		return &envelopeReporter{w: opts.Writer, toolVersion: opts.ToolVersion, weights: opts.Weights}
+	})
+}
+
+// jsonReporter streams findings as an indented JSON array.
+type jsonReporter struct {
+	w     io.Writer
+	meta  ReportMeta
+	count int
+}
+
+func (r *jsonReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	return nil
+}
+
+func (r *jsonReporter) Report(p FoundPrompt) error {
+	jsonData, err := json.MarshalIndent(r.meta.JSONFinding(p), "  ", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling JSON: %w", err)
+	}
+	separator := "[\n  "
+	if r.count > 0 {
+		separator = ",\n  "
+	}
+	r.count++
+	_, err = fmt.Fprintf(r.w, "%s%s", separator, jsonData)
+	return err
+}
+
+func (r *jsonReporter) Finish() error {
+	if r.count == 0 {
+		_, err := fmt.Fprintln(r.w, "[]")
+		return err
+	}
+	_, err := fmt.Fprintln(r.w, "\n]")
+	return err
+}
+
+// ndjsonReporter writes each finding as one compact JSON object per line, as soon as it is
+// reported.
+type ndjsonReporter struct {
+	w    io.Writer
+	meta ReportMeta
+}
+
+func (r *ndjsonReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	return nil
+}
+
+func (r *ndjsonReporter) Report(p FoundPrompt) error {
+	jsonData, err := json.Marshal(r.meta.JSONFinding(p))
+	if err != nil {
+		return fmt.Errorf("marshalling JSON: %w", err)
+	}
+	_, err = fmt.Fprintf(r.w, "%s\n", jsonData)
+	return err
+}
+
+func (r *ndjsonReporter) Finish() error { return nil }
+
+// envelopeReporter buffers findings and writes them wrapped in a JSONEnvelope.
+type envelopeReporter struct {
+	w           io.Writer
+	toolVersion string
+	weights     SeverityWeights
+	meta        ReportMeta
+	envelope    JSONEnvelope
+	prompts     []FoundPrompt
+}
+
+func (r *envelopeReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	r.envelope = newEnvelope(meta, r.toolVersion)
+	return nil
+}
+
+func (r *envelopeReporter) Report(p FoundPrompt) error {
+	r.envelope.Findings = append(r.envelope.Findings, r.meta.JSONFinding(p))
+	r.prompts = append(r.prompts, p)
+	return nil
+}
+
+// newEnvelope returns an envelope for meta without findings. An empty toolVersion is recorded as
+// "dev".
+func newEnvelope(meta ReportMeta, toolVersion string) JSONEnvelope {
+	if toolVersion == "" {
+		toolVersion = "dev"
+	}
+	envelope := JSONEnvelope{
+		SchemaVersion: SchemaVersion,
+		Tool:          ToolInfo{Name: "prompt-scanner", Version: toolVersion, HeuristicsVersion: HeuristicsVersion},
+		Target:        meta.Target,
+		Targets:       meta.targetNames(),
+		Commit:        meta.Commit,
+		Ref:           meta.Ref,
+		Project:       meta.Project,
+		Team:          meta.Team,
+		Labels:        meta.Labels,
+		Hashes:        meta.Hashes,
+		Findings:      []JSONOutput{},
+	}
+	if !meta.Reproducible {
+		envelope.GeneratedAt = time.Now().UTC()
+	}
+	return envelope
+}
+
+// BuildEnvelope returns the envelope document of a completed scan, as the envelope format writes
+// it.
+func BuildEnvelope(meta ReportMeta, toolVersion string, prompts []FoundPrompt, weights SeverityWeights) JSONEnvelope {
+	envelope := newEnvelope(meta, toolVersion)
+	for _, p := range prompts {
+		envelope.Findings = append(envelope.Findings, meta.JSONFinding(p))
+	}
+	envelope.Summary = Summarize(prompts, weights)
+	return envelope
+}
+
+func (r *envelopeReporter) Finish() error {
+	r.envelope.Summary = Summarize(r.prompts, r.weights)
+	jsonData, err := json.MarshalIndent(r.envelope, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(r.w, string(jsonData))
+	return err
+}
+
+// targetNames returns the targets of a run that scans several, as given.
+func (m ReportMeta) targetNames() []string {
+	var names []string
+	for _, t := range m.Targets {
+		names = append(names, t.Target)
+	}
+	return names
+}
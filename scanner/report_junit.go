@@ -0,0 +1,123 @@
+// scanner/report_junit.go
+package scanner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterReporter("junit", func(opts ReporterOptions) Reporter {
+		return &junitReporter{w: opts.Writer}
+	})
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",cdata"`
+}
+
+// junitReporter buffers findings and writes a JUnit XML report with one test suite per file and
+// one failed test case per finding. A scan without findings yields a single passing test case, as
+// some CI systems treat reports without tests as broken.
+type junitReporter struct {
+	w      io.Writer
+	meta   ReportMeta
+	suites []junitTestSuite
+	index  map[string]int // Display path to position in suites
+}
+
+func (r *junitReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	r.index = make(map[string]int)
+	return nil
+}
+
+func (r *junitReporter) Report(p FoundPrompt) error {
+	path := filepath.ToSlash(r.meta.DisplayPath(p.Filepath))
+	i, ok := r.index[path]
+	if !ok {
+		i = len(r.suites)
+		r.index[path] = i
+		r.suites = append(r.suites, junitTestSuite{Name: path})
+	}
+	body := p.Content
+	if permalink := r.meta.Permalink(p); permalink != "" {
+		body += "\n\n" + permalink
+	}
+	if p.Fingerprint != "" {
+		body += "\n\nFingerprint: " + p.Fingerprint
+	}
+	severity := p.Severity
+	if severity == "" {
+		severity = SeverityLow
+	}
+	suite := &r.suites[i]
+	suite.Tests++
+	suite.Failures++
+	suite.TestCases = append(suite.TestCases, junitTestCase{
+		Name:      fmt.Sprintf("%s:%d", path, p.Line),
+		ClassName: strings.TrimSuffix(path, filepath.Ext(path)),
+		File:      path,
+		Line:      p.Line,
+		Failure: &junitFailure{
+			Message: fmt.Sprintf("[%s] Potential prompt: %s", severity, previewLine(p.Content)),
+			Type:    severity,
+			Body:    body,
+		},
+	})
+	return nil
+}
+
+func (r *junitReporter) Finish() error {
+	report := junitTestSuites{Name: "prompt-scanner", Suites: r.suites}
+	if len(report.Suites) == 0 {
+		report.Suites = []junitTestSuite{{
+			Name:      "prompt-scanner",
+			Tests:     1,
+			TestCases: []junitTestCase{{Name: "no potential prompts", ClassName: "prompt-scanner"}},
+		}}
+	}
+	for _, suite := range report.Suites {
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+	}
+	if !r.meta.StartedAt.IsZero() {
+		report.Time = fmt.Sprintf("%.3f", time.Since(r.meta.StartedAt).Seconds())
+	}
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling JUnit report: %w", err)
+	}
+	_, err = fmt.Fprintf(r.w, "%s%s\n", xml.Header, data)
+	return err
+}
@@ -0,0 +1,114 @@
+// scanner/report_markdown.go
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+func init() {
+	RegisterReporter("markdown", func(opts ReporterOptions) Reporter {
+		return &markdownReporter{w: opts.Writer, contextLines: opts.SnippetLines}
+	})
+}
+
+// markdownReporter buffers findings and writes a Markdown report with source snippets.
+type markdownReporter struct {
+	w            io.Writer
+	contextLines int
+	meta         ReportMeta
+	prompts      []FoundPrompt
+}
+
+func (r *markdownReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	return nil
+}
+
+func (r *markdownReporter) Report(p FoundPrompt) error {
+	r.prompts = append(r.prompts, p)
+	return nil
+}
+
+func (r *markdownReporter) Finish() error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Prompt scan report\n\n")
+	fmt.Fprintf(&b, "Target: `%s`", r.meta.Target)
+	if r.meta.Commit != "" {
+		fmt.Fprintf(&b, " at `%s`", r.meta.Commit)
+	}
+	fmt.Fprintf(&b, "\n\nFound %d potential prompts.\n", len(r.prompts))
+
+	sourceCache := make(map[string][]string)
+	for _, p := range r.prompts {
+		location := fmt.Sprintf("%s:%d", r.meta.DisplayPath(p.Filepath), p.Line)
+		if link := r.meta.Permalink(p); link != "" {
+			fmt.Fprintf(&b, "\n## [%s](%s)\n\n", location, link)
+		} else {
+			fmt.Fprintf(&b, "\n## %s\n\n", location)
+		}
+		if p.Symbol != "" {
+			fmt.Fprintf(&b, "Symbol: `%s`\n\n", p.Symbol)
+		}
+		if p.Fingerprint != "" {
+			fmt.Fprintf(&b, "Fingerprint: `%s`\n\n", p.Fingerprint)
+		}
+		if signals := matchedSignals(p); len(signals) > 0 {
+			fmt.Fprintf(&b, "Matched: %s\n\n", strings.Join(signals, ", "))
+		}
+
+		snippet, err := readSnippet(p, r.contextLines, sourceCache)
+		if err != nil {
+			log.Printf("Warning: %v. Showing extracted content instead.", err)
+			snippet = sourceSnippet{Code: strings.TrimRight(p.Content, "\n") + "\n"}
+		}
+		fence := markdownFence(snippet.Code)
+		fmt.Fprintf(&b, "%s%s\n%s%s\n", fence, snippet.Language, snippet.Code, fence)
+	}
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
+
+// matchedSignals describes, for report readers, which keywords or placeholders triggered a finding.
+func matchedSignals(p FoundPrompt) []string {
+	var signals []string
+	if p.Marked {
+		signals = append(signals, "pragma `"+PragmaPrompt+"`")
+	}
+	if p.MatchedVariableName != "" {
+		signals = append(signals, "variable `"+p.MatchedVariableName+"`")
+	}
+	if p.MatchedContentWord != "" {
+		signals = append(signals, "keyword `"+p.MatchedContentWord+"`")
+	}
+	if p.MatchedPlaceholder != "" {
+		signals = append(signals, "placeholder `"+p.MatchedPlaceholder+"`")
+	}
+	if p.MatchedPath != "" {
+		signals = append(signals, "path `"+p.MatchedPath+"`")
+	}
+	for _, contract := range p.OutputContracts {
+		signals = append(signals, fmt.Sprintf("output contract `%s` (line %d of the prompt)", contract.Format, contract.Line))
+	}
+	for _, v := range p.PolicyViolations {
+		signals = append(signals, fmt.Sprintf("policy `%s` (%s): %s", v.Rule, v.Severity, v.Message))
+	}
+	for _, lint := range p.Lints {
+		signals = append(signals, fmt.Sprintf("lint `%s`: %s", lint.Rule, lint.Message))
+	}
+	if p.Kind == KindRAGScaffold {
+		signals = append(signals, "RAG scaffold (slots `"+strings.Join(p.Slots, "`, `")+"`)")
+	}
+	return signals
+}
+
+// markdownFence returns a code fence long enough not to collide with backticks inside content.
+func markdownFence(content string) string {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	return fence
+}
@@ -0,0 +1,195 @@
+// scanner/report_prcomment.go
+package scanner
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+// PRCommentMarker starts every pr-comment body, so CI can find and update its previous comment
+// instead of posting a new one on each push.
+const PRCommentMarker = "<!-- prompt-scanner:pr-comment -->"
+
+// prCommentPreviewLength is the number of characters of each prompt shown in the findings table.
+const prCommentPreviewLength = 80
+
+// prCommentMaxFindings is the number of findings listed in the table, most severe first, keeping
+// the body well below GitHub's 65536-character limit on comments.
+const prCommentMaxFindings = 25
+
+func init() {
+	RegisterReporter("pr-comment", func(opts ReporterOptions) Reporter {
+		return &prCommentReporter{w: opts.Writer, baseline: opts.Baseline, weights: opts.Weights}
+	})
+}
+
+// prCommentReporter buffers findings and writes one Markdown comment body for a pull request:
+// new prompts, prompts changed since the base branch, removed prompts and how to suppress
+// findings. Without a baseline every finding counts as new.
+type prCommentReporter struct {
+	w        io.Writer
+	baseline *JSONEnvelope
+	weights  SeverityWeights
+	meta     ReportMeta
+	prompts  []FoundPrompt
+}
+
+func (r *prCommentReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	return nil
+}
+
+func (r *prCommentReporter) Report(p FoundPrompt) error {
+	r.prompts = append(r.prompts, p)
+	return nil
+}
+
+// prChange is a prompt whose text differs from the base branch.
+type prChange struct {
+	Finding JSONOutput
+	Base    JSONOutput
+}
+
+func (r *prCommentReporter) Finish() error {
+	var findings []JSONOutput
+	for _, p := range r.prompts {
+		findings = append(findings, r.meta.JSONFinding(p))
+	}
+	added, changed, removed := compareWithBaseline(findings, r.baseline)
+	sortBySeverity(added)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n## Prompt scan\n\n", PRCommentMarker)
+	summary := Summarize(r.prompts, r.weights)
+	if r.baseline == nil {
+		fmt.Fprintf(&b, "Found **%d** potential prompts (%s). Prompt hygiene score: **%d/100**.\n", len(findings), severityCounts(summary), summary.HygieneScore)
+	} else {
+		base := "the base branch"
+		if r.baseline.Ref != "" {
+			base = "`" + r.baseline.Ref + "`"
+		}
+		fmt.Fprintf(&b, "Compared with %s: **%d new**, **%d changed**, **%d removed** prompts. Prompt hygiene score: **%d/100** (base: %d/100).\n",
+			base, len(added), len(changed), len(removed), summary.HygieneScore, r.baseline.Summary.HygieneScore)
+		fmt.Fprintf(&b, "\n%d potential prompts in total (%s).\n", len(findings), severityCounts(summary))
+	}
+
+	if len(added) > 0 {
+		heading := "New prompts"
+		if r.baseline == nil {
+			heading = "Prompts"
+		}
+		// Markdown is not rendered inside <summary>, so headings there are plain HTML.
+		fmt.Fprintf(&b, "\n<details><summary><b>%s (%d)</b></summary>\n\n| Severity | Location | Prompt | Fingerprint |\n| --- | --- | --- | --- |\n", heading, len(added))
+		for i, f := range added {
+			if i == prCommentMaxFindings {
+				fmt.Fprintf(&b, "\n…and %d more, with lower or equal severity. See the full report for all of them.\n", len(added)-i)
+				break
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | `%s` |\n", f.Severity, r.location(f), tableCell(previewLine(f.Content)), f.Fingerprint)
+		}
+		b.WriteString("\n</details>\n")
+	}
+	if len(changed) > 0 {
+		fmt.Fprintf(&b, "\n### Changed prompts\n")
+		for _, c := range changed {
+			diff := UnifiedDiff(c.Base.Filepath, c.Finding.Filepath, c.Base.Content, c.Finding.Content)
+			fence := markdownFence(diff)
+			// Markdown is not rendered inside <summary>, so the location is plain HTML there.
+			fmt.Fprintf(&b, "\n<details><summary><code>%s:%d</code></summary>\n\n", html.EscapeString(c.Finding.Filepath), c.Finding.Line)
+			if c.Finding.Permalink != "" {
+				fmt.Fprintf(&b, "%s\n\n", r.location(c.Finding))
+			}
+			fmt.Fprintf(&b, "%sdiff\n%s%s\n\n</details>\n", fence, diff, fence)
+		}
+	}
+	if len(removed) > 0 {
+		fmt.Fprintf(&b, "\n### Removed prompts\n\n")
+		for _, f := range removed {
+			fmt.Fprintf(&b, "- `%s:%d` %s\n", f.Filepath, f.Line, previewLine(f.Content))
+		}
+	}
+	if len(added) > 0 || len(changed) > 0 {
+		fmt.Fprintf(&b, "\n<details><summary>Suppressing findings</summary>\n\n"+
+			"Add a `prompt-scanner:ignore` comment on the line of a string, or on the line above it, to stop reporting it. "+
+			"Config keys can be skipped with `--ignore-keys`.\n\n</details>\n")
+	}
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
+
+// severityCounts renders the number of findings per severity, e.g. "1 high, 2 medium, 0 low".
+func severityCounts(summary ScanSummary) string {
+	return fmt.Sprintf("%d high, %d medium, %d low", summary.BySeverity[SeverityHigh], summary.BySeverity[SeverityMedium], summary.BySeverity[SeverityLow])
+}
+
+// location renders a finding's path and line, linked to the source when a permalink is known.
+func (r *prCommentReporter) location(f JSONOutput) string {
+	location := fmt.Sprintf("`%s:%d`", f.Filepath, f.Line)
+	if f.Permalink != "" {
+		return fmt.Sprintf("[%s](%s)", location, f.Permalink)
+	}
+	return location
+}
+
+// compareWithBaseline splits findings into new and changed ones and lists the baseline findings
+// that disappeared. Findings are matched by id; baselines written before ids existed, and prompts
+// that moved to a new id with the same text, are matched by fingerprint, so reindenting or
+// rewrapping a prompt does not make it new.
+func compareWithBaseline(findings []JSONOutput, baseline *JSONEnvelope) (added []JSONOutput, changed []prChange, removed []JSONOutput) {
+	if baseline == nil {
+		return findings, nil, nil
+	}
+	baseByID := make(map[string]JSONOutput)
+	baseContents := make(map[string]bool)
+	for _, f := range baseline.Findings {
+		if f.ID != "" {
+			baseByID[f.ID] = f
+		}
+		baseContents[PromptFingerprint(f.Content)] = true
+	}
+	seenIDs := make(map[string]bool)
+	seenContents := make(map[string]bool)
+	for _, f := range findings {
+		seenIDs[f.ID] = true
+		seenContents[PromptFingerprint(f.Content)] = true
+		base, ok := baseByID[f.ID]
+		switch {
+		case ok && base.Content != f.Content:
+			changed = append(changed, prChange{Finding: f, Base: base})
+		case !ok && !baseContents[PromptFingerprint(f.Content)]:
+			added = append(added, f)
+		}
+	}
+	for _, f := range baseline.Findings {
+		if !seenContents[PromptFingerprint(f.Content)] && (f.ID == "" || !seenIDs[f.ID]) {
+			removed = append(removed, f)
+		}
+	}
+	return added, changed, removed
+}
+
+// sortBySeverity orders findings from high to low severity, keeping file order within a level.
+func sortBySeverity(findings []JSONOutput) {
+	rank := map[string]int{SeverityHigh: 0, SeverityMedium: 1, SeverityLow: 2, "": 3}
+	sort.SliceStable(findings, func(i, j int) bool { return rank[findings[i].Severity] < rank[findings[j].Severity] })
+}
+
+// previewLine returns the first line of content, shortened to prCommentPreviewLength characters.
+func previewLine(content string) string {
+	line, _, more := strings.Cut(strings.TrimSpace(content), "\n")
+	if runes := []rune(line); len(runes) > prCommentPreviewLength {
+		line, more = string(runes[:prCommentPreviewLength]), true
+	}
+	if more {
+		line += "…"
+	}
+	return line
+}
+
+// tableCell escapes text for a Markdown table cell.
+func tableCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "`", "\\`", "<", "&lt;", ">", "&gt;").Replace(text)
+}
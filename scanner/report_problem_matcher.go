@@ -0,0 +1,70 @@
+// scanner/report_problem_matcher.go
+package scanner
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProblemMatcher is a GitHub Actions problem matcher for the problem-matcher output format.
+// Registered with "::add-matcher::<file>", it turns every output line into an annotation.
+//
+//go:embed problem-matcher.json
+var ProblemMatcher []byte
+
+// problemMatcherOwner is the owner declared in ProblemMatcher.
+const problemMatcherOwner = "prompt-scanner"
+
+func init() {
+	RegisterReporter("problem-matcher", func(opts ReporterOptions) Reporter {
+		return &problemMatcherReporter{w: opts.Writer, matcherDir: opts.MatcherDir}
+	})
+}
+
+// problemMatcherReporter streams one compiler-style line per finding:
+//
+//	prompt-scanner: path/to/file.py:12:1: warning: [high] Potential prompt: You are a helpful… [fp:537d7d2a5c0f1e9b]
+//
+// When matcherDir is set (inside GitHub Actions), ProblemMatcher is written there and registered
+// before the first finding, and unregistered after the last.
+type problemMatcherReporter struct {
+	w          io.Writer
+	matcherDir string
+	meta       ReportMeta
+}
+
+func (r *problemMatcherReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	if r.matcherDir == "" {
+		return nil
+	}
+	matcherPath := filepath.Join(r.matcherDir, "prompt-scanner-matcher.json")
+	if err := os.WriteFile(matcherPath, ProblemMatcher, 0o644); err != nil {
+		return fmt.Errorf("writing problem matcher: %w", err)
+	}
+	_, err := fmt.Fprintf(r.w, "::add-matcher::%s\n", matcherPath)
+	return err
+}
+
+func (r *problemMatcherReporter) Report(p FoundPrompt) error {
+	message := fmt.Sprintf("[%s] Potential prompt: %s", p.Severity, previewLine(p.Content))
+	if len(p.PolicyViolations) > 0 {
+		message += fmt.Sprintf(" (policy %s: %s)", p.PolicyViolations[0].Rule, p.PolicyViolations[0].Message)
+	}
+	message += fingerprintTag(p)
+	_, err := fmt.Fprintf(r.w, "prompt-scanner: %s:%d:1: warning: %s\n",
+		filepath.ToSlash(r.meta.DisplayPath(p.Filepath)), p.Line, strings.ReplaceAll(message, "\r", ""))
+	return err
+}
+
+func (r *problemMatcherReporter) Finish() error {
+	if r.matcherDir == "" {
+		return nil
+	}
+	_, err := fmt.Fprintf(r.w, "::remove-matcher owner=%s::\n", problemMatcherOwner)
+	return err
+}
@@ -0,0 +1,58 @@
+// scanner/report_quickfix.go
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	RegisterReporter("quickfix", func(opts ReporterOptions) Reporter {
+		return &quickfixReporter{w: opts.Writer}
+	})
+}
+
+// quickfixReporter streams one "file:line:col: message" line per finding, the format Vim's and
+// Neovim's default errorformat and Emacs' compilation-mode parse:
+//
+//	prompts/agent.py:12:1: warning: [high] Potential prompt: You are a helpful… [fp:537d7d2a5c0f1e9b]
+//
+// Paths are relative to the working directory the editor runs in, or absolute outside it.
+type quickfixReporter struct {
+	w    io.Writer
+	meta ReportMeta
+	cwd  string
+}
+
+func (r *quickfixReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	r.cwd, _ = os.Getwd()
+	return nil
+}
+
+func (r *quickfixReporter) Report(p FoundPrompt) error {
+	message := fmt.Sprintf("[%s] Potential prompt: %s", p.Severity, previewLine(p.Content))
+	if len(p.PolicyViolations) > 0 {
+		message += fmt.Sprintf(" (policy %s: %s)", p.PolicyViolations[0].Rule, p.PolicyViolations[0].Message)
+	}
+	message += fingerprintTag(p)
+	_, err := fmt.Fprintf(r.w, "%s:%d:1: warning: %s\n", r.path(p.Filepath), p.Line, strings.ReplaceAll(message, "\r", ""))
+	return err
+}
+
+// path names the file so that the editor can open it.
+func (r *quickfixReporter) path(path string) string {
+	// Cloned repositories are removed after the scan, so their files are named as in the repository.
+	if r.cwd == "" || r.meta.isRemote(path) {
+		return filepath.ToSlash(r.meta.DisplayPath(path))
+	}
+	if rel, err := filepath.Rel(r.cwd, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+func (r *quickfixReporter) Finish() error { return nil }
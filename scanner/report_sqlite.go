@@ -0,0 +1,310 @@
+// scanner/report_sqlite.go
+package scanner
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // Registers the "sqlite3" database/sql driver
+)
+
+// SQLiteSchemaVersion is stored in the user_version pragma of databases written by the sqlite
+// format, and is bumped when the tables below change incompatibly.
+const SQLiteSchemaVersion = 2
+
+// sqliteSchema holds one row per scan and one per finding of a scan. Timestamps are RFC 3339 UTC
+// strings and labels and the full finding are JSON. fingerprint is the PromptFingerprint of the
+// text, shared by every copy of a prompt, and baseline_fingerprint the one baselines match findings
+// at a path with, so a prompt can be followed across scans either way.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS scans (
+	id                 INTEGER PRIMARY KEY,
+	target             TEXT NOT NULL,
+	commit_sha         TEXT,
+	ref                TEXT,
+	project            TEXT,
+	team               TEXT,
+	labels             TEXT,
+	tool_version       TEXT NOT NULL,
+	heuristics_version INTEGER NOT NULL,
+	generated_at       TEXT,
+	total_findings     INTEGER NOT NULL,
+	high               INTEGER NOT NULL,
+	medium             INTEGER NOT NULL,
+	low                INTEGER NOT NULL,
+	hygiene_score      INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS findings (
+	id                   INTEGER PRIMARY KEY,
+	scan_id              INTEGER NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+	finding_id           TEXT,
+	fingerprint          TEXT NOT NULL,
+	baseline_fingerprint TEXT NOT NULL,
+	target               TEXT,
+	filepath             TEXT NOT NULL,
+	line                 INTEGER NOT NULL,
+	symbol               TEXT,
+	kind                 TEXT,
+	severity             TEXT,
+	audience             TEXT,
+	tokens               INTEGER,
+	model                TEXT,
+	provider             TEXT,
+	permalink            TEXT,
+	content              TEXT NOT NULL,
+	data                 TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS findings_scan_id ON findings(scan_id);
+CREATE INDEX IF NOT EXISTS findings_fingerprint ON findings(fingerprint);
+CREATE INDEX IF NOT EXISTS findings_baseline_fingerprint ON findings(baseline_fingerprint);
+CREATE INDEX IF NOT EXISTS scans_target ON scans(target, generated_at);
+`
+
+func init() {
+	RegisterReporter("sqlite", func(opts ReporterOptions) Reporter {
+		return &sqliteReporter{path: opts.DatabasePath, toolVersion: opts.ToolVersion, weights: opts.Weights}
+	})
+}
+
+// sqliteReporter buffers findings and adds the scan and its findings to a SQLite database,
+// creating it when needed. Earlier scans are kept, so one database holds the history of many.
+type sqliteReporter struct {
+	path        string
+	toolVersion string
+	weights     SeverityWeights
+	meta        ReportMeta
+	prompts     []FoundPrompt
+}
+
+func (r *sqliteReporter) Start(meta ReportMeta) error {
+	if r.path == "" {
+		return errors.New("the sqlite format needs a database file to write to")
+	}
+	r.meta = meta
+	return nil
+}
+
+func (r *sqliteReporter) Report(p FoundPrompt) error {
+	r.prompts = append(r.prompts, p)
+	return nil
+}
+
+func (r *sqliteReporter) Finish() error {
+	db, err := sql.Open("sqlite3", "file:"+r.path+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", r.path, err)
+	}
+	defer db.Close()
+	if err := migrateSQLite(db); err != nil {
+		return fmt.Errorf("failed to prepare %s: %w", r.path, err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", r.path, err)
+	}
+	defer tx.Rollback()
+	if err := r.insert(tx, BuildEnvelope(r.meta, r.toolVersion, r.prompts, r.weights)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", r.path, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", r.path, err)
+	}
+	return nil
+}
+
+// sqliteMigrations upgrade a database from the schema version of their index to the next, inside
+// a transaction.
+var sqliteMigrations = []func(tx *sql.Tx) error{
+	1: migrateSQLiteV1,
+}
+
+// migrateSQLiteV1 renames the fingerprint column of version 1, which held the baseline fingerprint,
+// and adds the prompt fingerprint, computed from the content of each finding like new scans do.
+func migrateSQLiteV1(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE findings RENAME COLUMN fingerprint TO baseline_fingerprint;
+ALTER TABLE findings ADD COLUMN fingerprint TEXT NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS findings_fingerprint;`); err != nil {
+		return err
+	}
+	rows, err := tx.Query("SELECT id, content FROM findings")
+	if err != nil {
+		return err
+	}
+	fingerprints := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var content string
+		if err := rows.Scan(&id, &content); err != nil {
+			rows.Close()
+			return err
+		}
+		fingerprints[id] = PromptFingerprint(content)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare("UPDATE findings SET fingerprint = ? WHERE id = ?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for id, fingerprint := range fingerprints {
+		if _, err := stmt.Exec(fingerprint, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateSQLite creates the tables of an empty database, upgrades databases written with an
+// older schema version and refuses newer ones.
+func migrateSQLite(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > SQLiteSchemaVersion {
+		return fmt.Errorf("database schema version %d, this build writes version %d", version, SQLiteSchemaVersion)
+	}
+	if version > 0 {
+		for v := version; v < SQLiteSchemaVersion; v++ {
+			if err := migrateSQLiteStep(db, v); err != nil {
+				return fmt.Errorf("upgrading from schema version %d: %w", v, err)
+			}
+		}
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return err
+	}
+	_, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SQLiteSchemaVersion))
+	return err
+}
+
+// migrateSQLiteStep runs the migration from version v and records v+1, or leaves the database
+// unchanged if it fails.
+func migrateSQLiteStep(db *sql.DB, v int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := sqliteMigrations[v](tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", v+1)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insert adds the scan of envelope and its findings.
+func (r *sqliteReporter) insert(tx *sql.Tx, envelope JSONEnvelope) error {
+	var labels, generatedAt any
+	if len(envelope.Labels) > 0 {
+		data, err := json.Marshal(envelope.Labels)
+		if err != nil {
+			return err
+		}
+		labels = string(data)
+	}
+	if !envelope.GeneratedAt.IsZero() {
+		generatedAt = envelope.GeneratedAt.Format(time.RFC3339)
+	}
+	summary := envelope.Summary
+	result, err := tx.Exec(`INSERT INTO scans (target, commit_sha, ref, project, team, labels, tool_version, heuristics_version,
+		generated_at, total_findings, high, medium, low, hygiene_score) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		envelope.Target, nullString(envelope.Commit), nullString(envelope.Ref), nullString(envelope.Project), nullString(envelope.Team), labels,
+		envelope.Tool.Version, envelope.Tool.HeuristicsVersion, generatedAt, summary.TotalFindings,
+		summary.BySeverity[SeverityHigh], summary.BySeverity[SeverityMedium], summary.BySeverity[SeverityLow], summary.HygieneScore)
+	if err != nil {
+		return err
+	}
+	scanID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO findings (scan_id, finding_id, fingerprint, baseline_fingerprint, target, filepath, line, symbol,
+		kind, severity, audience, tokens, model, provider, permalink, content, data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, f := range envelope.Findings {
+		data, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		_, err = stmt.Exec(scanID, nullString(f.ID), PromptFingerprint(f.Content), baselineFingerprint(filepath.ToSlash(f.Filepath), f.Content), nullString(f.Target), f.Filepath, f.Line,
+			nullString(f.Symbol), nullString(f.Kind), nullString(f.Severity), nullString(f.Audience), f.Tokens,
+			nullString(f.Model), nullString(f.Provider), nullString(f.Permalink), f.Content, string(data))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nullString stores empty strings as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// ReadSQLiteHistory loads the scans stored in a database written by the sqlite format as history
+// records, oldest first, so that trends can be built from a database as well as a history file.
+func ReadSQLiteHistory(path string) ([]HistoryRecord, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	defer db.Close()
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return nil, fmt.Errorf("failed to read database %s: %w", path, err)
+	}
+	if version == 0 || version > SQLiteSchemaVersion {
+		return nil, fmt.Errorf("%s: database schema version %d, this build reads versions 1 to %d", path, version, SQLiteSchemaVersion)
+	}
+	rows, err := db.Query(`SELECT target, commit_sha, project, team, labels, generated_at, total_findings, high, medium, low, hygiene_score
+		FROM scans ORDER BY generated_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database %s: %w", path, err)
+	}
+	defer rows.Close()
+
+	var records []HistoryRecord
+	for rows.Next() {
+		var record HistoryRecord
+		var commit, project, team, labels, generatedAt sql.NullString
+		var high, medium, low int
+		if err := rows.Scan(&record.Target, &commit, &project, &team, &labels, &generatedAt,
+			&record.Summary.TotalFindings, &high, &medium, &low, &record.Summary.HygieneScore); err != nil {
+			return nil, fmt.Errorf("failed to read database %s: %w", path, err)
+		}
+		record.Commit, record.Project, record.Team = commit.String, project.String, team.String
+		if labels.Valid {
+			if err := json.Unmarshal([]byte(labels.String), &record.Labels); err != nil {
+				return nil, fmt.Errorf("%s: invalid labels of a scan of %s: %w", path, record.Target, err)
+			}
+		}
+		if generatedAt.Valid {
+			if record.RecordedAt, err = time.Parse(time.RFC3339, generatedAt.String); err != nil {
+				return nil, fmt.Errorf("%s: invalid time of a scan of %s: %w", path, record.Target, err)
+			}
+		}
+		record.Summary.BySeverity = map[string]int{SeverityHigh: high, SeverityMedium: medium, SeverityLow: low}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read database %s: %w", path, err)
+	}
+	return records, nil
+}
@@ -0,0 +1,48 @@
+// scanner/report_sqlite_test.go
+package scanner
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestReadSQLiteHistory reads back the scans the sqlite format wrote as history records.
+func TestReadSQLiteHistory(t *testing.T) {
+	root := t.TempDir()
+	dbPath := filepath.Join(t.TempDir(), "results.db")
+	prompts := schemaFixture(root)
+	for _, target := range []string{"https://github.com/owner/repo", "https://github.com/owner/other"} {
+		reporter, err := NewReporter("sqlite", ReporterOptions{DatabasePath: dbPath, ToolVersion: "1.0.0"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		meta := ReportMeta{Target: target, Root: root, Project: "assistant", Labels: map[string]string{"env": "ci"}}
+		if err := ReportAll(reporter, meta, prompts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := ReadSQLiteHistory(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[1].Target != "https://github.com/owner/other" || records[1].RecordedAt.Before(records[0].RecordedAt) {
+		t.Errorf("records are not oldest first: %+v", records)
+	}
+	want := Summarize(prompts, nil)
+	got := records[0]
+	if got.Target != "https://github.com/owner/repo" || got.RecordedAt.IsZero() || got.Project != "assistant" || got.Labels["env"] != "ci" {
+		t.Errorf("unexpected record metadata: %+v", got)
+	}
+	if got.Summary.TotalFindings != want.TotalFindings || got.Summary.HygieneScore != want.HygieneScore ||
+		got.Summary.BySeverity[SeverityHigh] != want.BySeverity[SeverityHigh] || got.Summary.BySeverity[SeverityLow] != want.BySeverity[SeverityLow] {
+		t.Errorf("summary = %+v, want %+v", got.Summary, want)
+	}
+
+	if _, err := ReadSQLiteHistory(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("expected an error for a missing database")
+	}
+}
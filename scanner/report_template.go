@@ -0,0 +1,86 @@
+// scanner/report_template.go
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+func init() {
+	RegisterReporter("template", func(opts ReporterOptions) Reporter {
+		return &templateReporter{w: opts.Writer, tmpl: opts.Template, toolVersion: opts.ToolVersion, weights: opts.Weights}
+	})
+}
+
+// templateFuncs are available to output templates in addition to the text/template builtins.
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"preview": previewLine,
+	"join":    strings.Join,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"replace": strings.ReplaceAll,
+	"indent": func(spaces int, s string) string {
+		pad := strings.Repeat(" ", spaces)
+		return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+	},
+}
+
+// ParseOutputTemplate reads a text/template file for the template output format. The template is
+// executed once with a JSONEnvelope, so it can range over .Findings and use .Summary, .Target,
+// .Commit and the other envelope fields, plus the functions json, preview, join, upper, lower,
+// replace and indent.
+func ParseOutputTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading template %s: %w", path, err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template %s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// templateReporter buffers findings and renders them through a user-supplied template.
+type templateReporter struct {
+	w           io.Writer
+	tmpl        *template.Template
+	toolVersion string
+	weights     SeverityWeights
+	envelope    JSONEnvelope
+	meta        ReportMeta
+	prompts     []FoundPrompt
+}
+
+func (r *templateReporter) Start(meta ReportMeta) error {
+	if r.tmpl == nil {
+		return errors.New("the template format needs a template file")
+	}
+	r.meta = meta
+	r.envelope = newEnvelope(meta, r.toolVersion)
+	return nil
+}
+
+func (r *templateReporter) Report(p FoundPrompt) error {
+	r.envelope.Findings = append(r.envelope.Findings, r.meta.JSONFinding(p))
+	r.prompts = append(r.prompts, p)
+	return nil
+}
+
+func (r *templateReporter) Finish() error {
+	r.envelope.Summary = Summarize(r.prompts, r.weights)
+	if err := r.tmpl.Execute(r.w, r.envelope); err != nil {
+		return fmt.Errorf("executing template: %w", err)
+	}
+	return nil
+}
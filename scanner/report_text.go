@@ -0,0 +1,119 @@
+// scanner/report_text.go
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Multiline rendering modes for the text output.
+const (
+	MultilineIndent   = "indent"   // Continuation lines are indented to align under the first line's content
+	MultilineCollapse = "collapse" // Lines are joined with single spaces
+	MultilineEscape   = "escape"   // Newlines and tabs are written as \n and \t escapes
+)
+
+// MultilineModes lists the accepted values of ReporterOptions.Multiline.
+var MultilineModes = []string{MultilineIndent, MultilineCollapse, MultilineEscape}
+
+func init() {
+	RegisterReporter("text", func(opts ReporterOptions) Reporter {
+		return &textReporter{
+			w:      opts.Writer,
+			layout: textLayout{multiline: opts.Multiline, noFilepath: opts.NoFilepath, noLinenumber: opts.NoLinenumber},
+		}
+	})
+}
+
+// textReporter streams findings as text lines.
+type textReporter struct {
+	w      io.Writer
+	layout textLayout
+	meta   ReportMeta
+}
+
+func (r *textReporter) Start(meta ReportMeta) error {
+	r.meta = meta
+	return nil
+}
+
+func (r *textReporter) Report(p FoundPrompt) error {
+	content := p.Content
+	if p.Rejected {
+		content = "[rejected: " + p.RejectReason + "] " + content
+	}
+	return r.layout.render(r.w, r.meta.DisplayPath(p.Filepath), p.Line, p.Fingerprint, content)
+}
+
+func (r *textReporter) Finish() error { return nil }
+
+// textLayout renders findings as "prefix<TAB>content" lines. The prefix (path and/or line number)
+// never contains a raw tab or newline, so the first tab on every output line separates prefix and
+// content regardless of the file names involved.
+type textLayout struct {
+	multiline    string
+	noFilepath   bool
+	noLinenumber bool
+}
+
+// escapeControl makes s safe for a single output line by escaping backslashes and control whitespace.
+var escapeControl = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// prefix builds the location prefix for a finding, escaping control characters in the path. The
+// fingerprint follows the location as "[fp:…]"; lines without a location hold only the content.
+func (l textLayout) prefix(displayPath string, line int, fingerprint string) string {
+	var prefixParts []string
+	if !l.noFilepath {
+		prefixParts = append(prefixParts, escapeControl.Replace(displayPath))
+	}
+	if !l.noLinenumber {
+		prefixParts = append(prefixParts, fmt.Sprintf("%d", line))
+	}
+	prefix := strings.Join(prefixParts, ":")
+	if prefix != "" && fingerprint != "" {
+		prefix += " [fp:" + fingerprint + "]"
+	}
+	return prefix
+}
+
+// render writes one finding.
+func (l textLayout) render(w io.Writer, displayPath string, line int, fingerprint, content string) error {
+	prefix := l.prefix(displayPath, line, fingerprint)
+	fullPrefixWithTab := ""
+	if prefix != "" {
+		fullPrefixWithTab = prefix + "\t"
+	}
+
+	normalizedContent := strings.ReplaceAll(content, "\r\n", "\n")
+	normalizedContent = strings.TrimRight(normalizedContent, "\n")
+
+	switch l.multiline {
+	case MultilineEscape:
+		_, err := fmt.Fprintf(w, "%s%s\n", fullPrefixWithTab, escapeControl.Replace(normalizedContent))
+		return err
+	case MultilineCollapse:
+		_, err := fmt.Fprintf(w, "%s%s\n", fullPrefixWithTab, strings.Join(strings.Fields(normalizedContent), " "))
+		return err
+	}
+
+	lines := strings.Split(normalizedContent, "\n")
+	if _, err := fmt.Fprintf(w, "%s%s\n", fullPrefixWithTab, lines[0]); err != nil {
+		return err
+	}
+
+	indentation := ""
+	if fullPrefixWithTab != "" {
+		// Pad by the prefix's display width (wide runes count double) so the tab that follows lands
+		// on the same tab stop as the first line's separator.
+		indentation = strings.Repeat(" ", runewidth.StringWidth(prefix)) + "\t"
+	}
+	for i := 1; i < len(lines); i++ {
+		if _, err := fmt.Fprintf(w, "%s%s\n", indentation, lines[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+// scanner/reporter.go
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/alexferrari88/prompt-scanner/utils"
+)
+
+// Reporter receives scan results and renders them in some output format.
+// Start is called once before any finding, Report once per finding and Finish once at the end.
+// Reporters may write as they go (streaming) or buffer until Finish.
+type Reporter interface {
+	Start(meta ReportMeta) error
+	Report(p FoundPrompt) error
+	Finish() error
+}
+
+// ReportMeta describes the scan whose results are being reported.
+type ReportMeta struct {
+	Target     string    // The scanned path or repository URL, for display
+	Root       string    // Directory finding paths are shown relative to; empty keeps them as-is
+	RepoWebURL string    // Web URL of the scanned GitHub repository, empty for local scans
+	Commit     string    // Commit SHA checked out for the scan, empty if unknown
+	Ref        string    // Branch, tag or SHA requested for the scan, empty for the default branch
+	StartedAt  time.Time // When the scan started
+	// Project, Team and Labels tag every finding and the envelope so that reports from many
+	// teams can be segmented in a central store. All are optional.
+	Project string
+	Team    string
+	Labels  map[string]string
+	// Reproducible leaves timestamps out of reports (the envelope's generated_at is the zero
+	// time), so two scans of the same tree give byte-identical output.
+	Reproducible bool
+	Hashes       *ScanHashes // Recorded in the envelope when set
+	// Targets describes each target of a run that scans several, in command-line order. Findings
+	// are tagged with the target whose Root holds them and their paths shown under the target's
+	// name; Root, RepoWebURL and Commit are then unused.
+	Targets []ScanTarget
+}
+
+// ScanTarget is one of several targets scanned in a single run.
+type ScanTarget struct {
+	Target     string // The path or repository URL as given
+	Root       string // Directory the target was scanned in, a clone for repository URLs, or the scanned file
+	RepoWebURL string // Web URL of the repository, empty for local targets
+	Commit     string // Commit SHA checked out for the scan, empty if unknown
+}
+
+// Name returns the prefix the target's finding paths are shown under: the path as given, or the
+// host and path of a repository URL.
+func (t ScanTarget) Name() string {
+	if t.RepoWebURL != "" {
+		return filepath.FromSlash(strings.TrimPrefix(strings.TrimPrefix(t.RepoWebURL, "https://"), "http://"))
+	}
+	return filepath.Clean(t.Target)
+}
+
+// target returns the scan target holding path, the one with the longest Root containing it.
+func (m ReportMeta) target(path string) (ScanTarget, bool) {
+	var best ScanTarget
+	found := false
+	for _, t := range m.Targets {
+		rel, err := filepath.Rel(t.Root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if !found || len(t.Root) > len(best.Root) {
+			best, found = t, true
+		}
+	}
+	return best, found
+}
+
+// DisplayPath returns path relative to Root when possible. In runs with several targets it is
+// relative to its target's root, under the target's name.
+func (m ReportMeta) DisplayPath(path string) string {
+	if len(m.Targets) > 0 {
+		if t, ok := m.target(path); ok {
+			if relPath, err := filepath.Rel(t.Root, path); err == nil {
+				return filepath.Join(t.Name(), relPath)
+			}
+		}
+		return path
+	}
+	if m.Root == "" {
+		return path
+	}
+	if relPath, err := filepath.Rel(m.Root, path); err == nil {
+		return relPath
+	}
+	return path
+}
+
+// isRemote reports whether path was scanned in a clone of a remote repository.
+func (m ReportMeta) isRemote(path string) bool {
+	if len(m.Targets) > 0 {
+		t, ok := m.target(path)
+		return ok && t.RepoWebURL != ""
+	}
+	return m.RepoWebURL != ""
+}
+
+// Permalink returns a commit-pinned URL to the finding's lines, or "" when the scan wasn't of a
+// remote repository at a known commit.
+func (m ReportMeta) Permalink(p FoundPrompt) string {
+	if len(m.Targets) > 0 {
+		t, ok := m.target(p.Filepath)
+		if !ok {
+			return ""
+		}
+		m = ReportMeta{Root: t.Root, RepoWebURL: t.RepoWebURL, Commit: t.Commit}
+	}
+	if m.RepoWebURL == "" || m.Commit == "" {
+		return ""
+	}
+	endLine := p.EndLine
+	if endLine == 0 {
+		endLine = p.Line + utils.CountNewlines(p.Content)
+	}
+	return utils.GitHubPermalink(m.RepoWebURL, m.Commit, m.DisplayPath(p.Filepath), p.Line, endLine)
+}
+
+// JSONFinding converts a finding to its serialized form.
+func (m ReportMeta) JSONFinding(p FoundPrompt) JSONOutput {
+	return JSONOutput{
+		ID:          p.ID,
+		Fingerprint: p.Fingerprint,
+		Filepath:    m.DisplayPath(p.Filepath),
+		Line:        p.Line,
+		Content:     p.Content,
+		Permalink:   m.Permalink(p),
+		Symbol:      p.Symbol,
+		Severity:    p.Severity,
+		Audience:    p.Audience,
+		Kind:        p.Kind,
+		Marked:      p.Marked,
+		Slots:       p.Slots,
+
+		Rejected:     p.Rejected,
+		RejectReason: p.RejectReason,
+
+		OutputContracts: p.OutputContracts,
+		Variables:       p.Variables,
+		Lints:           p.Lints,
+
+		Embedded:         p.Embedded,
+		Tokens:           p.Tokens,
+		Model:            p.Model,
+		Provider:         p.Provider,
+		PolicyViolations: p.PolicyViolations,
+
+		Target:  m.findingTarget(p),
+		Project: m.Project,
+		Team:    m.Team,
+		Labels:  m.Labels,
+	}
+}
+
+// findingTarget returns the target p was found in, or "" for runs with a single target.
+func (m ReportMeta) findingTarget(p FoundPrompt) string {
+	if t, ok := m.target(p.Filepath); ok {
+		return t.Target
+	}
+	return ""
+}
+
+// ReporterOptions configures a Reporter created through the registry.
+// Each format uses the options relevant to it and ignores the rest.
+type ReporterOptions struct {
+	Writer       io.Writer
+	NoFilepath   bool               // text: omit the file path
+	NoLinenumber bool               // text: omit the line number
+	Multiline    string             // text: MultilineIndent, MultilineCollapse or MultilineEscape
+	SnippetLines int                // markdown/html: lines of source context around each finding
+	ToolVersion  string             // envelope: version recorded in the tool block
+	Weights      SeverityWeights    // envelope, pr-comment: severity weights for the hygiene score (nil uses defaults)
+	Baseline     *JSONEnvelope      // pr-comment: report of the base branch to compare findings with
+	MatcherDir   string             // problem-matcher: directory to write and register the GitHub problem matcher in; empty skips registration
+	Template     *template.Template // template: parsed with ParseOutputTemplate
+	DatabasePath string             // sqlite: database file the scan is added to; Writer is unused
+}
+
+// ReporterFactory creates a Reporter for the given options.
+type ReporterFactory func(opts ReporterOptions) Reporter
+
+var (
+	reportersMu sync.RWMutex
+	reporters   = make(map[string]ReporterFactory)
+)
+
+// RegisterReporter makes an output format available under name, replacing any existing
+// registration with the same name.
+func RegisterReporter(name string, factory ReporterFactory) {
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+	reporters[name] = factory
+}
+
+// NewReporter creates the Reporter registered under name.
+func NewReporter(name string, opts ReporterOptions) (Reporter, error) {
+	reportersMu.RLock()
+	factory, ok := reporters[name]
+	reportersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown output format '%s'", name)
+	}
+	return factory(opts), nil
+}
+
+// ReporterNames returns the registered output format names, sorted.
+func ReporterNames() []string {
+	reportersMu.RLock()
+	defer reportersMu.RUnlock()
+	names := make([]string, 0, len(reporters))
+	for name := range reporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReportAll drives r over a complete result set.
+func ReportAll(r Reporter, meta ReportMeta, prompts []FoundPrompt) error {
+	if err := r.Start(meta); err != nil {
+		return err
+	}
+	for _, p := range prompts {
+		if err := r.Report(p); err != nil {
+			return err
+		}
+	}
+	return r.Finish()
+}
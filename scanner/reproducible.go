@@ -0,0 +1,116 @@
+// scanner/reproducible.go
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+// ScanHashes identify the detection logic behind a report, so that an attested report can be
+// checked against a rerun with the same grammars and rules.
+type ScanHashes struct {
+	// Grammars hashes the tree-sitter module version and the queries run against each grammar.
+	Grammars string `json:"grammars"`
+	// Ruleset hashes the options that decide which strings are reported: thresholds, keyword and
+	// placeholder sets, scanned file kinds, language and path overrides and policy.
+	Ruleset string `json:"ruleset"`
+}
+
+// Hashes returns the ScanHashes of the scanner's configured options.
+func (s *Scanner) Hashes() ScanHashes {
+	return ScanHashes{Grammars: GrammarHash(), Ruleset: s.RulesetHash()}
+}
+
+// GrammarHash returns a hex SHA-256 of the tree-sitter dependency versions the binary was built
+// with and of the queries run against each grammar.
+func GrammarHash() string {
+	h := sha256.New()
+	if info, ok := debug.ReadBuildInfo(); ok {
+		var deps []string
+		for _, dep := range info.Deps {
+			if strings.Contains(dep.Path, "tree-sitter") {
+				deps = append(deps, dep.Path+"@"+dep.Version+" "+dep.Sum)
+			}
+		}
+		sort.Strings(deps)
+		for _, dep := range deps {
+			fmt.Fprintf(h, "%s\n", dep)
+		}
+	}
+	langs := make([]string, 0, len(rawLangToQueries))
+	for lang := range rawLangToQueries {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		fmt.Fprintf(h, "%s\x00%s\x00", lang, rawLangToQueries[lang])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RulesetHash returns a hex SHA-256 of the options that decide which strings are reported.
+// Options that only affect how a scan runs or is reported, such as Verbose or Progress, are left
+// out.
+func (s *Scanner) RulesetHash() string {
+	o := s.Options
+	ruleset := struct {
+		MinLength              int
+		VariableKeywords       []string
+		ContentKeywords        []string
+		PlaceholderPatterns    []string
+		ScanConfigs            bool
+		ConstantsFiles         bool
+		ScanText               bool
+		ScanMinified           bool
+		PromptFilenamePatterns []string
+		Mode                   string
+		UseGitignore           bool
+		MultilineOnly          bool
+		MinLines               int
+		IgnoreKeys             []string
+		LintOnly               bool
+		QualityLints           bool
+		IncludeRejected        bool
+		Policy                 *Policy
+		LanguageOverrides      map[string]LanguageOverride
+		PathOverrides          []PathOverride
+		Adaptive               bool
+		Include                []string
+		Exclude                []string
+		MaxNestingDepth        int
+		NoDefaultExcludes      bool
+	}{
+		o.MinLength, o.VariableKeywords, o.ContentKeywords, o.PlaceholderPatterns,
+		o.ScanConfigs, o.ConstantsFiles, o.ScanText, o.ScanMinified, o.PromptFilenamePatterns,
+		o.preset().Name, o.UseGitignore, o.MultilineOnly, o.MinLines, o.IgnoreKeys,
+		o.LintOnly, o.QualityLints, o.IncludeRejected, o.Policy, o.LanguageOverrides,
+		o.PathOverrides, o.Adaptive, o.Include, o.Exclude, o.MaxNestingDepth, o.NoDefaultExcludes,
+	}
+	// Maps are marshalled with sorted keys, so equal options always give the same hash.
+	data, err := json.Marshal(ruleset)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%#v", ruleset))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SortFindings orders prompts by path, then line, so that reports do not depend on the order in
+// which the scan workers finished their files.
+func SortFindings(prompts []FoundPrompt) {
+	sort.SliceStable(prompts, func(i, j int) bool {
+		a, b := prompts[i], prompts[j]
+		if a.Filepath != b.Filepath {
+			return a.Filepath < b.Filepath
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Content < b.Content
+	})
+}
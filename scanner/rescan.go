@@ -0,0 +1,46 @@
+// scanner/rescan.go
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ReadFindings reads the findings of a report written in the json, ndjson or envelope format.
+func ReadFindings(path string) ([]JSONOutput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+	if trimmed[0] == '[' {
+		var findings []JSONOutput
+		if err := json.Unmarshal(trimmed, &findings); err != nil {
+			return nil, fmt.Errorf("failed to parse %s as a json report: %w", path, err)
+		}
+		return findings, nil
+	}
+	var envelope JSONEnvelope
+	if err := json.Unmarshal(trimmed, &envelope); err == nil && envelope.SchemaVersion != "" {
+		return envelope.Findings, nil
+	}
+	var findings []JSONOutput
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		var finding JSONOutput
+		if err := decoder.Decode(&finding); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse %s as a json, ndjson or envelope report: %w", path, err)
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
@@ -0,0 +1,75 @@
+// scanner/scaffold.go
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Finding kinds. Findings without a more specific kind are plain prompts and leave Kind empty.
+const (
+	KindRAGScaffold = "rag_scaffold" // Retrieval-augmented template wiring retrieved context and a question together
+)
+
+var (
+	// slotPatterns capture template slot names: {name}, {{ name }}, ${name}, $name, {{{name}}}, <name>.
+	slotPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\{\{\{?\s*\.?([A-Za-z_][\w.]*)\s*\}?\}\}`),
+		regexp.MustCompile(`\$\{\s*([A-Za-z_][\w.]*)\s*\}`),
+		regexp.MustCompile(`(?:^|[^{$])\{\s*([A-Za-z_][\w.]*)\s*(?:![rsa])?(?::[^{}]*)?\}`),
+		regexp.MustCompile(`\$([A-Za-z_]\w*)`),
+		regexp.MustCompile(`<([A-Za-z_][\w]*)>`),
+	}
+
+	ragInstructionPattern = regexp.MustCompile(`(?i)(use the (following|provided|given|retrieved) (pieces of )?(context|documents?|sources|passages|information)|` +
+		`(answer|respond)[^.\n]{0,60}\b(based|only|solely)\b[^.\n]{0,20}\bon the (provided |given |following |retrieved )?(context|documents?|sources|passages)|` +
+		`given the (following )?(context|documents?|sources|passages)|` +
+		`if (the answer is not|you can't find the answer|you don't know)[^.\n]{0,40}(context|documents?)|` +
+		`^\s*(context|documents?|sources|passages)\s*:)`)
+	ragContextSlots  = map[string]bool{"context": true, "contexts": true, "documents": true, "document": true, "docs": true, "sources": true, "chunks": true, "passages": true, "retrieved": true, "summaries": true, "context_str": true}
+	ragQuestionSlots = map[string]bool{"question": true, "query": true, "input": true, "query_str": true, "user_question": true, "question_text": true}
+)
+
+// extractSlots returns the distinct template slot names in text, in order of appearance.
+func extractSlots(text string) []string {
+	type slotMatch struct {
+		pos  int
+		name string
+	}
+	var matches []slotMatch
+	for _, re := range slotPatterns {
+		for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+			matches = append(matches, slotMatch{pos: m[2], name: text[m[2]:m[3]]})
+		}
+	}
+	// Sort by position with a simple insertion sort; slot counts are small.
+	for i := 1; i < len(matches); i++ {
+		for j := i; j > 0 && matches[j].pos < matches[j-1].pos; j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+	seen := make(map[string]bool)
+	var slots []string
+	for _, m := range matches {
+		if !seen[m.name] {
+			seen[m.name] = true
+			slots = append(slots, m.name)
+		}
+	}
+	return slots
+}
+
+// classifyKind recognizes RAG scaffolds: templates that tell the model to answer from supplied
+// context, or that have both a context slot and a question slot.
+func classifyKind(text string, slots []string) string {
+	hasContextSlot, hasQuestionSlot := false, false
+	for _, slot := range slots {
+		name := strings.ToLower(slot[strings.LastIndex(slot, ".")+1:])
+		hasContextSlot = hasContextSlot || ragContextSlots[name]
+		hasQuestionSlot = hasQuestionSlot || ragQuestionSlots[name]
+	}
+	if (hasContextSlot && hasQuestionSlot) || (ragInstructionPattern.MatchString(text) && (hasContextSlot || hasQuestionSlot)) {
+		return KindRAGScaffold
+	}
+	return ""
+}
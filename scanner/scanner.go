@@ -3,31 +3,76 @@ package scanner
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/alexferrari88/prompt-scanner/utils"
+	"github.com/bmatcuk/doublestar/v4"
 	gitignore "github.com/sabhiram/go-gitignore"
+	"golang.org/x/sync/errgroup"
 )
 
+// defaultNumWorkers is the number of scan workers when ScanOptions.Workers is 0.
 var defaultNumWorkers = runtime.NumCPU()
 
+// ScannerIgnoreFile is the name of the exclusion file read from the root of a scanned directory.
+// It uses .gitignore syntax and is always honored, unlike .gitignore files (see
+// ScanOptions.UseGitignore), so fixtures and generated code can be excluded permanently.
+const ScannerIgnoreFile = ".promptscannerignore"
+
 // Scanner orchestrates the scanning process.
 type Scanner struct {
 	Options        ScanOptions
 	gitIgnoreCache map[string]gitignore.IgnoreParser // Key: absolute path to directory containing .gitignore
 	cacheMutex     sync.Mutex
+
+	languageOptions map[string]*ScanOptions // Effective options per LanguageOverrides key
+	pathOptions     []*ScanOptions          // Effective options per PathOverrides entry, nil when it skips
+	rootDir         string                  // Directory being scanned, for path-based heuristics
+	fsys            fs.FS                   // File system being scanned by ScanFS; nil scans the disk
+	parsers         []registeredParser      // Parsers added with RegisterParser, in order
+	constantsFile   bool                    // Parsing a constants file (see isConstantsFile)
+	pragmas         filePragmas             // Pragma comments of the file being parsed
+	grammars        *grammarCache           // Warm tree-sitter parsers of the worker parsing the file
+	ctx             context.Context         // Scan the file is parsed for; nil parses without cancellation
+	tracing         bool                    // The candidate being evaluated matches TraceLocation
+	nesting         []string                // Embedded code being parsed, outermost first (see parseEmbeddedCode)
+
+	// observe, when set, is called for every candidate string with the heuristics' verdict.
+	observe func(ctx PromptContext, fp FoundPrompt, accepted bool)
+
+	skippedLarge    atomic.Int64 // Files skipped for exceeding MaxFileSize
+	skippedMinified atomic.Int64 // Minified JavaScript files skipped without ScanMinified
+	timedOutMu      sync.Mutex
+	timedOut        []string // Files whose parsing exceeded ParseTimeout
 }
 
 // New creates a new Scanner instance.
 func New(options ScanOptions) (*Scanner, error) {
+	if options.Mode != "" {
+		if _, err := LookupPreset(options.Mode); err != nil {
+			return nil, err
+		}
+	}
+	for _, pattern := range append(slices.Clip(options.Include), options.Exclude...) {
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid include or exclude glob '%s'", pattern)
+		}
+	}
 	if err := options.compileMatchers(); err != nil {
 		return nil, fmt.Errorf("failed to compile matchers: %w", err)
 	}
@@ -35,6 +80,12 @@ func New(options ScanOptions) (*Scanner, error) {
 		Options:        options,
 		gitIgnoreCache: make(map[string]gitignore.IgnoreParser),
 	}
+	if err := s.compileLanguageOptions(options.LanguageOverrides); err != nil {
+		return nil, fmt.Errorf("failed to compile matchers: %w", err)
+	}
+	if err := s.compilePathOptions(options.PathOverrides); err != nil {
+		return nil, fmt.Errorf("failed to compile matchers: %w", err)
+	}
 	if !utils.CommandExists("git") && options.Verbose {
 		// This log is already conditional due to options.Verbose
 		log.Println("Warning: 'git' command not found in PATH. GitHub URL cloning might be affected if not using a shallow clone mechanism that relies on it, though direct cloning often still works.")
@@ -50,13 +101,13 @@ func (s *Scanner) isIgnored(path string, rootDir string) (bool, error) {
 		return false, nil
 	}
 
-	absPath, err := filepath.Abs(path)
+	absPath, err := s.absPath(path)
 	if err != nil {
 		return false, fmt.Errorf("isIgnored: failed to get absolute path for target %s: %w", path, err)
 	}
 
 	var currentSearchDir string
-	fi, statErr := os.Stat(absPath)
+	fi, statErr := s.stat(absPath)
 	if statErr != nil {
 		currentSearchDir = filepath.Dir(absPath)
 	} else {
@@ -66,12 +117,12 @@ func (s *Scanner) isIgnored(path string, rootDir string) (bool, error) {
 			currentSearchDir = filepath.Dir(absPath)
 		}
 	}
-	currentSearchDir, err = filepath.Abs(currentSearchDir)
+	currentSearchDir, err = s.absPath(currentSearchDir)
 	if err != nil {
 		return false, fmt.Errorf("isIgnored: failed to get absolute path for search base %s: %w", filepath.Dir(absPath), err)
 	}
 
-	absRootDir, err := filepath.Abs(rootDir)
+	absRootDir, err := s.absPath(rootDir)
 	if err != nil {
 		return false, fmt.Errorf("isIgnored: failed to get absolute path for rootDir %s: %w", rootDir, err)
 	}
@@ -88,7 +139,7 @@ func (s *Scanner) isIgnored(path string, rootDir string) (bool, error) {
 		s.cacheMutex.Unlock()
 
 		if !foundInCache {
-			compiledIgnorer, compileErr := gitignore.CompileIgnoreFile(gitIgnoreFilePath)
+			compiledIgnorer, compileErr := s.compileIgnoreFile(gitIgnoreFilePath)
 			if compileErr != nil {
 				if s.Options.Verbose {
 					log.Printf("Warning: Error compiling .gitignore file %s: %v. It will be skipped.", gitIgnoreFilePath, compileErr)
@@ -125,46 +176,182 @@ func (s *Scanner) isIgnored(path string, rootDir string) (bool, error) {
 	return false, nil
 }
 
+// loadScannerIgnore compiles the ScannerIgnoreFile at the root of rootDir, or returns nil when
+// there is none.
+func (s *Scanner) loadScannerIgnore(rootDir string) gitignore.IgnoreParser {
+	ignorePath := filepath.Join(rootDir, ScannerIgnoreFile)
+	if _, err := s.stat(ignorePath); err != nil {
+		return nil
+	}
+	ignorer, err := s.compileIgnoreFile(ignorePath)
+	if err != nil {
+		log.Printf("Warning: Error reading %s: %v. It will be skipped.", ignorePath, err)
+		return nil
+	}
+	return ignorer
+}
+
+// isScannerIgnored reports whether path matches the ScannerIgnoreFile patterns of rootDir.
+// Directories are matched with a trailing slash so that "fixtures/" patterns prune them.
+func isScannerIgnored(ignorer gitignore.IgnoreParser, rootDir, path string, isDir bool) bool {
+	rel, err := filepath.Rel(rootDir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	if isDir {
+		rel += "/"
+	}
+	return ignorer.MatchesPath(rel)
+}
+
+// reportProgress forwards ev to the Progress callback, if any.
+func (s *Scanner) reportProgress(ev ProgressEvent) {
+	if s.Options.Progress != nil {
+		s.Options.Progress(ev)
+	}
+}
+
 // ScanDirectory recursively scans a directory for prompts.
 func (s *Scanner) ScanDirectory(rootDir string) ([]FoundPrompt, error) {
+	return s.ScanDirectoryContext(context.Background(), rootDir)
+}
+
+// ScanDirectoryContext scans a directory like ScanDirectory until ctx is done. A cancelled scan
+// returns the prompts of the files scanned so far along with ctx's error.
+func (s *Scanner) ScanDirectoryContext(ctx context.Context, rootDir string) ([]FoundPrompt, error) {
 	var allPrompts []FoundPrompt
-	var wg sync.WaitGroup
-	filesToProcess := make(chan string, defaultNumWorkers*2)     // Buffered channel
-	resultsChan := make(chan []FoundPrompt, defaultNumWorkers*2) // Buffered channel
-	var mu sync.Mutex                                            // Mutex for allPrompts slice
-
-	for i := 0; i < defaultNumWorkers; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-			for filePath := range filesToProcess {
-				promptsFromFile, err := s.processFile(filePath)
-				if err != nil {
-					if s.Options.Verbose {
-						log.Printf("Worker %d: Error processing file %q: %v\n", workerID, filePath, err)
-					}
+	err := s.ScanDirectoryFuncContext(ctx, rootDir, func(prompts []FoundPrompt) error {
+		allPrompts = append(allPrompts, prompts...)
+		return nil
+	})
+	return allPrompts, err
+}
+
+// Scan recursively scans root until ctx is done, handing each finding to fn as soon as the file
+// holding it is scanned, in the order of the file. fn is never called concurrently. If fn returns an
+// error, the scan stops and that error is returned; a cancelled scan returns ctx's error.
+func (s *Scanner) Scan(ctx context.Context, root string, fn func(FoundPrompt) error) error {
+	return s.ScanDirectoryFuncContext(ctx, root, func(prompts []FoundPrompt) error {
+		for _, p := range prompts {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ScanDirectoryFunc recursively scans a directory like ScanDirectory, but hands each file's
+// prompts to fn as soon as the file is scanned instead of collecting them. fn is never called
+// concurrently. If fn returns an error, the scan stops and that error is returned.
+func (s *Scanner) ScanDirectoryFunc(rootDir string, fn func(prompts []FoundPrompt) error) error {
+	return s.ScanDirectoryFuncContext(context.Background(), rootDir, fn)
+}
+
+// ScanDirectoryFuncContext is ScanDirectoryFunc until ctx is done: the walk and the workers stop,
+// parses in progress are abandoned and ctx's error is returned once every file already handed to
+// fn has been.
+func (s *Scanner) ScanDirectoryFuncContext(ctx context.Context, rootDir string, fn func(prompts []FoundPrompt) error) error {
+	return s.scanDirectory(ctx, rootDir, fn)
+}
+
+// scanDirectory runs the scan pipeline: one goroutine walks rootDir and shards the files it finds
+// across numWorkers workers by language (see shardDispatcher), the workers parse them and
+// one collector passes their prompts to fn. All of them run in an
+// errgroup, so the first error (a failed walk, fn failing or ctx being cancelled) stops every
+// stage and scanDirectory only returns once they have all exited. Errors parsing a single file
+// are not fatal; they are logged in verbose mode and reported through Progress.
+func (s *Scanner) scanDirectory(ctx context.Context, rootDir string, fn func(prompts []FoundPrompt) error) error {
+	s.rootDir = rootDir
+	if s.Options.Adaptive {
+		profile, err := s.profileDirectory(ctx, rootDir)
+		if err != nil {
+			return err
+		}
+		if err := s.adaptThresholds(profile); err != nil {
+			return err
+		}
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	numWorkers := s.Options.Workers
+	if numWorkers <= 0 {
+		numWorkers = defaultNumWorkers
+	}
+	shards := newShardDispatcher(numWorkers)
+	resultsChan := make(chan []FoundPrompt, numWorkers*2)
+
+	g.Go(func() error {
+		defer shards.close()
+		err := s.walkFiles(ctx, rootDir, func(path, lang string) error {
+			s.reportProgress(ProgressEvent{Kind: FileQueued, Filepath: path, Language: lang})
+			return shards.dispatch(ctx, path, lang)
+		})
+		if s.Options.Verbose {
+			log.Printf("Scan sharding: %s", shards)
+		}
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("error walking directory %s: %w", rootDir, err)
+		}
+		return err
+	})
+
+	workers, workerCtx := errgroup.WithContext(ctx)
+	for i := 0; i < numWorkers; i++ {
+		workerID := i
+		workers.Go(func() error {
+			grammars := newGrammarCache()
+			defer grammars.close()
+			for file := range shards.queues[workerID] {
+				if err := workerCtx.Err(); err != nil {
+					return err
+				}
+				promptsFromFile, err := s.processFile(workerCtx, file.path, file.lang, grammars)
+				if err != nil && s.Options.Verbose {
+					log.Printf("Worker %d: Error processing file %q: %v\n", workerID, file.path, err)
 				}
-				if len(promptsFromFile) > 0 {
-					resultsChan <- promptsFromFile
+				s.reportProgress(ProgressEvent{Kind: FileScanned, Filepath: file.path, Language: file.lang, Findings: promptsFromFile, Err: err})
+				if len(promptsFromFile) == 0 {
+					continue
+				}
+				select {
+				case resultsChan <- promptsFromFile:
+				case <-workerCtx.Done():
+					return workerCtx.Err()
 				}
 			}
-		}(i)
+			return nil
+		})
 	}
+	g.Go(func() error {
+		defer close(resultsChan)
+		return workers.Wait()
+	})
 
-	// Goroutine to collect results
-	var collectWg sync.WaitGroup
-	collectWg.Add(1)
-	go func() {
-		defer collectWg.Done()
+	g.Go(func() error {
 		for promptsSlice := range resultsChan {
-			mu.Lock()
-			allPrompts = append(allPrompts, promptsSlice...)
-			mu.Unlock()
+			if err := fn(promptsSlice); err != nil {
+				return err
+			}
 		}
-	}()
+		return nil
+	})
+	return g.Wait()
+}
 
-	// Walk the directory
-	walkErr := filepath.WalkDir(rootDir, func(path string, d os.DirEntry, err error) error {
+// walkFiles calls fn for every file under rootDir that is scanned with the current options,
+// skipping paths matched by ScannerIgnoreFile or .gitignore, hidden and common non-source
+// directories. The walk stops with the error of fn, or of ctx once it is done.
+func (s *Scanner) walkFiles(ctx context.Context, rootDir string, fn func(path, lang string) error) error {
+	scannerIgnore := s.loadScannerIgnore(rootDir)
+	if s.Options.OnlyFiles != nil {
+		return s.walkOnlyFiles(ctx, rootDir, scannerIgnore, fn)
+	}
+	return s.walkDir(rootDir, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
 			if s.Options.Verbose {
 				log.Printf("Warning: Error accessing path %q: %v\n", path, err)
@@ -174,111 +361,534 @@ func (s *Scanner) ScanDirectory(rootDir string) ([]FoundPrompt, error) {
 			}
 			return nil
 		}
-
-		absRootDir, rootErr := filepath.Abs(rootDir)
-		if rootErr != nil {
-			if s.Options.Verbose {
-				log.Printf("Warning: Could not get absolute path for rootDir %s: %v. Gitignore may not work correctly.", rootDir, rootErr)
+		if s.skipsPath(scannerIgnore, rootDir, path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
 			}
-			absRootDir = rootDir
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		return s.visitFile(path, fn)
+	})
+}
+
+// skipsPath reports whether the walk of rootDir skips path: a path matched by ScannerIgnoreFile
+// or .gitignore, or a hidden or common non-source directory.
+func (s *Scanner) skipsPath(scannerIgnore gitignore.IgnoreParser, rootDir, path string, isDir bool) bool {
+	if scannerIgnore != nil && isScannerIgnored(scannerIgnore, rootDir, path, isDir) {
+		if s.Options.Verbose {
+			log.Printf("Skipping path due to %s: %s\n", ScannerIgnoreFile, path)
+		}
+		return true
+	}
+
+	absRootDir, rootErr := s.absPath(rootDir)
+	if rootErr != nil {
+		if s.Options.Verbose {
+			log.Printf("Warning: Could not get absolute path for rootDir %s: %v. Gitignore may not work correctly.", rootDir, rootErr)
+		}
+		absRootDir = rootDir
+	}
+
+	if ignored, gitignoreErr := s.isIgnored(path, absRootDir); gitignoreErr != nil {
+		if s.Options.Verbose {
+			log.Printf("Warning: Error checking .gitignore for path %q: %v. Path will be processed.\n", path, gitignoreErr)
 		}
+	} else if ignored {
+		if s.Options.Verbose {
+			log.Printf("Skipping path due to .gitignore: %s\n", path)
+		}
+		return true
+	}
 
-		if ignored, gitignoreErr := s.isIgnored(path, absRootDir); gitignoreErr != nil {
+	if isDir {
+		dirName := filepath.Base(path)
+		if dirName == ".git" || dirName == "node_modules" || dirName == "vendor" ||
+			dirName == "dist" || dirName == "build" || dirName == "target" ||
+			dirName == "tmp" || dirName == "temp" || dirName == "__pycache__" ||
+			dirName == ".venv" || dirName == "venv" || dirName == "env" ||
+			dirName == ".next" || dirName == ".nuxt" || dirName == ".svelte-kit" {
 			if s.Options.Verbose {
-				log.Printf("Warning: Error checking .gitignore for path %q: %v. Path will be processed.\n", path, gitignoreErr)
+				log.Printf("Skipping common non-source directory: %s\n", path)
 			}
-		} else if ignored {
+			return true
+		}
+		if strings.HasPrefix(dirName, ".") && len(dirName) > 1 && dirName != ".config" && dirName != ".github" {
 			if s.Options.Verbose {
-				log.Printf("Skipping path due to .gitignore: %s\n", path)
+				log.Printf("Skipping hidden directory: %s\n", path)
 			}
-			if d.IsDir() {
-				return filepath.SkipDir
+			return true
+		}
+		if s.isExcludedDir(path) {
+			if s.Options.Verbose {
+				log.Printf("Skipping directory matched by an exclude glob: %s\n", path)
 			}
-			return nil
+			return true
 		}
+	}
+	return false
+}
 
-		if d.IsDir() {
-			dirName := d.Name()
-			if dirName == ".git" || dirName == "node_modules" || dirName == "vendor" ||
-				dirName == "dist" || dirName == "build" || dirName == "target" ||
-				dirName == "tmp" || dirName == "temp" || dirName == "__pycache__" ||
-				dirName == ".venv" || dirName == "venv" || dirName == "env" ||
-				dirName == ".next" || dirName == ".nuxt" || dirName == ".svelte-kit" {
-				if s.Options.Verbose {
-					log.Printf("Skipping common non-source directory: %s\n", path)
-				}
-				return filepath.SkipDir
-			}
-			if strings.HasPrefix(dirName, ".") && len(dirName) > 1 && dirName != ".config" && dirName != ".github" {
-				if s.Options.Verbose {
-					log.Printf("Skipping hidden directory: %s\n", path)
-				}
-				return filepath.SkipDir
+// visitFile calls fn for a file the walk did not skip, unless its language is unknown or it is
+// excluded by SkipFiles, the Include and Exclude globs or a path override.
+func (s *Scanner) visitFile(path string, fn func(path, lang string) error) error {
+	lang := s.fileLanguage(path)
+	if lang == "" {
+		return nil
+	}
+	if s.isFilteredOut(path) {
+		if s.Options.Verbose {
+			log.Printf("Skipping file due to the include and exclude globs: %s\n", path)
+		}
+		return nil
+	}
+	if s.isSkippedFile(path) {
+		if s.Options.Verbose {
+			log.Printf("Skipping file listed in SkipFiles: %s\n", path)
+		}
+		return nil
+	}
+	if s.isPathSkipped(path) {
+		if s.Options.Verbose {
+			log.Printf("Skipping file due to a skipping path override: %s\n", path)
+		}
+		return nil
+	}
+	return fn(path, lang)
+}
+
+// walkOnlyFiles calls fn for the files of OnlyFiles that exist and would be scanned by a walk of
+// rootDir: files under skipped directories or matched by the ignore files are left out.
+func (s *Scanner) walkOnlyFiles(ctx context.Context, rootDir string, scannerIgnore gitignore.IgnoreParser, fn func(path, lang string) error) error {
+	seen := make(map[string]bool)
+	for _, file := range s.Options.OnlyFiles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		path := filepath.FromSlash(file)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(rootDir, path)
+		}
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if info, err := s.stat(path); err != nil || info.IsDir() {
+			if s.Options.Verbose {
+				log.Printf("Skipping %s listed in OnlyFiles: not a file\n", path)
 			}
-			return nil
+			continue
 		}
+		if s.skipsListedFile(scannerIgnore, rootDir, path) {
+			continue
+		}
+		if err := s.visitFile(path, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		filesToProcess <- path
-		return nil
-	})
+// skipsListedFile reports whether a walk of rootDir would skip path itself or one of the
+// directories between rootDir and path. Files outside rootDir are only checked themselves.
+func (s *Scanner) skipsListedFile(scannerIgnore gitignore.IgnoreParser, rootDir, path string) bool {
+	if rel, err := filepath.Rel(rootDir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		parts := strings.Split(rel, string(filepath.Separator))
+		dir := rootDir
+		for _, part := range parts[:len(parts)-1] {
+			dir = filepath.Join(dir, part)
+			if s.skipsPath(scannerIgnore, rootDir, dir, true) {
+				return true
+			}
+		}
+	}
+	return s.skipsPath(scannerIgnore, rootDir, path, false)
+}
 
-	close(filesToProcess)
-	wg.Wait()
-	close(resultsChan)
-	collectWg.Wait()
+// isSkippedFile reports whether path is one of ScanOptions.SkipFiles.
+func (s *Scanner) isSkippedFile(path string) bool {
+	if len(s.Options.SkipFiles) == 0 {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, skip := range s.Options.SkipFiles {
+		if absSkip, err := filepath.Abs(skip); err == nil && absSkip == absPath {
+			return true
+		}
+	}
+	return false
+}
 
-	if walkErr != nil {
-		return allPrompts, fmt.Errorf("error walking directory %s: %w", rootDir, walkErr)
+// isFilteredOut reports whether the Include and Exclude globs, or DefaultExcludeList, leave out
+// the file at path.
+func (s *Scanner) isFilteredOut(path string) bool {
+	if len(s.Options.Include) == 0 && len(s.Options.Exclude) == 0 && s.Options.NoDefaultExcludes {
+		return false
+	}
+	relPath := s.relativePath(path)
+	if !s.Options.NoDefaultExcludes && matchesAnyGlob(DefaultExcludeList, relPath) {
+		return true
+	}
+	if len(s.Options.Include) > 0 && !matchesAnyGlob(s.Options.Include, relPath) {
+		return true
 	}
-	return allPrompts, nil
+	return matchesAnyGlob(s.Options.Exclude, relPath)
 }
 
-// processFile determines the file type and calls the appropriate parser.
-func (s *Scanner) processFile(filePath string) ([]FoundPrompt, error) {
-	ext := strings.ToLower(filepath.Ext(filePath))
-	fileName := strings.ToLower(filepath.Base(filePath))
+// isExcludedDir reports whether an Exclude glob ending in "/**" matches the directory at path, so
+// that nothing under it can be scanned. The scanned root itself is never excluded.
+func (s *Scanner) isExcludedDir(path string) bool {
+	if len(s.Options.Exclude) == 0 || filepath.Clean(path) == filepath.Clean(s.rootDir) {
+		return false
+	}
+	relPath := s.relativePath(path)
+	for _, pattern := range s.Options.Exclude {
+		if dirPattern, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if matched, _ := doublestar.Match(dirPattern, relPath); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
 
-	contentBytes, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
+// matchesAnyGlob reports whether relPath matches one of the doublestar patterns.
+func matchesAnyGlob(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := doublestar.Match(pattern, relPath); matched {
+			return true
+		}
 	}
-	if len(contentBytes) == 0 {
-		return nil, nil
+	return false
+}
+
+// fileLanguage returns the language or config format processFile uses for filePath, or "" if the
+// file isn't scanned with the current options. Extensionless files are opened to sniff their
+// language, so callers look it up once per file and pass it along.
+func (s *Scanner) fileLanguage(filePath string) string {
+	return s.languageOf(filePath, func() []byte { return s.readHead(filePath) })
+}
+
+// contentLanguage is fileLanguage for a file whose content is already read, such as a buffer
+// given to ScanReader, which may differ from the file on disk or have none.
+func (s *Scanner) contentLanguage(filePath string, contentBytes []byte) string {
+	return s.languageOf(filePath, func() []byte { return contentBytes })
+}
+
+// languageOf implements fileLanguage; head returns the start of the file's content and is only
+// called for extensionless files.
+func (s *Scanner) languageOf(filePath string, head func() []byte) string {
+	if lang := s.registeredLanguage(filePath); lang != "" {
+		return lang
 	}
+	ext := strings.ToLower(filepath.Ext(filePath))
+	fileName := strings.ToLower(filepath.Base(filePath))
 
 	switch ext {
 	case ".go":
-		return s.ParseGoFile(filePath, contentBytes)
+		return "go"
 	case ".py":
-		return s.ParseTreeSitterFile(filePath, contentBytes, "python")
+		return "python"
 	case ".js", ".jsx":
-		return s.ParseTreeSitterFile(filePath, contentBytes, "javascript")
+		return "javascript"
 	case ".ts", ".tsx":
-		return s.ParseTreeSitterFile(filePath, contentBytes, "typescript")
+		return "typescript"
+	case ".rb":
+		return "ruby"
+	case ".java":
+		return "java"
+	case ".php":
+		return "php"
+	case ".sh", ".bash":
+		return "bash"
+	case ".html", ".htm":
+		return "html"
+	}
+	// Scripts such as bin/deploy name their language on the first line.
+	if ext == "" {
+		if lang := sniffLanguage(head()); lang != "" {
+			return lang
+		}
 	}
 
-	if s.Options.ScanConfigs {
+	if s.Options.ScanText && isTextPromptFile(filePath) {
+		return "text"
+	}
+	// Config files are scanned with -scan-configs, or when their name strongly suggests prompts.
+	if s.Options.ScanConfigs || s.matchesPromptFilename(fileName) {
 		if strings.HasPrefix(fileName, ".env") {
-			return s.ParseEnvFile(filePath, contentBytes)
+			return "env"
 		}
 		switch ext {
-		case ".json":
-			return s.ParseJSONFile(filePath, contentBytes)
+		case ".json", ".jsonc", ".json5":
+			return "json"
 		case ".yaml", ".yml":
-			return s.ParseYAMLFile(filePath, contentBytes)
+			return "yaml"
 		case ".toml":
-			return s.ParseTOMLFile(filePath, contentBytes)
+			return "toml"
+		case ".xml":
+			return "xml"
+		case ".tf", ".hcl":
+			return "hcl"
+		}
+	}
+	return ""
+}
+
+// isConfigLanguage reports whether lang is a config or plain-text format rather than source code.
+func isConfigLanguage(lang string) bool {
+	switch lang {
+	case "env", "json", "yaml", "toml", "xml", "hcl", "html", "text":
+		return true
+	}
+	return false
+}
+
+// matchesPromptFilename reports whether a lowercased file name matches PromptFilenamePatterns.
+func (s *Scanner) matchesPromptFilename(fileName string) bool {
+	for _, pattern := range s.Options.PromptFilenamePatterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), fileName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isTooLarge reports whether the file at filePath exceeds MaxFileSize.
+func (s *Scanner) isTooLarge(filePath string) bool {
+	if s.Options.MaxFileSize <= 0 {
+		return false
+	}
+	info, err := s.stat(filePath)
+	return err == nil && info.Size() > s.Options.MaxFileSize
+}
+
+// SkippedLargeFiles returns how many files the scanner has skipped so far for exceeding
+// MaxFileSize.
+func (s *Scanner) SkippedLargeFiles() int {
+	return int(s.skippedLarge.Load())
+}
+
+// skipsMinified reports whether the file is minified or bundled JavaScript that ScanMinified is
+// off for.
+func (s *Scanner) skipsMinified(filePath, lang string, contentBytes []byte) bool {
+	return !s.Options.ScanMinified && (lang == "javascript" || lang == "typescript") && isMinified(filePath, contentBytes)
+}
+
+// SkippedMinifiedFiles returns how many minified or bundled JavaScript files the scanner has
+// skipped so far because ScanMinified is off.
+func (s *Scanner) SkippedMinifiedFiles() int {
+	return int(s.skippedMinified.Load())
+}
+
+// TimedOutFiles returns the files the scanner has given up parsing so far because they took
+// longer than ParseTimeout, sorted.
+func (s *Scanner) TimedOutFiles() []string {
+	s.timedOutMu.Lock()
+	defer s.timedOutMu.Unlock()
+	files := slices.Clone(s.timedOut)
+	sort.Strings(files)
+	return files
+}
+
+// ScanFile scans a single file, whatever the ignore files and the include and exclude globs say.
+// It returns no findings for files in a language the scanner does not handle.
+func (s *Scanner) ScanFile(filePath string) ([]FoundPrompt, error) {
+	return s.processFile(context.Background(), filePath, s.fileLanguage(filePath), nil)
+}
+
+// ScanReader scans content read from r, such as an unsaved editor buffer, as a file named
+// displayName. lang is a language or config format as in ProgressEvent.Language ("python",
+// "yaml", ...); when empty, it is taken from the extension of displayName, or for names without
+// one from the shebang of the content. Findings point to displayName.
+func (s *Scanner) ScanReader(r io.Reader, lang, displayName string) ([]FoundPrompt, error) {
+	contentBytes, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", displayName, err)
+	}
+	if lang == "" {
+		lang = s.contentLanguage(displayName, contentBytes)
+		if lang == "" {
+			return nil, fmt.Errorf("cannot tell the language of %s; pass it explicitly", displayName)
+		}
+	}
+	if s.Options.MaxFileSize > 0 && int64(len(contentBytes)) > s.Options.MaxFileSize {
+		s.skippedLarge.Add(1)
+		return nil, nil
+	}
+	return s.processContent(context.Background(), displayName, lang, contentBytes, nil)
+}
+
+// processFile reads the file at filePath, in lang as returned by fileLanguage, and calls the
+// appropriate parser.
+func (s *Scanner) processFile(ctx context.Context, filePath, lang string, grammars *grammarCache) ([]FoundPrompt, error) {
+	if lang == "" {
+		return nil, nil
+	}
+
+	if s.isTooLarge(filePath) {
+		s.skippedLarge.Add(1)
+		if s.Options.Verbose {
+			log.Printf("Skipping file larger than %d bytes: %s\n", s.Options.MaxFileSize, filePath)
 		}
+		return nil, nil
+	}
+	contentBytes, err := s.readFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
-	return nil, nil
+	return s.processContent(ctx, filePath, lang, contentBytes, grammars)
 }
 
-// CloneRepo clones a public GitHub repository to a temporary directory.
+// processContent parses the content of a file in lang and completes its findings.
+func (s *Scanner) processContent(ctx context.Context, filePath, lang string, contentBytes []byte, grammars *grammarCache) ([]FoundPrompt, error) {
+	if len(contentBytes) == 0 {
+		return nil, nil
+	}
+	if isBinary(contentBytes) {
+		if s.Options.Verbose {
+			log.Printf("Skipping binary file: %s\n", filePath)
+		}
+		return nil, nil
+	}
+	if s.skipsMinified(filePath, lang, contentBytes) {
+		s.skippedMinified.Add(1)
+		if s.Options.Verbose {
+			log.Printf("Skipping minified file: %s\n", filePath)
+		}
+		return nil, nil
+	}
+
+	parser := s.fileParser(filePath, lang, contentBytes)
+	parser.grammars = grammars
+	parser.ctx = ctx
+	var rejected []FoundPrompt
+	var literals []IndexedLiteral
+	if s.Options.IncludeRejected || s.Options.Index != nil {
+		observe := parser.observe
+		parser.observe = func(ctx PromptContext, fp FoundPrompt, accepted bool) {
+			if observe != nil {
+				observe(ctx, fp, accepted)
+			}
+			if s.Options.Index != nil {
+				literals = append(literals, IndexedLiteral{Line: fp.Line, EndLine: fp.EndLine, VariableName: ctx.VariableName, Content: fp.Content, Prompt: accepted})
+			}
+			if s.Options.IncludeRejected && !accepted {
+				fp.Rejected = true
+				rejected = append(rejected, fp)
+			}
+		}
+	}
+	prompts, err := parser.parseContent(filePath, lang, contentBytes)
+	if errors.Is(err, ErrParseTimeout) {
+		s.timedOutMu.Lock()
+		s.timedOut = append(s.timedOut, filePath)
+		s.timedOutMu.Unlock()
+	}
+	if s.Options.Index != nil {
+		s.Options.Index.add(filePath, s.relativePath(filePath), literals)
+	}
+	// Variable types and declared input variables come from code elsewhere in the file, so they
+	// are resolved once the whole file has been parsed.
+	kept := prompts[:0]
+	for _, p := range prompts {
+		p.Variables = inferVariables(p, contentBytes)
+		p.Lints = lintPrompt(p, contentBytes)
+		if s.Options.QualityLints {
+			p.Lints = append(p.Lints, qualityLints(p.Content)...)
+		}
+		p.Fingerprint = PromptFingerprint(p.Content)
+		p.Tokens = s.Options.countTokens(p.Content)
+		p.Model, p.Provider = inferModel(contentBytes, p.Line)
+		if s.Options.Policy != nil {
+			p.PolicyViolations = s.Options.Policy.Evaluate(p)
+		}
+		if s.Options.LintOnly && len(p.Lints) == 0 {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	assignFindingIDs(s.relativePath(filePath), kept)
+	if s.Options.AnonymousStats != nil {
+		s.Options.AnonymousStats.add(s.Options.preset().Name, lang, kept, parser.pragmas)
+	}
+	kept = s.Options.Baseline.NewFindings(s.rootDir, kept)
+	if len(rejected) > 0 {
+		kept = append(kept, rejected...)
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].Line < kept[j].Line })
+	}
+	return kept, err
+}
+
+// context returns the context of the scan the file is parsed for.
+func (s *Scanner) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// fileParser returns a scanner holding the per-file state for parsing one file. Workers parse
+// files concurrently, so this state cannot live on s itself.
+func (s *Scanner) fileParser(filePath, lang string, contentBytes []byte) *Scanner {
+	parser := &Scanner{Options: s.forFile(filePath, lang).Options, rootDir: s.rootDir, observe: s.observe, ctx: s.ctx, parsers: s.parsers}
+	parser.constantsFile = s.Options.ConstantsFiles && !isConfigLanguage(lang) && isConstantsFile(contentBytes)
+	parser.pragmas = parsePragmas(contentBytes)
+	return parser
+}
+
+// parseContent runs the parser for lang over a file's content.
+func (s *Scanner) parseContent(filePath, lang string, contentBytes []byte) ([]FoundPrompt, error) {
+	if parser := s.registeredParser(lang); parser != nil {
+		return parser.Parse(s, filePath, contentBytes)
+	}
+	switch lang {
+	case "go":
+		return s.ParseGoFile(filePath, contentBytes)
+	case "env":
+		return s.ParseEnvFile(filePath, contentBytes)
+	case "json":
+		return s.ParseJSONFile(filePath, contentBytes)
+	case "yaml":
+		return s.ParseYAMLFile(filePath, contentBytes)
+	case "toml":
+		return s.ParseTOMLFile(filePath, contentBytes)
+	case "xml":
+		return s.ParseXMLFile(filePath, contentBytes)
+	case "hcl":
+		return s.ParseHCLFile(filePath, contentBytes)
+	case "html":
+		return s.ParseHTMLFile(filePath, contentBytes)
+	case "text":
+		return s.ParseTextFile(filePath, contentBytes)
+	default:
+		return s.ParseTreeSitterFile(filePath, contentBytes, lang)
+	}
+}
+
+// CloneRepo clones a public GitHub repository to a temporary directory under Options.TempDir.
 func (s *Scanner) CloneRepo(url string) (string, error) {
+	return s.CloneRepoAtRef(url, "")
+}
+
+// CloneRepoAtRef clones a public GitHub repository to a temporary directory and checks out ref
+// (a branch, tag or commit SHA). An empty ref clones the default branch.
+func (s *Scanner) CloneRepoAtRef(url, ref string) (string, error) {
+	return s.CloneRepoAtRefContext(context.Background(), url, ref)
+}
+
+// CloneRepoAtRefContext is CloneRepoAtRef, killing git and removing the partial clone if ctx is
+// done first.
+func (s *Scanner) CloneRepoAtRefContext(ctx context.Context, url, ref string) (string, error) {
 	if !utils.CommandExists("git") {
 		return "", fmt.Errorf("'git' command not found in PATH. Cannot clone repository. Please install git or ensure it's in your system's PATH")
 	}
-	tempDir, err := os.MkdirTemp("", "prompt-scan-repo-")
+	tempDir, err := os.MkdirTemp(s.Options.TempDir, "prompt-scan-repo-")
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %w", err)
 	}
@@ -287,13 +897,29 @@ func (s *Scanner) CloneRepo(url string) (string, error) {
 		log.Printf("Cloning %s into %s...", url, tempDir)
 	}
 
-	cmd := exec.Command("git", "clone", "--depth", "1", url, tempDir)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		_ = os.RemoveAll(tempDir)
-		return "", fmt.Errorf("failed to clone repo '%s' (git command exit status: %s): %w. Stderr: %s", url, cmd.ProcessState.String(), err, stderr.String())
+	var commands [][]string
+	if ref == "" {
+		commands = [][]string{{"clone", "--depth", "1", url, tempDir}}
+	} else {
+		// Fetching a single ref works for branches, tags and (on GitHub) full commit SHAs alike.
+		commands = [][]string{
+			{"-C", tempDir, "init", "--quiet"},
+			{"-C", tempDir, "remote", "add", "origin", url},
+			{"-C", tempDir, "fetch", "--depth", "1", "origin", ref},
+			{"-C", tempDir, "checkout", "--quiet", "FETCH_HEAD"},
+		}
+	}
+	for _, args := range commands {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			_ = os.RemoveAll(tempDir)
+			if ctx.Err() != nil {
+				return "", fmt.Errorf("failed to clone repo '%s': %w", url, ctx.Err())
+			}
+			return "", fmt.Errorf("failed to clone repo '%s' (git command exit status: %s): %w. Stderr: %s", url, cmd.ProcessState.String(), err, stderr.String())
+		}
 	}
 
 	if s.Options.Verbose {
@@ -301,3 +927,21 @@ func (s *Scanner) CloneRepo(url string) (string, error) {
 	}
 	return tempDir, nil
 }
+
+// HeadCommit returns the full SHA of the commit checked out in repoDir.
+func (s *Scanner) HeadCommit(repoDir string) (string, error) {
+	return s.ResolveRef(repoDir, "HEAD")
+}
+
+// ResolveRef returns the full SHA of the commit ref (a branch, tag or expression such as HEAD~1)
+// names in the local repository repoDir.
+func (s *Scanner) ResolveRef(repoDir, ref string) (string, error) {
+	cmd := exec.Command("git", "-C", repoDir, "rev-parse", "--verify", ref+"^{commit}")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("resolving %s in %s: %w. Stderr: %s", ref, repoDir, err, stderr.String())
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,13 @@
+// scanner/schema.go
+package scanner
+
+import _ "embed"
+
+// SchemaVersion is the version of the JSON output contract described by OutputSchema.
+// It is bumped whenever a field is added, removed or changes meaning.
+const SchemaVersion = "1.2.0"
+
+// OutputSchema is the JSON Schema (draft 2020-12) describing the JSON array, NDJSON line and envelope outputs.
+//
+//go:embed schema/output.schema.json
+var OutputSchema []byte
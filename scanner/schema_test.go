@@ -0,0 +1,117 @@
+// scanner/schema_test.go
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// schemaFixture returns findings that between them set every field of the JSON output, so that a
+// field added to the structs but not to the schema (or the other way round) fails validation.
+func schemaFixture(root string) []FoundPrompt {
+	content := "Answer the {question} using the context below.\nRespond in JSON.\n\nContext:\n{context}"
+	return []FoundPrompt{
+		{
+			ID:               "agent.SYSTEM_PROMPT",
+			Filepath:         filepath.Join(root, "app", "agent.py"),
+			Line:             12,
+			EndLine:          16,
+			Content:          content,
+			Symbol:           "agent.Agent.run",
+			Severity:         SeverityHigh,
+			Audience:         AudienceModel,
+			Kind:             KindRAGScaffold,
+			Marked:           true,
+			Slots:            []string{"question", "context"},
+			OutputContracts:  []OutputContract{{Format: "json", Line: 2, Text: "Respond in JSON."}},
+			Variables:        []TemplateVariable{{Name: "question", Type: "string"}, {Name: "context", Type: "array"}},
+			Lints:            []Lint{{Rule: "missing-input-variable", Level: LintLevelWarning, Message: "question is not declared"}},
+			Embedded:         &EmbeddedOrigin{Language: "python", Key: "jobs.build.steps[0].run"},
+			Tokens:           21,
+			Model:            "gpt-4o",
+			Provider:         "openai",
+			PolicyViolations: []PolicyViolation{{Rule: "max_tokens", Severity: SeverityMedium, Message: "21 tokens exceed the budget of 20"}},
+			Fingerprint:      PromptFingerprint(content),
+		},
+		{
+			Filepath: filepath.Join(root, "web", "copy.ts"),
+			Line:     3,
+			Content:  "Welcome back! Click here to get started.",
+			Severity: SeverityLow,
+			Audience: AudienceHuman,
+		},
+		{
+			Filepath:     filepath.Join(root, "app", "log.py"),
+			Line:         40,
+			Content:      "failed to load the prompt",
+			Rejected:     true,
+			RejectReason: RejectLowScore,
+		},
+	}
+}
+
+// TestOutputSchema validates the json, ndjson and envelope outputs against OutputSchema.
+func TestOutputSchema(t *testing.T) {
+	loader := gojsonschema.NewSchemaLoader()
+	loader.Draft = gojsonschema.Draft7 // The schema only uses keywords draft 7 shares with 2020-12
+	loader.AutoDetect = false
+	schema, err := loader.Compile(gojsonschema.NewBytesLoader(OutputSchema))
+	if err != nil {
+		t.Fatalf("compiling the schema: %v", err)
+	}
+	root := t.TempDir()
+	meta := ReportMeta{
+		Target:     "https://github.com/owner/repo",
+		Root:       root,
+		RepoWebURL: "https://github.com/owner/repo",
+		Commit:     "0123456789abcdef0123456789abcdef01234567",
+		Ref:        "main",
+		Project:    "assistant",
+		Team:       "ml-platform",
+		Labels:     map[string]string{"env": "ci"},
+		Hashes:     &ScanHashes{Grammars: "grammars-hash", Ruleset: "ruleset-hash"},
+	}
+	prompts := schemaFixture(root)
+
+	for _, format := range []string{"json", "ndjson", "envelope"} {
+		t.Run(format, func(t *testing.T) {
+			var out bytes.Buffer
+			reporter, err := NewReporter(format, ReporterOptions{Writer: &out, ToolVersion: "1.0.0"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ReportAll(reporter, meta, prompts); err != nil {
+				t.Fatal(err)
+			}
+			documents := []string{out.String()}
+			if format == "ndjson" {
+				documents = nil
+				lines := bufio.NewScanner(&out)
+				for lines.Scan() {
+					documents = append(documents, lines.Text())
+				}
+				if len(documents) != len(prompts) {
+					t.Fatalf("expected %d lines, got %d", len(prompts), len(documents))
+				}
+			}
+			for _, doc := range documents {
+				result, err := schema.Validate(gojsonschema.NewStringLoader(doc))
+				if err != nil {
+					t.Fatalf("validating %s output: %v", format, err)
+				}
+				if !result.Valid() {
+					var errs []string
+					for _, e := range result.Errors() {
+						errs = append(errs, e.String())
+					}
+					t.Errorf("%s output does not match the schema:\n%s\n%s", format, strings.Join(errs, "\n"), doc)
+				}
+			}
+		})
+	}
+}
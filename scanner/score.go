@@ -0,0 +1,149 @@
+// scanner/score.go
+package scanner
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Severity levels assigned to findings. Higher severity means a prompt that is more important to
+// move out of code (e.g. a full system prompt rather than a short one-liner).
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// hygieneScale controls how quickly the hygiene score falls as weighted findings accumulate:
+// a total weight equal to hygieneScale halves the score.
+const hygieneScale = 50.0
+
+// SeverityWeights maps a severity to its penalty in the hygiene score.
+type SeverityWeights map[string]float64
+
+// DefaultSeverityWeights are the penalties used when none are configured.
+var DefaultSeverityWeights = SeverityWeights{
+	SeverityHigh:   10,
+	SeverityMedium: 3,
+	SeverityLow:    1,
+}
+
+// ParseSeverityWeights parses "high=10,medium=3,low=1". Severities that are not mentioned keep
+// their default weight.
+func ParseSeverityWeights(spec string) (SeverityWeights, error) {
+	weights := make(SeverityWeights, len(DefaultSeverityWeights))
+	for severity, weight := range DefaultSeverityWeights {
+		weights[severity] = weight
+	}
+	if strings.TrimSpace(spec) == "" {
+		return weights, nil
+	}
+	for _, part := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid weight '%s', expected severity=number", part)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if _, known := DefaultSeverityWeights[key]; !known {
+			return nil, fmt.Errorf("unknown severity '%s' in weights", key)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight for '%s': %s", key, value)
+		}
+		weights[key] = weight
+	}
+	return weights, nil
+}
+
+// ScanSummary aggregates a set of findings.
+type ScanSummary struct {
+	TotalFindings int            `json:"total_findings"`
+	BySeverity    map[string]int `json:"by_severity"`
+	HygieneScore  int            `json:"hygiene_score"` // 0-100, higher means fewer inline prompts
+}
+
+// Summarize counts findings per severity and computes the hygiene score. A nil weights map uses
+// DefaultSeverityWeights.
+func Summarize(prompts []FoundPrompt, weights SeverityWeights) ScanSummary {
+	counter := NewSummaryCounter(weights)
+	for _, p := range prompts {
+		counter.Add(p)
+	}
+	return counter.Summary()
+}
+
+// SummaryCounter builds a ScanSummary one finding at a time, for scans whose findings are
+// streamed rather than kept.
+type SummaryCounter struct {
+	weights SeverityWeights
+	summary ScanSummary
+	penalty float64
+}
+
+// NewSummaryCounter returns an empty counter. A nil weights map uses DefaultSeverityWeights.
+func NewSummaryCounter(weights SeverityWeights) *SummaryCounter {
+	if weights == nil {
+		weights = DefaultSeverityWeights
+	}
+	return &SummaryCounter{
+		weights: weights,
+		summary: ScanSummary{BySeverity: map[string]int{SeverityHigh: 0, SeverityMedium: 0, SeverityLow: 0}},
+	}
+}
+
+// Add counts one finding. Rejected candidates are not counted.
+func (c *SummaryCounter) Add(p FoundPrompt) {
+	if p.Rejected {
+		return
+	}
+	severity := p.Severity
+	if severity == "" {
+		severity = SeverityLow
+	}
+	c.summary.TotalFindings++
+	c.summary.BySeverity[severity]++
+	c.penalty += c.weights[severity]
+}
+
+// Summary returns the summary of the findings added so far.
+func (c *SummaryCounter) Summary() ScanSummary {
+	summary := c.summary
+	summary.BySeverity = make(map[string]int, len(c.summary.BySeverity))
+	for severity, n := range c.summary.BySeverity {
+		summary.BySeverity[severity] = n
+	}
+	summary.HygieneScore = int(math.Round(100 * hygieneScale / (hygieneScale + c.penalty)))
+	return summary
+}
+
+// assessSeverity grades an accepted finding. Role-setting or system-level prompts that are long or
+// span lines are high; other multi-line, templated or prompt-named strings are medium.
+func (s *Scanner) assessSeverity(ctx PromptContext, fp *FoundPrompt) string {
+	text := strings.ToLower(strings.TrimSpace(ctx.Text))
+	lowerVar := strings.ToLower(ctx.VariableName)
+	isRoleStatement := strings.HasPrefix(text, "you are") || strings.HasPrefix(text, "act as") ||
+		strings.Contains(lowerVar, "system")
+	isSubstantial := fp.IsMultiLine || len(text) >= 200
+
+	hasPlaceholder := fp.MatchedPlaceholder != ""
+	if !hasPlaceholder {
+		for _, re := range s.Options.compiledPlaceholders {
+			if re.MatchString(ctx.Text) {
+				hasPlaceholder = true
+				break
+			}
+		}
+	}
+
+	switch {
+	case isRoleStatement && isSubstantial:
+		return SeverityHigh
+	case isSubstantial || hasPlaceholder || fp.MatchedVariableName != "":
+		return SeverityMedium
+	default:
+		return SeverityLow
+	}
+}
@@ -0,0 +1,176 @@
+// scanner/shard.go
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// grammarCache keeps the tree-sitter parsers and compiled queries of one worker warm across
+// files. Parsers are not safe for concurrent use, so every worker owns its cache. A nil cache
+// creates and frees them per file.
+type grammarCache struct {
+	parsers map[string]*sitter.Parser
+	queries map[string]*sitter.Query
+}
+
+func newGrammarCache() *grammarCache {
+	return &grammarCache{parsers: make(map[string]*sitter.Parser), queries: make(map[string]*sitter.Query)}
+}
+
+// ErrParseTimeout is returned, wrapped, for files tree-sitter could not parse within
+// ScanOptions.ParseTimeout.
+var ErrParseTimeout = errors.New("parsing timed out")
+
+// parse parses content with the grammar lang, reusing the cache's parser for name. The parse is
+// cancelled when ctx is done or, with a positive timeout, once it has run that long.
+func (c *grammarCache) parse(ctx context.Context, name string, lang *sitter.Language, content []byte, timeout time.Duration) (*sitter.Tree, error) {
+	var parser *sitter.Parser
+	if c != nil {
+		parser = c.parsers[name]
+	}
+	if parser == nil {
+		parser = sitter.NewParser()
+		parser.SetLanguage(lang)
+		if c != nil {
+			c.parsers[name] = parser
+		} else {
+			defer parser.Close()
+		}
+	}
+	// Timeouts use tree-sitter's own limit: ParseCtx cancels from a goroutine that can outlive the
+	// call and halt the parser's next parse, which is only harmless once ctx is done for good, as
+	// the worker's parsers are then only used for the scan being cancelled.
+	parser.SetOperationLimit(int(timeout.Microseconds()))
+	tree, err := parser.ParseCtx(ctx, nil, content)
+	if err != nil {
+		// A halted parse resumes on the next call unless the parser is reset.
+		parser.Reset()
+	}
+	if errors.Is(err, sitter.ErrOperationLimit) {
+		return nil, fmt.Errorf("%w after %s", ErrParseTimeout, timeout)
+	}
+	return tree, err
+}
+
+// query returns the compiled query for name, compiling it on first use. The caller closes the
+// query only when release is true, i.e. when there is no cache to keep it in.
+func (c *grammarCache) query(name, source string, lang *sitter.Language) (q *sitter.Query, release bool, err error) {
+	if c != nil {
+		if q := c.queries[name]; q != nil {
+			return q, false, nil
+		}
+	}
+	q, err = sitter.NewQuery([]byte(source), lang)
+	if err != nil {
+		return nil, false, err
+	}
+	if c == nil {
+		return q, true, nil
+	}
+	c.queries[name] = q
+	return q, false, nil
+}
+
+// close frees the cached parsers and queries.
+func (c *grammarCache) close() {
+	for _, p := range c.parsers {
+		p.Close()
+	}
+	for _, q := range c.queries {
+		q.Close()
+	}
+}
+
+// shardQueueSize is the number of files buffered per worker.
+const shardQueueSize = 2
+
+// shardDispatcher routes files to workers by language, so each worker mostly sees a few grammars
+// and keeps their parsers warm instead of rebuilding them for every file. A file goes to the
+// least-loaded worker that has already handled its language. When all of those are backed up, an
+// idle worker takes the language on if there is one, which keeps every worker busy on
+// single-language trees; otherwise the file waits for a worker that knows its language.
+type shardDispatcher struct {
+	queues   []chan queuedFile
+	affinity map[string][]int // Language to the workers that have handled it
+}
+
+// queuedFile is a file waiting for a worker, with the language the walk found it in.
+type queuedFile struct {
+	path string
+	lang string
+}
+
+func newShardDispatcher(workers int) *shardDispatcher {
+	d := &shardDispatcher{queues: make([]chan queuedFile, workers), affinity: make(map[string][]int)}
+	for i := range d.queues {
+		d.queues[i] = make(chan queuedFile, shardQueueSize)
+	}
+	return d
+}
+
+// dispatch queues path for a worker, blocking until there is room or ctx is done. It is called
+// from the walker goroutine only.
+func (d *shardDispatcher) dispatch(ctx context.Context, path, lang string) error {
+	worker := -1
+	for _, w := range d.affinity[lang] {
+		if len(d.queues[w]) < shardQueueSize && (worker < 0 || len(d.queues[w]) < len(d.queues[worker])) {
+			worker = w
+		}
+	}
+	if worker < 0 {
+		for w := range d.queues {
+			if len(d.queues[w]) == 0 {
+				worker = w
+				d.addAffinity(lang, w)
+				break
+			}
+		}
+	}
+	if worker < 0 {
+		candidates := d.affinity[lang]
+		if len(candidates) == 0 {
+			candidates = make([]int, len(d.queues))
+			for w := range candidates {
+				candidates[w] = w
+			}
+		}
+		for _, w := range candidates {
+			if worker < 0 || len(d.queues[w]) < len(d.queues[worker]) {
+				worker = w
+			}
+		}
+		d.addAffinity(lang, worker)
+	}
+	select {
+	case d.queues[worker] <- queuedFile{path: path, lang: lang}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *shardDispatcher) addAffinity(lang string, worker int) {
+	for _, w := range d.affinity[lang] {
+		if w == worker {
+			return
+		}
+	}
+	d.affinity[lang] = append(d.affinity[lang], worker)
+}
+
+// close signals the workers that no more files are coming.
+func (d *shardDispatcher) close() {
+	for _, q := range d.queues {
+		close(q)
+	}
+}
+
+// String describes the language assignment, for verbose logs.
+func (d *shardDispatcher) String() string {
+	return fmt.Sprintf("%d workers, language affinity %v", len(d.queues), d.affinity)
+}
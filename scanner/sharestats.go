@@ -0,0 +1,154 @@
+// scanner/sharestats.go
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AnonymousStatsReport is the content-free summary of a scan that users can opt in to share with
+// the maintainers, to tune the built-in heuristics. It holds counts only: no paths, prompt text,
+// variable names, placeholders or custom keywords, which are all counted as "custom".
+type AnonymousStatsReport struct {
+	ToolVersion       string `json:"tool_version"`
+	HeuristicsVersion int    `json:"heuristics_version"`
+	Mode              string `json:"mode"`
+	Findings          int    `json:"findings"`
+	// FindingsByRule counts findings per acceptance rule: "content_keyword:you are a",
+	// "variable_keyword:prompt", "placeholder", "long_string", "constants_file", "marked" or
+	// "rag_scaffold". Greedy findings count once per signal that scored them.
+	FindingsByRule     map[string]int `json:"findings_by_rule"`
+	FindingsByLanguage map[string]int `json:"findings_by_language"`
+	FindingsBySeverity map[string]int `json:"findings_by_severity"`
+	FindingsByKind     map[string]int `json:"findings_by_kind"`
+	// FalsePositiveMarks counts, per language, the lines marked with PragmaIgnore: strings users
+	// had to tell the scanner are not prompts.
+	FalsePositiveMarks map[string]int `json:"false_positive_marks"`
+}
+
+// AnonymousStats gathers an AnonymousStatsReport over the files a scanner processes, when set as
+// ScanOptions.AnonymousStats. It is safe for concurrent use.
+type AnonymousStats struct {
+	mu     sync.Mutex
+	report AnonymousStatsReport
+}
+
+// NewAnonymousStats returns empty AnonymousStats for scans by toolVersion.
+func NewAnonymousStats(toolVersion string) *AnonymousStats {
+	return &AnonymousStats{report: AnonymousStatsReport{
+		ToolVersion:        toolVersion,
+		HeuristicsVersion:  HeuristicsVersion,
+		FindingsByRule:     make(map[string]int),
+		FindingsByLanguage: make(map[string]int),
+		FindingsBySeverity: make(map[string]int),
+		FindingsByKind:     make(map[string]int),
+		FalsePositiveMarks: make(map[string]int),
+	}}
+}
+
+// add counts the findings and pragma marks of one file in lang, scanned in mode.
+func (a *AnonymousStats) add(mode, lang string, prompts []FoundPrompt, pragmas filePragmas) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.report.Mode = mode
+	for _, pragma := range pragmas {
+		if pragma == PragmaIgnore {
+			a.report.FalsePositiveMarks[lang]++
+		}
+	}
+	for _, p := range prompts {
+		if p.Rejected {
+			continue
+		}
+		a.report.Findings++
+		a.report.FindingsByLanguage[lang]++
+		a.report.FindingsBySeverity[p.Severity]++
+		if p.Kind != "" {
+			a.report.FindingsByKind[p.Kind]++
+		}
+		for _, rule := range findingRules(p) {
+			a.report.FindingsByRule[rule]++
+		}
+	}
+}
+
+// Report returns the counts gathered so far.
+func (a *AnonymousStats) Report() AnonymousStatsReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	report := a.report
+	report.FindingsByRule = maps.Clone(report.FindingsByRule)
+	report.FindingsByLanguage = maps.Clone(report.FindingsByLanguage)
+	report.FindingsBySeverity = maps.Clone(report.FindingsBySeverity)
+	report.FindingsByKind = maps.Clone(report.FindingsByKind)
+	report.FalsePositiveMarks = maps.Clone(report.FalsePositiveMarks)
+	return report
+}
+
+// findingRules names the rules that accepted p, with keywords outside the built-in lists
+// replaced by "custom".
+func findingRules(p FoundPrompt) []string {
+	var rules []string
+	switch {
+	case p.Marked:
+		return []string{"marked"}
+	case p.MatchedContentWord == "long_string" || p.MatchedContentWord == "constants_file":
+		rules = append(rules, p.MatchedContentWord)
+	case p.MatchedContentWord != "":
+		rules = append(rules, "content_keyword:"+builtinKeyword(p.MatchedContentWord, DefaultContentKeywordsList))
+	}
+	if p.MatchedVariableName != "" {
+		rules = append(rules, "variable_keyword:"+builtinKeyword(p.MatchedVariableName, DefaultVarKeywordsList))
+	}
+	if p.MatchedPlaceholder != "" {
+		rules = append(rules, "placeholder")
+	}
+	if len(rules) == 0 && p.Kind == KindRAGScaffold {
+		rules = append(rules, "rag_scaffold")
+	}
+	return rules
+}
+
+// builtinKeyword returns the entry of builtins matching keyword, or "custom".
+func builtinKeyword(keyword string, builtins []string) string {
+	for _, builtin := range builtins {
+		if strings.EqualFold(builtin, keyword) {
+			return builtin
+		}
+	}
+	return "custom"
+}
+
+// PostAnonymousStats sends report as JSON to url.
+func PostAnonymousStats(ctx context.Context, url, userAgent string, report AnonymousStatsReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshalling statistics: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create statistics request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send statistics to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send statistics to %s: %s", url, resp.Status)
+	}
+	return nil
+}
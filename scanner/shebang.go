@@ -0,0 +1,83 @@
+// scanner/shebang.go
+package scanner
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// sniffLength is how much of an extensionless file sniffLanguage looks at.
+const sniffLength = 512
+
+// interpreterLanguages maps script interpreters, without version suffixes, to languages.
+var interpreterLanguages = map[string]string{
+	"python":  "python",
+	"pypy":    "python",
+	"node":    "javascript",
+	"nodejs":  "javascript",
+	"bun":     "javascript",
+	"ts-node": "typescript",
+	"tsx":     "typescript",
+	"ruby":    "ruby",
+	"php":     "php",
+	"sh":      "bash",
+	"bash":    "bash",
+	"dash":    "bash",
+	"ksh":     "bash",
+	"zsh":     "bash",
+}
+
+// readHead returns the first sniffLength bytes of the file at filePath, or nil if it cannot be
+// read.
+func (s *Scanner) readHead(filePath string) []byte {
+	f, err := s.openFile(filePath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	head := make([]byte, sniffLength)
+	n, _ := io.ReadFull(f, head)
+	return head[:n]
+}
+
+// sniffLanguage returns the language of an extensionless file from the first line of head, the
+// start of its content: a shebang naming a known interpreter, directly or through env
+// ("#!/usr/bin/env python3"), or a "<?php" tag. It returns "" for other and binary files.
+func sniffLanguage(head []byte) string {
+	if len(head) > sniffLength {
+		head = head[:sniffLength]
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return ""
+	}
+	line, _, _ := bytes.Cut(head, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if bytes.HasPrefix(line, []byte("<?php")) {
+		return "php"
+	}
+	rest, ok := bytes.CutPrefix(line, []byte("#!"))
+	if !ok {
+		return ""
+	}
+	return interpreterLanguage(strings.Fields(string(rest)))
+}
+
+// interpreterLanguage returns the language of the interpreter a shebang runs. For env, the
+// interpreter is the first argument that is neither an option nor a variable assignment.
+func interpreterLanguage(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	name := filepath.Base(args[0])
+	if name == "env" {
+		for _, arg := range args[1:] {
+			if !strings.HasPrefix(arg, "-") && !strings.Contains(arg, "=") {
+				return interpreterLanguage([]string{arg})
+			}
+		}
+		return ""
+	}
+	return interpreterLanguages[strings.TrimRight(name, "0123456789.")]
+}
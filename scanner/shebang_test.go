@@ -0,0 +1,33 @@
+// scanner/shebang_test.go
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestScanReaderSniffsBuffer tells the language of an extensionless buffer from its own shebang,
+// not from a file of that name on disk, which here does not exist.
+func TestScanReaderSniffsBuffer(t *testing.T) {
+	s, err := New(ScanOptions{
+		MinLength:        DefaultMinLength,
+		VariableKeywords: DefaultVarKeywordsList,
+		ContentKeywords:  DefaultContentKeywordsList,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/usr/bin/env python3\nSYSTEM_PROMPT = \"You are a helpful assistant. Answer the user's question about {topic} concisely.\"\n"
+	displayName := filepath.Join(t.TempDir(), "deploy")
+	prompts, err := s.ScanReader(strings.NewReader(script), "", displayName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prompts) != 1 || prompts[0].Line != 2 {
+		t.Errorf("expected one finding on line 2, got %+v", prompts)
+	}
+	if _, err := s.ScanReader(strings.NewReader("just some notes\n"), "", displayName); err == nil {
+		t.Error("expected an error for a buffer without a shebang")
+	}
+}
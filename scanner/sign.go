@@ -0,0 +1,108 @@
+// scanner/sign.go
+package scanner
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// SignatureSuffix is appended to a report's path to name its detached signature.
+const SignatureSuffix = ".sig"
+
+// LoadSigningKey reads a PEM-encoded PKCS #8 Ed25519 private key, as written by
+// "openssl genpkey -algorithm ed25519".
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	key, err := readPEMKey(path, func(der []byte) (any, error) {
+		private, err := x509.ParsePKCS8PrivateKey(der)
+		return private, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an Ed25519 private key", path)
+	}
+	return private, nil
+}
+
+// LoadVerifyingKey reads a PEM-encoded Ed25519 public key ("openssl pkey -pubout"). A private key
+// file is accepted too, its public half being used.
+func LoadVerifyingKey(path string) (ed25519.PublicKey, error) {
+	key, err := readPEMKey(path, func(der []byte) (any, error) {
+		if public, err := x509.ParsePKIXPublicKey(der); err == nil {
+			return public, nil
+		}
+		private, err := x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return nil, err
+		}
+		if signer, ok := private.(crypto.Signer); ok {
+			return signer.Public(), nil
+		}
+		return private, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("verifying key %s is not an Ed25519 key", path)
+	}
+	return public, nil
+}
+
+func readPEMKey(path string, parse func(der []byte) (any, error)) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", path, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to read key %s: no PEM block found", path)
+	}
+	key, err := parse(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// SignFile writes a detached Ed25519 signature of the file at path to path+SignatureSuffix and
+// returns the signature's path. The signature is the raw 64 bytes, so it can also be checked with
+// "openssl pkeyutl -verify -rawin -pubin -inkey key.pub -in report -sigfile report.sig".
+func SignFile(key ed25519.PrivateKey, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s for signing: %w", path, err)
+	}
+	sigPath := path + SignatureSuffix
+	if err := os.WriteFile(sigPath, ed25519.Sign(key, data), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write signature %s: %w", sigPath, err)
+	}
+	return sigPath, nil
+}
+
+// ErrBadSignature is returned by VerifyFile when a report does not match its signature.
+var ErrBadSignature = errors.New("signature does not match the report")
+
+// VerifyFile checks the detached signature at sigPath of the file at path.
+func VerifyFile(key ed25519.PublicKey, path, sigPath string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read signature %s: %w", sigPath, err)
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return fmt.Errorf("%s: %w", path, ErrBadSignature)
+	}
+	return nil
+}
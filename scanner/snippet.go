@@ -0,0 +1,167 @@
+// scanner/snippet.go
+package scanner
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// snippetStyle is the chroma style used for highlighted snippets in HTML reports.
+const snippetStyle = "github"
+
+// sourceSnippet holds the source lines surrounding a finding.
+type sourceSnippet struct {
+	FirstLine int    // 1-based line number of the first line in Code
+	Code      string // Raw source lines, newline-terminated
+	Language  string // Chroma lexer name, empty if unknown
+}
+
+// readSnippet returns the finding's source lines plus contextLines lines before and after.
+// Files are cached in sourceCache since reports usually contain several findings per file.
+func readSnippet(p FoundPrompt, contextLines int, sourceCache map[string][]string) (sourceSnippet, error) {
+	lines, ok := sourceCache[p.Filepath]
+	if !ok {
+		contentBytes, err := os.ReadFile(p.Filepath)
+		if err != nil {
+			return sourceSnippet{}, fmt.Errorf("reading %s for snippet: %w", p.Filepath, err)
+		}
+		lines = strings.Split(strings.ReplaceAll(string(contentBytes), "\r\n", "\n"), "\n")
+		sourceCache[p.Filepath] = lines
+	}
+
+	endLine := p.EndLine
+	if endLine < p.Line {
+		endLine = p.Line
+	}
+	first := p.Line - contextLines
+	if first < 1 {
+		first = 1
+	}
+	last := endLine + contextLines
+	if last > len(lines) {
+		last = len(lines)
+	}
+	if first > last {
+		return sourceSnippet{}, fmt.Errorf("line %d out of range for %s", p.Line, p.Filepath)
+	}
+
+	snippet := sourceSnippet{
+		FirstLine: first,
+		Code:      strings.Join(lines[first-1:last], "\n") + "\n",
+	}
+	if lexer := lexers.Match(p.Filepath); lexer != nil {
+		snippet.Language = strings.ToLower(lexer.Config().Name)
+	}
+	return snippet, nil
+}
+
+// emphasisPattern builds a regex matching the keywords and placeholders that made p a finding.
+// It returns nil when nothing was recorded.
+func emphasisPattern(p FoundPrompt) *regexp.Regexp {
+	var terms []string
+	for _, term := range []string{p.MatchedContentWord, p.MatchedPlaceholder} {
+		if term != "" && term != "long_string" {
+			terms = append(terms, regexp.QuoteMeta(term))
+		}
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)(` + strings.Join(terms, "|") + `)`)
+}
+
+// highlightHTML renders a snippet as syntax-highlighted HTML. Tokens are emitted with chroma's CSS
+// classes (see snippetCSS) and matched terms are wrapped in <mark>.
+func highlightHTML(snippet sourceSnippet, p FoundPrompt) string {
+	lexer := lexers.Get(snippet.Language)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+	emphasis := emphasisPattern(p)
+
+	iterator, err := lexer.Tokenise(nil, snippet.Code)
+	if err != nil {
+		return "<pre class=\"chroma\">" + html.EscapeString(snippet.Code) + "</pre>"
+	}
+
+	var b strings.Builder
+	b.WriteString(`<pre class="chroma">`)
+	lineNo := snippet.FirstLine
+	endLine := p.EndLine
+	if endLine < p.Line {
+		endLine = p.Line
+	}
+	startLine := func() {
+		class := "line"
+		if lineNo >= p.Line && lineNo <= endLine {
+			class += " hl"
+		}
+		fmt.Fprintf(&b, `<span class="%s"><span class="ln">%d</span>`, class, lineNo)
+	}
+	startLine()
+	for _, token := range iterator.Tokens() {
+		class := chroma.StandardTypes[token.Type]
+		parts := strings.SplitAfter(token.Value, "\n")
+		for _, part := range parts {
+			if part == "" {
+				continue
+			}
+			text := strings.TrimSuffix(part, "\n")
+			if text != "" {
+				// Only emphasize terms on the finding's own lines, not in the surrounding context.
+				pattern := emphasis
+				if lineNo < p.Line || lineNo > endLine {
+					pattern = nil
+				}
+				fmt.Fprintf(&b, `<span class="%s">%s</span>`, class, emphasize(text, pattern))
+			}
+			if strings.HasSuffix(part, "\n") {
+				b.WriteString("\n</span>")
+				lineNo++
+				if lineNo <= snippet.FirstLine+strings.Count(snippet.Code, "\n")-1 {
+					startLine()
+				}
+			}
+		}
+	}
+	b.WriteString("</pre>")
+	return b.String()
+}
+
+// emphasize HTML-escapes text, wrapping matches of pattern in <mark>.
+func emphasize(text string, pattern *regexp.Regexp) string {
+	if pattern == nil {
+		return html.EscapeString(text)
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:loc[0]]))
+		b.WriteString("<mark>" + html.EscapeString(text[loc[0]:loc[1]]) + "</mark>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// snippetCSS returns the stylesheet for highlighted snippets.
+func snippetCSS() string {
+	var b strings.Builder
+	formatter := chromahtml.New(chromahtml.WithClasses(true))
+	if err := formatter.WriteCSS(&b, styles.Get(snippetStyle)); err != nil {
+		return ""
+	}
+	b.WriteString(".chroma .line { display: block; }\n")
+	b.WriteString(".chroma .line.hl { background-color: #fff8c5; }\n")
+	b.WriteString(".chroma .ln { display: inline-block; min-width: 3em; color: #8c959f; user-select: none; }\n")
+	return b.String()
+}
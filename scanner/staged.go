@@ -0,0 +1,69 @@
+// scanner/staged.go
+package scanner
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// CheckoutStaged writes the files added, copied, modified or renamed in the index of the git
+// repository holding repoDir to a new temporary directory under Options.TempDir, with the content
+// they are staged with rather than the one in the working tree, so that a pre-commit check sees
+// exactly what is about to be committed. The repository's ScannerIgnoreFile is written too, when
+// the index has one. It returns the directory, which the caller removes, and the staged paths
+// relative to the repository root; with nothing staged, the directory is empty.
+func (s *Scanner) CheckoutStaged(repoDir string) (string, []string, error) {
+	top, err := gitOutput(repoDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", nil, err
+	}
+	top = strings.TrimSpace(top)
+	out, err := gitOutput(top, "diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR")
+	if err != nil {
+		return "", nil, err
+	}
+	var staged []string
+	for _, path := range strings.Split(out, "\x00") {
+		if path != "" {
+			staged = append(staged, path)
+		}
+	}
+
+	tempDir, err := os.MkdirTemp(s.Options.TempDir, "prompt-scan-staged-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	if len(staged) == 0 {
+		return tempDir, nil, nil
+	}
+	paths := staged
+	if ignored, err := gitOutput(top, "ls-files", "--cached", "-z", "--", ScannerIgnoreFile); err == nil && ignored != "" && !slices.Contains(staged, ScannerIgnoreFile) {
+		paths = append(paths[:len(paths):len(paths)], ScannerIgnoreFile)
+	}
+	cmd := exec.Command("git", "-C", top, "checkout-index", "-z", "--stdin", "--prefix="+tempDir+string(filepath.Separator))
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00"))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		_ = os.RemoveAll(tempDir)
+		return "", nil, fmt.Errorf("failed to check out staged files: %w. Stderr: %s", err, stderr.String())
+	}
+	return tempDir, staged, nil
+}
+
+// gitOutput runs git with args in dir and returns its standard output.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s in %s: %w. Stderr: %s", args[0], dir, err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
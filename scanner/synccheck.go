@@ -0,0 +1,128 @@
+// scanner/synccheck.go
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kinds of drift between an extract manifest and the source tree, reported by SyncCheck.
+const (
+	SyncMissingFile  = "missing_file" // The prompt file listed in the manifest is gone
+	SyncUnreferenced = "unreferenced" // No source file mentions the prompt file or its ID
+	SyncInlineCopy   = "inline_copy"  // A string literal in the source holds the prompt's text again
+)
+
+// SyncIssue is one drift found by SyncCheck.
+type SyncIssue struct {
+	Kind string `json:"kind"`
+	File string `json:"file"` // Prompt file, relative to the manifest's directory
+	// Path and Line locate an inline copy, relative to the scanned root.
+	Path string `json:"path,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+// SyncCheck verifies that the source under rootDir still uses the prompts extracted to
+// manifestDir, described by manifest: every prompt file must exist and be referenced by some
+// scanned file, by its path as written in the manifest (e.g. "src/agent.py.L12.txt", which also
+// matches "prompts/src/agent.py.L12.txt") or by its ID, and no string literal may hold the text of
+// a prompt again, either as extracted or as the prompt file reads now. Literals marked with a
+// PragmaIgnore comment are not reported as copies. Files under manifestDir are not scanned. The
+// issues are sorted by prompt file.
+func (s *Scanner) SyncCheck(rootDir, manifestDir string, manifest *ExtractManifest) ([]SyncIssue, error) {
+	s.rootDir = rootDir
+	var issues []SyncIssue
+	copies := make(map[string][]ExtractedPrompt) // Prompts per text hash
+	unreferenced := make(map[string]ExtractedPrompt)
+	for _, prompt := range manifest.Prompts {
+		copies[prompt.SHA256] = append(copies[prompt.SHA256], prompt)
+		content, err := os.ReadFile(filepath.Join(manifestDir, filepath.FromSlash(prompt.File)))
+		if errors.Is(err, fs.ErrNotExist) {
+			issues = append(issues, SyncIssue{Kind: SyncMissingFile, File: prompt.File})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt file: %w", err)
+		}
+		if hash := PromptHash(string(content)); hash != prompt.SHA256 {
+			copies[hash] = append(copies[hash], prompt)
+		}
+		unreferenced[prompt.File] = prompt
+	}
+
+	absManifestDir, err := filepath.Abs(manifestDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for %s: %w", manifestDir, err)
+	}
+	err = s.walkFiles(context.Background(), rootDir, func(path, lang string) error {
+		if absPath, err := filepath.Abs(path); err == nil && isUnder(absManifestDir, absPath) {
+			return nil
+		}
+		contentBytes, err := os.ReadFile(path)
+		if err != nil || len(contentBytes) == 0 || isBinary(contentBytes) {
+			return nil
+		}
+		for file, prompt := range unreferenced {
+			if bytes.Contains(contentBytes, []byte(prompt.File)) || (prompt.ID != "" && bytes.Contains(contentBytes, []byte(prompt.ID))) {
+				delete(unreferenced, file)
+			}
+		}
+		relPath := s.relativePath(path)
+		parser := s.fileParser(path, lang, contentBytes)
+		parser.Options.Trace = nil
+		parser.observe = func(ctx PromptContext, fp FoundPrompt, accepted bool) {
+			if prompts := copies[PromptHash(fp.Content)]; len(prompts) > 0 && fp.RejectReason != RejectPragma {
+				issues = append(issues, SyncIssue{Kind: SyncInlineCopy, File: copiedPrompt(prompts, relPath).File, Path: relPath, Line: fp.Line})
+			}
+		}
+		if _, err := parser.parseContent(path, lang, contentBytes); err != nil && s.Options.Verbose {
+			log.Printf("Sync check: error parsing %q: %v", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error checking directory %s: %w", rootDir, err)
+	}
+	for file := range unreferenced {
+		issues = append(issues, SyncIssue{Kind: SyncUnreferenced, File: file})
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	return issues, nil
+}
+
+// copiedPrompt picks the prompt an inline copy in the file at relPath stands for among prompts
+// with the same text: preferably one extracted from that file.
+func copiedPrompt(prompts []ExtractedPrompt, relPath string) ExtractedPrompt {
+	for _, prompt := range prompts {
+		if prompt.Source.Path == relPath {
+			return prompt
+		}
+	}
+	return prompts[0]
+}
+
+// isUnder reports whether path is dir or lies below it. Both must be absolute and clean.
+func isUnder(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,55 @@
+// scanner/text_parser.go
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/alexferrari88/prompt-scanner/utils"
+)
+
+// isTextPromptFile reports whether -scan-text treats the file as a whole-file prompt candidate:
+// .txt and .prompt files, and extensionless files inside a "prompts" directory.
+func isTextPromptFile(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".txt", ".prompt":
+		return true
+	case "":
+		for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(filePath)), "/") {
+			if strings.EqualFold(dir, "prompts") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseTextFile treats the whole body of a plain-text file as one prompt candidate. The file name
+// (without extension) stands in for the variable name, so names like system_prompt.txt count.
+func (s *Scanner) ParseTextFile(filePath string, contentBytes []byte) ([]FoundPrompt, error) {
+	content := strings.TrimRight(strings.ReplaceAll(string(contentBytes), "\r\n", "\n"), "\n")
+	if strings.TrimSpace(content) == "" {
+		return nil, nil
+	}
+	linesInContent := utils.CountNewlines(content) + 1
+	base := filepath.Base(filePath)
+
+	fp := FoundPrompt{
+		Filepath:    filePath,
+		Line:        1,
+		EndLine:     linesInContent,
+		Content:     content,
+		IsMultiLine: linesInContent > 1,
+	}
+	context := PromptContext{
+		Text:                content,
+		VariableName:        strings.TrimSuffix(base, filepath.Ext(base)),
+		IsMultiLineExplicit: linesInContent > 1,
+		LinesInContent:      linesInContent,
+		FileExtension:       filepath.Ext(filePath),
+	}
+	if s.evaluateCandidate(context, &fp) {
+		return []FoundPrompt{fp}, nil
+	}
+	return nil, nil
+}
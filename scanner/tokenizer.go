@@ -0,0 +1,277 @@
+// scanner/tokenizer.go
+package scanner
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Tokenizer counts the tokens a model's tokenizer splits text into. Implementations must be safe
+// for concurrent use, since every scan worker counts the tokens of its findings.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a counting function to Tokenizer.
+type TokenizerFunc func(text string) int
+
+// CountTokens calls f(text).
+func (f TokenizerFunc) CountTokens(text string) int { return f(text) }
+
+// LoadTokenizer reads a tokenizer file, so that findings carry exact token counts for the models
+// it belongs to rather than the GPT-style EstimateTokens. Two formats are read:
+//
+//   - SentencePiece models (tokenizer.model, as shipped with Llama 2, Mistral or Gemma), unigram or
+//     BPE, with or without byte fallback;
+//   - Hugging Face tokenizer.json files whose model is BPE or Unigram, with a byte-level
+//     pre-tokenizer (Llama 3, Qwen, GPT-2 style) or a metaspace one (Llama 2 style).
+//
+// Special and added tokens are not matched, and regular expressions of byte-level pre-tokenizers
+// that Go cannot compile are approximated, so counts may differ from the reference tokenizer by
+// a few tokens.
+func LoadTokenizer(path string) (Tokenizer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tokenizer %s: %w", path, err)
+	}
+	var t *subwordTokenizer
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		t, err = parseHFTokenizer(data)
+	} else {
+		t, err = parseSentencePieceModel(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tokenizer %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// countTokens counts the tokens of text with the configured Tokenizer, or estimates them.
+func (so *ScanOptions) countTokens(text string) int {
+	if so.Tokenizer != nil {
+		return so.Tokenizer.CountTokens(text)
+	}
+	return EstimateTokens(text)
+}
+
+// Subword algorithms of a subwordTokenizer.
+const (
+	unigramAlgorithm = iota // Most likely segmentation by piece log probabilities
+	bpeAlgorithm            // Repeated merges of adjacent symbols
+)
+
+// metaspace replaces spaces in SentencePiece-style vocabularies.
+const metaspace = "▁"
+
+// bpeMaxWordRunes bounds the words BPE merges at once; longer runs without spaces, such as
+// base64 blobs, are counted in chunks to keep the quadratic merge loop fast.
+const bpeMaxWordRunes = 256
+
+// subwordTokenizer counts tokens with a SentencePiece-style or byte-level subword vocabulary. It is
+// read-only once loaded.
+type subwordTokenizer struct {
+	algorithm int
+	// scores are the vocabulary's pieces: log probabilities for unigram models, merge priorities
+	// (higher first) for SentencePiece BPE models.
+	scores map[string]float64
+	// merges are the merge ranks (lower first) of Hugging Face BPE models; nil merges by score.
+	merges       map[[2]string]int
+	ignoreMerges bool // A word that is a piece itself is one token
+	byteFallback bool // Pieces missing from the vocabulary are split into <0xNN> byte tokens
+	// byteLevel maps text bytes to printable runes before merging, as GPT-2 does; split then
+	// pre-tokenizes the text. Otherwise spaces become metaspace and words start at each one.
+	byteLevel      bool
+	split          *regexp.Regexp
+	addPrefix      bool // Prepend a space (add_dummy_prefix)
+	collapseSpaces bool // Trim the text and collapse runs of whitespace (remove_extra_whitespaces)
+	maxPieceLen    int  // Longest piece, in bytes
+	unkScore       float64
+}
+
+// newSubwordTokenizer finishes a tokenizer whose scores are set.
+func newSubwordTokenizer(t *subwordTokenizer) (*subwordTokenizer, error) {
+	if len(t.scores) == 0 {
+		return nil, fmt.Errorf("the vocabulary is empty")
+	}
+	minScore := math.Inf(1)
+	for piece, score := range t.scores {
+		t.maxPieceLen = max(t.maxPieceLen, len(piece))
+		minScore = min(minScore, score)
+	}
+	// SentencePiece penalizes unknown characters 10 below the least likely piece.
+	t.unkScore = minScore - 10
+	return t, nil
+}
+
+// CountTokens returns the number of tokens the vocabulary splits text into.
+func (t *subwordTokenizer) CountTokens(text string) int {
+	n := 0
+	for _, word := range t.words(text) {
+		if t.algorithm == unigramAlgorithm {
+			n += t.countUnigram(word)
+			continue
+		}
+		for len(word) > 0 {
+			chunk := word
+			if utf8.RuneCountInString(word) > bpeMaxWordRunes {
+				chunk = word[:runeOffset(word, bpeMaxWordRunes)]
+			}
+			n += t.countBPE(chunk)
+			word = word[len(chunk):]
+		}
+	}
+	return n
+}
+
+// words pre-tokenizes text into the units subword merges stay within.
+func (t *subwordTokenizer) words(text string) []string {
+	if t.collapseSpaces {
+		text = strings.Join(strings.Fields(text), " ")
+	}
+	if text == "" {
+		return nil
+	}
+	if t.addPrefix && !strings.HasPrefix(text, " ") {
+		text = " " + text
+	}
+	if t.byteLevel {
+		words := t.split.FindAllString(text, -1)
+		for i, word := range words {
+			words[i] = byteLevelString(word)
+		}
+		return words
+	}
+	text = strings.ReplaceAll(text, " ", metaspace)
+	// Every word but the first starts with a metaspace. Searching from the second byte never
+	// matches inside a rune, since metaspace starts with a leading byte.
+	var words []string
+	for len(text) > 0 {
+		next := strings.Index(text[1:], metaspace)
+		if next < 0 {
+			words = append(words, text)
+			break
+		}
+		words = append(words, text[:next+1])
+		text = text[next+1:]
+	}
+	return words
+}
+
+// countUnigram counts the tokens of the most likely segmentation of word (Viterbi).
+func (t *subwordTokenizer) countUnigram(word string) int {
+	best := make([]float64, len(word)+1)
+	counts := make([]int, len(word)+1)
+	for i := 1; i <= len(word); i++ {
+		best[i] = math.Inf(-1)
+	}
+	for i := 0; i < len(word); {
+		_, size := utf8.DecodeRuneInString(word[i:])
+		if !math.IsInf(best[i], -1) {
+			matchedRune := false
+			for j := i + size; j <= len(word) && j-i <= t.maxPieceLen; j++ {
+				score, ok := t.scores[word[i:j]]
+				if !ok {
+					continue
+				}
+				matchedRune = matchedRune || j == i+size
+				if best[i]+score > best[j] {
+					best[j], counts[j] = best[i]+score, counts[i]+1
+				}
+			}
+			if !matchedRune && best[i]+t.unkScore > best[i+size] {
+				best[i+size], counts[i+size] = best[i]+t.unkScore, counts[i]+t.unknownTokens(word[i:i+size])
+			}
+		}
+		i += size
+	}
+	return counts[len(word)]
+}
+
+// countBPE merges the symbols of word, one rune each to begin with, until no merge applies, and
+// counts the remaining symbols.
+func (t *subwordTokenizer) countBPE(word string) int {
+	if _, ok := t.scores[word]; ok && t.ignoreMerges {
+		return 1
+	}
+	symbols := make([]string, 0, len(word))
+	for i := 0; i < len(word); {
+		_, size := utf8.DecodeRuneInString(word[i:])
+		symbols = append(symbols, word[i:i+size])
+		i += size
+	}
+	for len(symbols) > 1 {
+		bestIndex, bestRank, bestScore := -1, math.MaxInt, math.Inf(-1)
+		for i := 0; i+1 < len(symbols); i++ {
+			if t.merges != nil {
+				if rank, ok := t.merges[[2]string{symbols[i], symbols[i+1]}]; ok && rank < bestRank {
+					bestIndex, bestRank = i, rank
+				}
+			} else if score, ok := t.scores[symbols[i]+symbols[i+1]]; ok && score > bestScore {
+				bestIndex, bestScore = i, score
+			}
+		}
+		if bestIndex < 0 {
+			break
+		}
+		symbols[bestIndex] += symbols[bestIndex+1]
+		symbols = append(symbols[:bestIndex+1], symbols[bestIndex+2:]...)
+	}
+	n := 0
+	for _, symbol := range symbols {
+		if _, ok := t.scores[symbol]; ok {
+			n++
+		} else {
+			n += t.unknownTokens(symbol)
+		}
+	}
+	return n
+}
+
+// unknownTokens is the number of tokens of a symbol missing from the vocabulary: one per byte with
+// byte fallback, otherwise a single unknown token.
+func (t *subwordTokenizer) unknownTokens(symbol string) int {
+	if t.byteFallback {
+		return len(symbol)
+	}
+	return 1
+}
+
+// runeOffset returns the byte offset of the n-th rune of s.
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(s)
+}
+
+// byteLevelRunes maps every byte to the printable rune GPT-2 byte-level vocabularies use for it.
+var byteLevelRunes = func() [256]rune {
+	var table [256]rune
+	next := rune(256)
+	for b := 0; b < 256; b++ {
+		if (b >= '!' && b <= '~') || (b >= 0xA1 && b <= 0xAC) || (b >= 0xAE && b <= 0xFF) {
+			table[b] = rune(b)
+		} else {
+			table[b] = next
+			next++
+		}
+	}
+	return table
+}()
+
+// byteLevelString maps the bytes of s with byteLevelRunes.
+func byteLevelString(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		b.WriteRune(byteLevelRunes[s[i]])
+	}
+	return b.String()
+}
@@ -0,0 +1,207 @@
+// scanner/tokenizer_hf.go
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// byteLevelSplit is the GPT-2 pre-tokenizer pattern, used for byte-level tokenizers that name no
+// pattern of their own or one Go cannot compile.
+var byteLevelSplit = regexp.MustCompile(`'s|'t|'re|'ve|'m|'ll|'d| ?\pL+| ?\pN+| ?[^\s\pL\pN]+|\s+`)
+
+// hfTokenizer is the part of a Hugging Face tokenizer.json that decides token counts.
+type hfTokenizer struct {
+	Normalizer   *hfComponent `json:"normalizer"`
+	PreTokenizer *hfComponent `json:"pre_tokenizer"`
+	Model        struct {
+		Type         string          `json:"type"`
+		Vocab        json.RawMessage `json:"vocab"`
+		Merges       json.RawMessage `json:"merges"`
+		ByteFallback bool            `json:"byte_fallback"`
+		IgnoreMerges bool            `json:"ignore_merges"`
+	} `json:"model"`
+}
+
+// hfComponent is a normalizer or pre-tokenizer, possibly a sequence of others.
+type hfComponent struct {
+	Type           string         `json:"type"`
+	Normalizers    []*hfComponent `json:"normalizers"`
+	Pretokenizers  []*hfComponent `json:"pretokenizers"`
+	Pattern        *hfPattern     `json:"pattern"`
+	Content        string         `json:"content"`
+	Prepend        string         `json:"prepend"`
+	AddPrefixSpace *bool          `json:"add_prefix_space"`
+	PrependScheme  string         `json:"prepend_scheme"`
+}
+
+// hfPattern is the pattern of a Split pre-tokenizer or Replace normalizer.
+type hfPattern struct {
+	String string `json:"String"`
+	Regex  string `json:"Regex"`
+}
+
+// flatten lists c and the components of its sequences, in order.
+func (c *hfComponent) flatten() []*hfComponent {
+	if c == nil {
+		return nil
+	}
+	all := []*hfComponent{c}
+	for _, child := range append(c.Normalizers, c.Pretokenizers...) {
+		all = append(all, child.flatten()...)
+	}
+	return all
+}
+
+// parseHFTokenizer reads a Hugging Face tokenizer.json.
+func parseHFTokenizer(data []byte) (*subwordTokenizer, error) {
+	var hf hfTokenizer
+	if err := json.Unmarshal(data, &hf); err != nil {
+		return nil, fmt.Errorf("not a tokenizer.json: %w", err)
+	}
+	t := &subwordTokenizer{scores: make(map[string]float64), byteFallback: hf.Model.ByteFallback, ignoreMerges: hf.Model.IgnoreMerges}
+	switch hf.Model.Type {
+	case "BPE":
+		t.algorithm = bpeAlgorithm
+		var vocab map[string]int
+		if err := json.Unmarshal(hf.Model.Vocab, &vocab); err != nil {
+			return nil, fmt.Errorf("reading BPE vocab: %w", err)
+		}
+		for piece := range vocab {
+			t.scores[piece] = 0
+		}
+		merges, err := parseHFMerges(hf.Model.Merges)
+		if err != nil {
+			return nil, err
+		}
+		t.merges = merges
+	case "Unigram":
+		t.algorithm = unigramAlgorithm
+		var vocab [][2]any
+		if err := json.Unmarshal(hf.Model.Vocab, &vocab); err != nil {
+			return nil, fmt.Errorf("reading Unigram vocab: %w", err)
+		}
+		for _, entry := range vocab {
+			piece, _ := entry[0].(string)
+			score, _ := entry[1].(float64)
+			// Byte pieces such as <0x0A> never match text.
+			if piece != "" && !(strings.HasPrefix(piece, "<0x") && len(piece) == 6 && strings.HasSuffix(piece, ">")) {
+				t.scores[piece] = score
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tokenizer model type '%s' (only BPE and Unigram models are supported)", hf.Model.Type)
+	}
+
+	// Byte-level pre-tokenizers split with a pattern and map bytes to printable runes. Otherwise
+	// spaces must be replaced by metaspace, by the pre-tokenizer or by the normalizer.
+	metaspaced := false
+	for _, c := range hf.PreTokenizer.flatten() {
+		switch c.Type {
+		case "ByteLevel":
+			t.byteLevel = true
+			if c.AddPrefixSpace != nil && *c.AddPrefixSpace {
+				t.addPrefix = true
+			}
+		case "Split":
+			if c.Pattern != nil && c.Pattern.Regex != "" {
+				t.split = compileSplitPattern(c.Pattern.Regex)
+			}
+		case "Metaspace":
+			metaspaced = true
+			t.addPrefix = c.PrependScheme == "always" || c.PrependScheme == "first" || (c.PrependScheme == "" && (c.AddPrefixSpace == nil || *c.AddPrefixSpace))
+		}
+	}
+	for _, c := range hf.Normalizer.flatten() {
+		switch {
+		case c.Type == "Replace" && c.Pattern != nil && c.Pattern.String == " " && c.Content == metaspace:
+			metaspaced = true
+		case c.Type == "Prepend" && c.Prepend == metaspace:
+			t.addPrefix = true
+		}
+	}
+	switch {
+	case t.byteLevel:
+		if t.split == nil {
+			t.split = byteLevelSplit
+		}
+	case !metaspaced:
+		return nil, fmt.Errorf("unsupported pre-tokenizer (only byte-level and metaspace tokenizers are supported)")
+	}
+	return newSubwordTokenizer(t)
+}
+
+// parseHFMerges reads BPE merges, written as "a b" strings or, in newer files, as ["a", "b"] pairs.
+// The position of a merge is its rank.
+func parseHFMerges(raw json.RawMessage) (map[[2]string]int, error) {
+	merges := make(map[[2]string]int)
+	var pairs [][2]string
+	if err := json.Unmarshal(raw, &pairs); err == nil {
+		for rank, pair := range pairs {
+			if _, ok := merges[pair]; !ok {
+				merges[pair] = rank
+			}
+		}
+		return merges, nil
+	}
+	var lines []string
+	if err := json.Unmarshal(raw, &lines); err != nil {
+		return nil, fmt.Errorf("reading BPE merges: %w", err)
+	}
+	for rank, line := range lines {
+		a, b, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("reading BPE merges: malformed merge '%s'", line)
+		}
+		if _, ok := merges[[2]string{a, b}]; !ok {
+			merges[[2]string{a, b}] = rank
+		}
+	}
+	return merges, nil
+}
+
+// compileSplitPattern compiles the pattern of a Split pre-tokenizer. Patterns with lookaheads,
+// which Go does not support, drop the alternatives using them (such as Llama 3's `\s+(?!\S)`,
+// which only changes how runs of spaces are split); patterns that still do not compile fall back
+// to byteLevelSplit.
+func compileSplitPattern(pattern string) *regexp.Regexp {
+	if re, err := regexp.Compile(pattern); err == nil {
+		return re
+	}
+	var kept []string
+	for _, alternative := range topLevelAlternatives(pattern) {
+		if !strings.Contains(alternative, "(?!") && !strings.Contains(alternative, "(?=") {
+			kept = append(kept, alternative)
+		}
+	}
+	if re, err := regexp.Compile(strings.Join(kept, "|")); err == nil {
+		return re
+	}
+	return byteLevelSplit
+}
+
+// topLevelAlternatives splits a regular expression at the "|" outside groups and classes.
+func topLevelAlternatives(pattern string) []string {
+	var alternatives []string
+	depth, inClass, start := 0, false, 0
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\':
+			i++
+		case inClass:
+			inClass = c != ']'
+		case c == '[':
+			inClass = true
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == '|' && depth == 0:
+			alternatives = append(alternatives, pattern[start:i])
+			start = i + 1
+		}
+	}
+	return append(alternatives, pattern[start:])
+}
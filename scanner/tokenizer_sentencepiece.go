@@ -0,0 +1,149 @@
+// scanner/tokenizer_sentencepiece.go
+package scanner
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Field numbers and enum values of the SentencePiece ModelProto (sentencepiece_model.proto).
+const (
+	spModelPieces         = 1
+	spModelTrainerSpec    = 2
+	spModelNormalizerSpec = 3
+
+	spPiecePiece = 1
+	spPieceScore = 2
+	spPieceType  = 3
+
+	spTrainerModelType    = 3
+	spTrainerByteFallback = 35
+
+	spNormalizerAddDummyPrefix         = 3
+	spNormalizerRemoveExtraWhitespaces = 4
+
+	spTypeNormal      = 1
+	spTypeUserDefined = 4
+	spModelUnigram    = 1
+	spModelBPE        = 2
+)
+
+// parseSentencePieceModel reads a serialized SentencePiece ModelProto.
+func parseSentencePieceModel(data []byte) (*subwordTokenizer, error) {
+	t := &subwordTokenizer{scores: make(map[string]float64), addPrefix: true, collapseSpaces: true}
+	modelType := uint64(spModelUnigram)
+	err := readProtoFields(data, func(field int, value uint64, payload []byte) error {
+		switch field {
+		case spModelPieces:
+			return readSentencePiece(payload, t.scores)
+		case spModelTrainerSpec:
+			return readProtoFields(payload, func(field int, value uint64, _ []byte) error {
+				switch field {
+				case spTrainerModelType:
+					modelType = value
+				case spTrainerByteFallback:
+					t.byteFallback = value != 0
+				}
+				return nil
+			})
+		case spModelNormalizerSpec:
+			return readProtoFields(payload, func(field int, value uint64, _ []byte) error {
+				switch field {
+				case spNormalizerAddDummyPrefix:
+					t.addPrefix = value != 0
+				case spNormalizerRemoveExtraWhitespaces:
+					t.collapseSpaces = value != 0
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("not a SentencePiece model: %w", err)
+	}
+	switch modelType {
+	case spModelUnigram:
+		t.algorithm = unigramAlgorithm
+	case spModelBPE:
+		t.algorithm = bpeAlgorithm
+	default:
+		return nil, fmt.Errorf("unsupported SentencePiece model type %d (only unigram and BPE models are supported)", modelType)
+	}
+	return newSubwordTokenizer(t)
+}
+
+// readSentencePiece adds one piece of a ModelProto to scores. Control, unknown, unused and byte
+// pieces never match text, so they are left out.
+func readSentencePiece(data []byte, scores map[string]float64) error {
+	var piece string
+	var score float64
+	pieceType := uint64(spTypeNormal)
+	err := readProtoFields(data, func(field int, value uint64, payload []byte) error {
+		switch field {
+		case spPiecePiece:
+			piece = string(payload)
+		case spPieceScore:
+			score = float64(math.Float32frombits(uint32(value)))
+		case spPieceType:
+			pieceType = value
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if piece != "" && (pieceType == spTypeNormal || pieceType == spTypeUserDefined) {
+		scores[piece] = score
+	}
+	return nil
+}
+
+// readProtoFields calls fn for every field of a protocol buffer message: value holds varint and
+// fixed-size values, payload the bytes of length-delimited ones.
+func readProtoFields(data []byte, fn func(field int, value uint64, payload []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errors.New("malformed field key")
+		}
+		data = data[n:]
+		field := int(key >> 3)
+		if field == 0 {
+			return errors.New("invalid field number 0")
+		}
+		var value uint64
+		var payload []byte
+		switch key & 7 {
+		case 0: // varint
+			if value, n = binary.Uvarint(data); n <= 0 {
+				return errors.New("malformed varint")
+			}
+			data = data[n:]
+		case 1: // 64-bit
+			if len(data) < 8 {
+				return errors.New("truncated 64-bit field")
+			}
+			value, data = binary.LittleEndian.Uint64(data), data[8:]
+		case 2: // length-delimited
+			length, n := binary.Uvarint(data)
+			if n <= 0 || length > uint64(len(data)-n) {
+				return errors.New("malformed length-delimited field")
+			}
+			payload, data = data[n:n+int(length)], data[n+int(length):]
+		case 5: // 32-bit
+			if len(data) < 4 {
+				return errors.New("truncated 32-bit field")
+			}
+			value, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d", key&7)
+		}
+		if err := fn(field, value, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// scanner/tokens.go
+package scanner
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// EstimateTokens approximates how many tokens text occupies for typical BPE tokenizers: about
+// four characters or three quarters of a word per token, whichever is larger. CJK characters
+// count roughly one token each.
+func EstimateTokens(text string) int {
+	if strings.TrimSpace(text) == "" {
+		return 0
+	}
+	chars, cjk := 0, 0
+	for _, r := range text {
+		chars++
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cjk++
+		}
+	}
+	words := len(strings.Fields(text))
+	byChars := float64(chars-cjk) / 4
+	byWords := float64(words) * 4 / 3
+	return int(math.Ceil(math.Max(byChars, byWords))) + cjk
+}
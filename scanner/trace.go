@@ -0,0 +1,50 @@
+// scanner/trace.go
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// traceLocation is a parsed ScanOptions.TraceLocation.
+type traceLocation struct {
+	path string // Forward-slash path suffix
+	line int    // 0 traces every line
+}
+
+// parseTraceLocation parses "path:line" or a bare path.
+func parseTraceLocation(location string) (traceLocation, error) {
+	loc := traceLocation{path: location}
+	if i := strings.LastIndex(location, ":"); i > 0 {
+		if line, err := strconv.Atoi(location[i+1:]); err == nil {
+			if line < 1 {
+				return traceLocation{}, fmt.Errorf("invalid trace location '%s': line must be positive", location)
+			}
+			loc.path, loc.line = location[:i], line
+		}
+	}
+	loc.path = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(loc.path)), "./")
+	return loc, nil
+}
+
+// tracesCandidate reports whether fp is at the TraceLocation.
+func (s *Scanner) tracesCandidate(fp *FoundPrompt) bool {
+	loc := s.Options.compiledTrace
+	if loc == nil || s.Options.Trace == nil {
+		return false
+	}
+	rel := s.relativePath(fp.Filepath)
+	if rel != loc.path && !strings.HasSuffix(rel, "/"+loc.path) && filepath.ToSlash(fp.Filepath) != loc.path {
+		return false
+	}
+	return loc.line == 0 || (fp.Line <= loc.line && loc.line <= max(fp.Line, fp.EndLine))
+}
+
+// tracef records one step of the heuristics for the candidate being traced.
+func (s *Scanner) tracef(format string, args ...any) {
+	if s.tracing {
+		s.Options.Trace(fmt.Sprintf(format, args...))
+	}
+}
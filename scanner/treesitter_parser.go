@@ -3,14 +3,18 @@ package scanner
 
 import (
 	"bufio"
-	"context"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
 
 	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/bash"
+	"github.com/smacker/go-tree-sitter/java"
 	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/php"
 	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/ruby"
 	"github.com/smacker/go-tree-sitter/typescript/typescript"
 
 	"github.com/alexferrari88/prompt-scanner/utils"
@@ -39,6 +43,10 @@ var (
 		"python":     python.GetLanguage(),
 		"javascript": javascript.GetLanguage(),
 		"typescript": typescript.GetLanguage(),
+		"ruby":       ruby.GetLanguage(),
+		"java":       java.GetLanguage(),
+		"php":        php.GetLanguage(),
+		"bash":       bash.GetLanguage(),
 	}
 
 	rawLangToQueries = map[string]string{
@@ -120,6 +128,52 @@ var (
 			(throw_statement (string) @string_node) ; Context from AST walk
 			(throw_statement (template_string) @string_node) ; Context from AST walk
 		`,
+		"ruby": `
+			(string) @string_node
+			; Heredoc bodies are siblings of the statement holding their heredoc_beginning.
+			(heredoc_body) @string_node
+
+			(assignment
+				left: (_) @var.name ; Context from AST walk
+				right: (string) @string_node)
+			(call
+				method: (identifier) @call.function ; Context from AST walk
+				arguments: (argument_list (string) @string_node))
+		`,
+		"java": `
+			; Covers both regular literals and text blocks ("""...""").
+			(string_literal) @string_node
+
+			(variable_declarator
+				name: (identifier) @var.name ; Context from AST walk
+				value: (string_literal) @string_node)
+			(assignment_expression
+				left: (_) @var.name ; Context from AST walk
+				right: (string_literal) @string_node)
+			(method_invocation
+				name: (identifier) @call.function ; Context from AST walk
+				arguments: (argument_list (string_literal) @string_node))
+			(object_creation_expression
+				type: (_) @call.new_constructor ; Context from AST walk
+				arguments: (argument_list (string_literal) @string_node))
+		`,
+		"php": `
+			[ (string) (encapsed_string) (heredoc) (nowdoc) ] @string_node
+
+			(assignment_expression
+				left: (_) @var.name ; Context from AST walk
+				right: [ (string) (encapsed_string) (heredoc) (nowdoc) ] @string_node)
+			(function_call_expression
+				function: (_) @call.function ; Context from AST walk
+				arguments: (arguments (argument [ (string) (encapsed_string) (heredoc) (nowdoc) ] @string_node)))
+			(member_call_expression
+				name: (_) @call.function ; Context from AST walk
+				arguments: (arguments (argument [ (string) (encapsed_string) (heredoc) (nowdoc) ] @string_node)))
+		`,
+		"bash": `
+			; Quoted strings, including arguments to curl and other CLI tools, and heredoc bodies.
+			[ (string) (raw_string) (heredoc_body) ] @string_node
+		`,
 	}
 	langToQueries map[string]string
 )
@@ -134,6 +188,72 @@ func init() {
 	}
 }
 
+// symbolNodeTypes lists, per language, the AST node types that name a scope in a finding's breadcrumb.
+var symbolNodeTypes = map[string]map[string]bool{
+	"python":     {"class_definition": true, "function_definition": true},
+	"javascript": {"class_declaration": true, "class": true, "function_declaration": true, "method_definition": true, "generator_function_declaration": true},
+	"typescript": {"class_declaration": true, "abstract_class_declaration": true, "interface_declaration": true, "function_declaration": true, "method_definition": true, "generator_function_declaration": true},
+	"ruby":       {"class": true, "module": true, "method": true, "singleton_method": true},
+	"java":       {"class_declaration": true, "interface_declaration": true, "enum_declaration": true, "record_declaration": true, "method_declaration": true, "constructor_declaration": true},
+	"php":        {"namespace_definition": true, "class_declaration": true, "interface_declaration": true, "trait_declaration": true, "method_declaration": true, "function_definition": true},
+	"bash":       {"function_definition": true},
+}
+
+// symbolBreadcrumb builds "module.Outer.inner" for node from its enclosing named scopes. The module
+// part is the file name without extension. Anonymous JS/TS functions bound to a variable
+// (const handler = () => ...) are named after the variable.
+func symbolBreadcrumb(node *sitter.Node, contentBytes []byte, langName, filePath string) string {
+	scopeTypes := symbolNodeTypes[langName]
+	var parts []string
+	for current := node.Parent(); current != nil; current = current.Parent() {
+		name := ""
+		nodeType := current.Type()
+		if scopeTypes[nodeType] {
+			if nameNode := current.ChildByFieldName("name"); nameNode != nil {
+				name = nameNode.Content(contentBytes)
+			}
+		} else if nodeType == "arrow_function" || nodeType == "function_expression" || nodeType == "function" {
+			if parent := current.Parent(); parent != nil && parent.Type() == "variable_declarator" {
+				if nameNode := parent.ChildByFieldName("name"); nameNode != nil {
+					name = nameNode.Content(contentBytes)
+				}
+			}
+		}
+		if name != "" {
+			parts = append([]string{name}, parts...)
+		}
+	}
+	module := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	return strings.Join(append([]string{module}, parts...), ".")
+}
+
+// bashContext finds the variable a shell string is assigned to and the command it is passed to.
+// It looks through command substitutions, so PROMPT=$(cat <<EOF ...) yields "PROMPT" and "cat".
+func bashContext(node *sitter.Node, contentBytes []byte) (varName, commandName string) {
+	for current := node; current != nil; current = current.Parent() {
+		switch current.Type() {
+		case "command":
+			if nameNode := current.ChildByFieldName("name"); nameNode != nil && commandName == "" {
+				commandName = nameNode.Content(contentBytes)
+			}
+		case "redirected_statement":
+			if body := current.ChildByFieldName("body"); body != nil && body.Type() == "command" && commandName == "" {
+				if nameNode := body.ChildByFieldName("name"); nameNode != nil {
+					commandName = nameNode.Content(contentBytes)
+				}
+			}
+		case "variable_assignment":
+			if nameNode := current.ChildByFieldName("name"); nameNode != nil {
+				varName = nameNode.Content(contentBytes)
+			}
+			return varName, commandName
+		case "program", "function_definition", "compound_statement", "if_statement", "for_statement", "while_statement", "case_item":
+			return varName, commandName
+		}
+	}
+	return varName, commandName
+}
+
 // determineContextAroundNode walks the AST upwards from stringNode to find its context.
 func determineContextAroundNode(stringNode *sitter.Node, contentBytes []byte, langName string) (varName, invFuncName, invReceiverName string) {
 	current := stringNode
@@ -166,6 +286,14 @@ func determineContextAroundNode(stringNode *sitter.Node, contentBytes []byte, la
 						varName = leftNode.Content(contentBytes)
 					}
 				}
+			case "array_element_initializer": // PHP: ['key' => "value"]
+				if parentNode.ChildCount() >= 3 && parentNode.Child(int(parentNode.ChildCount())-1).ID() == current.ID() {
+					varName = strings.Trim(parentNode.Child(0).Content(contentBytes), `"'`)
+				}
+			case "const_element", "property_element": // PHP: const NAME = "value"; public $name = "value";
+				if parentNode.ChildCount() > 0 && parentNode.Child(0).ID() != current.ID() {
+					varName = parentNode.Child(0).Content(contentBytes)
+				}
 			case "pair": // JSON: "key": "value" (value is our string)
 				if valNode := parentNode.ChildByFieldName("value"); valNode != nil && valNode.ID() == current.ID() {
 					if keyNode := parentNode.ChildByFieldName("key"); keyNode != nil {
@@ -196,7 +324,15 @@ func determineContextAroundNode(stringNode *sitter.Node, contentBytes []byte, la
 					switch callLikeNode.Type() {
 					case "call_expression", "call":
 						var funcNode *sitter.Node
-						if langName == "python" && callLikeNode.Type() == "call" {
+						if langName == "ruby" && callLikeNode.Type() == "call" {
+							// Ruby calls expose receiver and method as separate fields.
+							if recvN := callLikeNode.ChildByFieldName("receiver"); recvN != nil {
+								invReceiverName = recvN.Content(contentBytes)
+							}
+							if methodN := callLikeNode.ChildByFieldName("method"); methodN != nil {
+								invFuncName = methodN.Content(contentBytes)
+							}
+						} else if langName == "python" && callLikeNode.Type() == "call" {
 							if callLikeNode.ChildCount() > 0 {
 								funcNode = callLikeNode.Child(0)
 							}
@@ -227,11 +363,43 @@ func determineContextAroundNode(stringNode *sitter.Node, contentBytes []byte, la
 								}
 							}
 						}
+					case "method_invocation": // Java: receiver.method("...")
+						if objN := callLikeNode.ChildByFieldName("object"); objN != nil {
+							invReceiverName = objN.Content(contentBytes)
+						}
+						if nameN := callLikeNode.ChildByFieldName("name"); nameN != nil {
+							invFuncName = nameN.Content(contentBytes)
+						}
 					case "new_expression":
 						invReceiverName = "new"
 						if constructorNode := callLikeNode.ChildByFieldName("constructor"); constructorNode != nil {
 							invFuncName = constructorNode.Content(contentBytes)
 						}
+					case "object_creation_expression": // Java/PHP: new Foo("...")
+						invReceiverName = "new"
+						if typeNode := callLikeNode.ChildByFieldName("type"); typeNode != nil {
+							invFuncName = typeNode.Content(contentBytes)
+						} else {
+							for i := 0; i < int(callLikeNode.ChildCount()); i++ {
+								if child := callLikeNode.Child(i); child.Type() == "name" || child.Type() == "qualified_name" {
+									invFuncName = child.Content(contentBytes)
+									break
+								}
+							}
+						}
+					case "function_call_expression": // PHP: func("...")
+						if funcN := callLikeNode.ChildByFieldName("function"); funcN != nil {
+							invFuncName = funcN.Content(contentBytes)
+						}
+					case "member_call_expression", "scoped_call_expression": // PHP: $obj->method("..."), Cls::method("...")
+						if objN := callLikeNode.ChildByFieldName("object"); objN != nil {
+							invReceiverName = objN.Content(contentBytes)
+						} else if scopeN := callLikeNode.ChildByFieldName("scope"); scopeN != nil {
+							invReceiverName = scopeN.Content(contentBytes)
+						}
+						if nameN := callLikeNode.ChildByFieldName("name"); nameN != nil {
+							invFuncName = nameN.Content(contentBytes)
+						}
 					}
 					if invFuncName != "" || invReceiverName != "" {
 						return varName, invFuncName, invReceiverName
@@ -287,6 +455,274 @@ func unescapeJSString(s string) string {
 	return s
 }
 
+// unescapeRubyString processes the escapes of a double-quoted Ruby string in one left-to-right
+// pass, so that an escaped backslash followed by "n" ("C:\\new") stays a backslash and an "n".
+// Escapes other than \n, \t, \', \" and \\ are kept verbatim.
+func unescapeRubyString(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case '\'', '"', '\\':
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// rubyStringContent returns the body of a Ruby string literal ("...", '...', %q(...), %Q{...}, %(...)).
+// Interpolations are kept verbatim; escapes are only processed for interpolating literals.
+func rubyStringContent(stringNode *sitter.Node, contentBytes []byte) string {
+	if stringNode.ChildCount() < 2 {
+		return ""
+	}
+	opening := stringNode.Child(0)
+	closing := stringNode.Child(int(stringNode.ChildCount()) - 1)
+	if opening.EndByte() > closing.StartByte() {
+		return ""
+	}
+	body := string(contentBytes[opening.EndByte():closing.StartByte()])
+	delimiter := opening.Content(contentBytes)
+	if delimiter == "'" || strings.HasPrefix(delimiter, "%q") {
+		body = strings.ReplaceAll(body, "\\'", "'")
+		return strings.ReplaceAll(body, "\\\\", "\\")
+	}
+	return unescapeRubyString(body)
+}
+
+// javaStringContent returns the value of a Java string literal. Text blocks ("""...""") have their
+// opening line dropped and incidental indentation stripped, following JLS 3.10.6.
+func javaStringContent(raw string) (content string, isTextBlock bool) {
+	if strings.HasPrefix(raw, `"""`) && strings.HasSuffix(raw, `"""`) && len(raw) >= 6 {
+		body := raw[3 : len(raw)-3]
+		if idx := strings.Index(body, "\n"); idx >= 0 {
+			body = body[idx+1:]
+		}
+		// The closing delimiter's indentation participates in the common-indent computation,
+		// so keep a placeholder for it while dedenting.
+		closingOnOwnLine := false
+		if idx := strings.LastIndex(body, "\n"); idx >= 0 && strings.TrimSpace(body[idx+1:]) == "" {
+			closingOnOwnLine = true
+			body += "x"
+		}
+		body = dedentLines(body)
+		if closingOnOwnLine {
+			body = body[:strings.LastIndex(body, "\n")+1]
+		}
+		lines := strings.Split(body, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " \t")
+		}
+		body = strings.Join(lines, "\n")
+		body = strings.ReplaceAll(body, "\\\n", "") // Line continuation
+		body = strings.ReplaceAll(body, "\\s", " ")
+		return unescapeJSString(body), true
+	}
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+		return unescapeJSString(raw[1 : len(raw)-1]), false
+	}
+	return raw, false
+}
+
+func unescapePHPString(s string) string {
+	s = strings.ReplaceAll(s, "\\n", "\n")
+	s = strings.ReplaceAll(s, "\\t", "\t")
+	s = strings.ReplaceAll(s, "\\\"", "\"")
+	s = strings.ReplaceAll(s, "\\$", "$")
+	s = strings.ReplaceAll(s, "\\\\", "\\")
+	return s
+}
+
+// phpStringContent returns the value of a PHP string, encapsed (double-quoted) string, heredoc or
+// nowdoc node. Interpolated variables are kept verbatim. For heredocs/nowdocs, the indentation of
+// the closing marker is removed from every line (PHP 7.3+ flexible heredoc semantics).
+func phpStringContent(stringNode *sitter.Node, contentBytes []byte) string {
+	switch stringNode.Type() {
+	case "heredoc", "nowdoc":
+		var bodyStart, bodyEnd uint32
+		var endTag *sitter.Node
+		for i := 0; i < int(stringNode.ChildCount()); i++ {
+			child := stringNode.Child(i)
+			switch child.Type() {
+			case "heredoc_start":
+				bodyStart = child.EndByte()
+			case "heredoc_end":
+				endTag = child
+			}
+		}
+		if endTag == nil || bodyStart == 0 || bodyStart > endTag.StartByte() {
+			return ""
+		}
+		bodyEnd = endTag.StartByte()
+		body := string(contentBytes[bodyStart:bodyEnd])
+		if idx := strings.Index(body, "\n"); idx >= 0 {
+			body = body[idx+1:] // Drop the rest of the opening line (closing quote of <<<'NOW')
+		}
+		if idx := strings.LastIndex(body, "\n"); idx >= 0 && strings.TrimSpace(body[idx:]) == "" {
+			body = body[:idx]
+		}
+		if indent := int(endTag.StartPoint().Column); indent > 0 {
+			lines := strings.Split(body, "\n")
+			for i, line := range lines {
+				trimmed := strings.TrimLeft(line, " \t")
+				if removable := len(line) - len(trimmed); removable > indent {
+					lines[i] = line[indent:]
+				} else {
+					lines[i] = trimmed
+				}
+			}
+			body = strings.Join(lines, "\n")
+		}
+		if stringNode.Type() == "heredoc" {
+			body = unescapePHPString(body)
+		}
+		return body
+	}
+
+	raw := stringNode.Content(contentBytes)
+	if len(raw) < 2 {
+		return ""
+	}
+	// Strip an optional binary-string prefix (b"...") before the quotes.
+	if raw[0] == 'b' || raw[0] == 'B' {
+		raw = raw[1:]
+	}
+	body := raw[1 : len(raw)-1]
+	if raw[0] == '\'' {
+		body = strings.ReplaceAll(body, "\\'", "'")
+		return strings.ReplaceAll(body, "\\\\", "\\")
+	}
+	return unescapePHPString(body)
+}
+
+// findHeredocBeginning locates the heredoc_beginning node (e.g. <<~EOS) that opens heredocBody.
+// Ruby's grammar places heredoc bodies after the statement that opened them, so this searches
+// the preceding siblings (skipping other heredoc bodies started on the same line).
+func findHeredocBeginning(heredocBody *sitter.Node, contentBytes []byte) *sitter.Node {
+	endTag := ""
+	for i := 0; i < int(heredocBody.ChildCount()); i++ {
+		if child := heredocBody.Child(i); child.Type() == "heredoc_end" {
+			endTag = strings.TrimSpace(child.Content(contentBytes))
+		}
+	}
+
+	var search func(n *sitter.Node) *sitter.Node
+	search = func(n *sitter.Node) *sitter.Node {
+		if n.Type() == "heredoc_beginning" {
+			if tag := strings.Trim(n.Content(contentBytes), "<~-'\"`"); tag == endTag || endTag == "" {
+				return n
+			}
+		}
+		for i := 0; i < int(n.ChildCount()); i++ {
+			if found := search(n.Child(i)); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+
+	for sibling := heredocBody.PrevSibling(); sibling != nil; sibling = sibling.PrevSibling() {
+		if sibling.Type() == "heredoc_body" {
+			continue
+		}
+		return search(sibling)
+	}
+	return nil
+}
+
+// rubyHeredocContent extracts the text of a heredoc body, dropping the terminator line and
+// removing common indentation for squiggly (<<~) heredocs.
+func rubyHeredocContent(heredocBody, beginning *sitter.Node, contentBytes []byte) string {
+	bodyEnd := heredocBody.EndByte()
+	for i := 0; i < int(heredocBody.ChildCount()); i++ {
+		if child := heredocBody.Child(i); child.Type() == "heredoc_end" {
+			bodyEnd = child.StartByte()
+		}
+	}
+	body := string(contentBytes[heredocBody.StartByte():bodyEnd])
+	body = strings.TrimPrefix(body, "\n")
+	if idx := strings.LastIndex(body, "\n"); idx >= 0 && strings.TrimSpace(body[idx:]) == "" {
+		body = body[:idx]
+	}
+
+	marker := ""
+	if beginning != nil && beginning.Type() == "heredoc_beginning" {
+		marker = beginning.Content(contentBytes)
+	}
+	if strings.HasPrefix(marker, "<<~") {
+		body = dedentLines(body)
+	}
+	if !strings.Contains(marker, "'") {
+		body = unescapeRubyString(body)
+	}
+	return body
+}
+
+// bashStringContent returns the text of a shell string literal or heredoc body. Double-quoted
+// strings keep their $expansions verbatim; <<- heredocs have leading tabs stripped.
+func bashStringContent(stringNode *sitter.Node, contentBytes []byte) string {
+	raw := stringNode.Content(contentBytes)
+	switch stringNode.Type() {
+	case "raw_string":
+		return strings.TrimSuffix(strings.TrimPrefix(raw, "'"), "'")
+	case "string":
+		raw = strings.TrimSuffix(strings.TrimPrefix(raw, `"`), `"`)
+		return strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\$`, `$`, "\\`", "`", "\\\n", "").Replace(raw)
+	case "heredoc_body":
+		content := strings.TrimSuffix(strings.TrimRight(raw, " \t"), "\n")
+		if redirect := stringNode.Parent(); redirect != nil && redirect.ChildCount() > 0 && redirect.Child(0).Type() == "<<-" {
+			lines := strings.Split(content, "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimLeft(line, "\t")
+			}
+			content = strings.Join(lines, "\n")
+		}
+		return content
+	}
+	return raw
+}
+
+// dedentLines removes the longest common leading whitespace from all non-blank lines.
+func dedentLines(s string) string {
+	lines := strings.Split(s, "\n")
+	minIndent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if minIndent == -1 || indent < minIndent {
+			minIndent = indent
+		}
+	}
+	if minIndent <= 0 {
+		return s
+	}
+	for i, line := range lines {
+		if len(line) >= minIndent {
+			lines[i] = line[minIndent:]
+		} else {
+			lines[i] = strings.TrimLeft(line, " \t")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (s *Scanner) ParseTreeSitterFile(filePath string, contentBytes []byte, langName string) ([]FoundPrompt, error) {
 	lang, supported := langToGrammar[langName]
 	if !supported {
@@ -297,19 +733,19 @@ func (s *Scanner) ParseTreeSitterFile(filePath string, contentBytes []byte, lang
 		return nil, fmt.Errorf("tree-sitter query for '%s' not defined or empty after cleaning", langName)
 	}
 
-	parser := sitter.NewParser()
-	parser.SetLanguage(lang)
-	tree, err := parser.ParseCtx(context.Background(), nil, contentBytes)
+	tree, err := s.grammars.parse(s.context(), langName, lang, contentBytes, s.Options.ParseTimeout)
 	if err != nil {
 		return nil, fmt.Errorf("ts parsing error for %s: %w", filePath, err)
 	}
 	defer tree.Close()
 
-	q, err := sitter.NewQuery([]byte(queryString), lang)
+	q, release, err := s.grammars.query(langName, queryString, lang)
 	if err != nil {
 		return nil, fmt.Errorf("ts query compilation error for %s (cleaned query: \n%s\nError: %w)", langName, queryString, err)
 	}
-	defer q.Close()
+	if release {
+		defer q.Close()
+	}
 
 	qc := sitter.NewQueryCursor()
 	qc.Exec(q, tree.RootNode())
@@ -336,7 +772,8 @@ func (s *Scanner) ParseTreeSitterFile(filePath string, contentBytes []byte, lang
 				break
 			}
 			if captureName == "string_node" {
-				if strings.Contains(nodeTypeStr, "string") || nodeTypeStr == "template_string" || nodeTypeStr == "string_fragment" {
+				if strings.Contains(nodeTypeStr, "string") || nodeTypeStr == "template_string" || nodeTypeStr == "string_fragment" ||
+					nodeTypeStr == "heredoc_body" || nodeTypeStr == "heredoc" || nodeTypeStr == "nowdoc" {
 					stringNode = node
 				}
 			}
@@ -346,11 +783,11 @@ func (s *Scanner) ParseTreeSitterFile(filePath string, contentBytes []byte, lang
 			continue
 		}
 
-		// If this node is a string_fragment and its parent is a template_string,
-		// skip it because the entire template_string will be processed.
+		// If this node is a string_fragment and its parent is a template_string or string,
+		// skip it because the entire parent literal will be processed.
 		if stringNode.Type() == "string_fragment" {
 			parentNode := stringNode.Parent()
-			if parentNode != nil && parentNode.Type() == "template_string" {
+			if parentNode != nil && (parentNode.Type() == "template_string" || parentNode.Type() == "string") {
 				continue
 			}
 		}
@@ -360,7 +797,23 @@ func (s *Scanner) ParseTreeSitterFile(filePath string, contentBytes []byte, lang
 		}
 		processedNodeIDs[stringNode.ID()] = true
 
-		varName, invFuncName, invReceiverName := determineContextAroundNode(stringNode, contentBytes, langName)
+		contextNode := stringNode
+		if stringNode.Type() == "heredoc_body" {
+			// A heredoc's context (assignment, call) lives around its opening marker, not its body.
+			if langName == "bash" {
+				if redirect := stringNode.Parent(); redirect != nil && redirect.Parent() != nil {
+					contextNode = redirect.Parent()
+				}
+			} else if beginning := findHeredocBeginning(stringNode, contentBytes); beginning != nil {
+				contextNode = beginning
+			}
+		}
+		var varName, invFuncName, invReceiverName string
+		if langName == "bash" {
+			varName, invFuncName = bashContext(contextNode, contentBytes)
+		} else {
+			varName, invFuncName, invReceiverName = determineContextAroundNode(contextNode, contentBytes, langName)
+		}
 
 		rawStringNodeContent := stringNode.Content(contentBytes)
 		actualContent := ""
@@ -501,14 +954,59 @@ func (s *Scanner) ParseTreeSitterFile(filePath string, contentBytes []byte, lang
 			if !isMultiLineExplicit && stringNode.StartPoint().Row != stringNode.EndPoint().Row {
 				isMultiLineExplicit = true
 			}
+
+		case "java":
+			actualContent, isMultiLineExplicit = javaStringContent(rawStringNodeContent)
+			if strings.Contains(actualContent, "\n") {
+				isMultiLineExplicit = true
+			}
+
+		case "php":
+			actualContent = phpStringContent(stringNode, contentBytes)
+			isMultiLineExplicit = nodeType == "heredoc" || nodeType == "nowdoc" || strings.Contains(actualContent, "\n") ||
+				stringNode.StartPoint().Row != stringNode.EndPoint().Row
+
+		case "bash":
+			actualContent = bashStringContent(stringNode, contentBytes)
+			isMultiLineExplicit = nodeType == "heredoc_body" || strings.Contains(actualContent, "\n")
+			// JSON request bodies (curl -d '{"messages": ...}') are scanned value by value.
+			if trimmed := strings.TrimSpace(actualContent); (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+				payloadPrompts, err := s.ParseJSONFile(filePath, []byte(trimmed))
+				if err == nil {
+					for _, p := range payloadPrompts {
+						p.Line = int(contextNode.StartPoint().Row + 1)
+						p.EndLine = int(stringNode.EndPoint().Row + 1)
+						p.Symbol = symbolBreadcrumb(contextNode, contentBytes, langName, filePath)
+						prompts = append(prompts, p)
+					}
+					continue
+				}
+			}
+
+		case "ruby":
+			if nodeType == "heredoc_body" {
+				isMultiLineExplicit = true
+				actualContent = rubyHeredocContent(stringNode, contextNode, contentBytes)
+			} else {
+				actualContent = rubyStringContent(stringNode, contentBytes)
+				if strings.Contains(actualContent, "\n") || stringNode.StartPoint().Row != stringNode.EndPoint().Row {
+					isMultiLineExplicit = true
+				}
+			}
 		}
 
-		startLine := int(stringNode.StartPoint().Row + 1)
+		// Heredocs are reported at the line of their opening marker.
+		startLine := int(contextNode.StartPoint().Row + 1)
+		if s.skipLiteral(actualContent, startLine, varName, isMultiLineExplicit) {
+			continue
+		}
 		linesInContent := utils.CountNewlines(actualContent) + 1
 
 		fp := FoundPrompt{
 			Filepath:    filePath,
 			Line:        startLine,
+			EndLine:     int(stringNode.EndPoint().Row + 1),
+			Symbol:      symbolBreadcrumb(contextNode, contentBytes, langName, filePath),
 			Content:     actualContent,
 			IsMultiLine: isMultiLineExplicit || linesInContent > 1,
 		}
@@ -522,7 +1020,7 @@ func (s *Scanner) ParseTreeSitterFile(filePath string, contentBytes []byte, lang
 			InvocationReceiverName: invReceiverName,
 		}
 
-		if s.IsPotentialPrompt(context, &fp) {
+		if s.evaluateCandidate(context, &fp) {
 			prompts = append(prompts, fp)
 		}
 	}
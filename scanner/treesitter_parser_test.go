@@ -0,0 +1,21 @@
+// scanner/treesitter_parser_test.go
+package scanner
+
+import "testing"
+
+func TestUnescapeRubyString(t *testing.T) {
+	for in, want := range map[string]string{
+		`C:\\new`:            `C:\new`,
+		`\\n`:                `\n`,
+		`line\nnext`:         "line\nnext",
+		`tab\there`:          "tab\there",
+		`say \"hi\" 'x'`:     `say "hi" 'x'`,
+		`\\\n`:               "\\\n",
+		`keep \e and \u00e9`: `keep \e and \u00e9`,
+		`trailing \`:         `trailing \`,
+	} {
+		if got := unescapeRubyString(in); got != want {
+			t.Errorf("unescapeRubyString(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
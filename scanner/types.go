@@ -1,7 +1,10 @@
 // scanner/types.go
 package scanner
 
-import "regexp"
+import (
+	"regexp"
+	"time"
+)
 
 // ScanOptions holds the configuration for a scan.
 type ScanOptions struct {
@@ -10,32 +13,230 @@ type ScanOptions struct {
 	ContentKeywords     []string
 	PlaceholderPatterns []string
 	ScanConfigs         bool
-	Greedy              bool
-	UseGitignore        bool
-	Verbose             bool
+	ConstantsFiles      bool // Report every long, sentence-like string in files that are mostly string assignments
+	ScanText            bool // Treat .txt, .prompt and extensionless files under prompts/ as whole-file candidates
+	ScanMinified        bool // Also scan minified and bundled JavaScript, skipped by default (see Scanner.SkippedMinifiedFiles)
+	Workers             int  // Number of files parsed concurrently; 0 uses one worker per CPU
+	// PromptFilenamePatterns are file name globs (e.g. "*prompt*.yaml") of config files that are
+	// scanned even without ScanConfigs.
+	PromptFilenamePatterns []string
+	// Mode names the heuristic preset (ModeStrict, ModeBalanced or ModeGreedy) whose rules decide
+	// which candidates are prompts; see Preset. Empty selects ModeGreedy if Greedy is set and
+	// ModeBalanced otherwise.
+	Mode string
+	// Greedy selects ModeGreedy when Mode is empty.
+	//
+	// Deprecated: set Mode to ModeGreedy instead.
+	Greedy        bool
+	UseGitignore  bool
+	Verbose       bool
+	MultilineOnly bool // Only report prompts spanning more than one line
+	MinLines      int  // Minimum number of content lines for a prompt to be reported (0 or 1 disables)
+	// IgnoreKeys are config key patterns (e.g. "description", "changelog.*") whose values are not
+	// scanned. See Scanner.isIgnoredKey for the matching rules.
+	IgnoreKeys   []string
+	LintOnly     bool // Only report findings that have lint issues
+	QualityLints bool // Also run advisory prompt-quality lints (long sentences, contradictions, control characters)
+	// IncludeRejected also returns the candidates the heuristics rejected, with Rejected set and a
+	// RejectReason code, in line order among the accepted findings of each file.
+	IncludeRejected bool
+	Policy          *Policy // Token-budget rules evaluated against each finding, nil to disable
+	// Classifier decides which candidate strings are prompts in place of the built-in heuristics
+	// (see PromptClassifier); nil uses the heuristics.
+	Classifier PromptClassifier
+	// Tokenizer counts the Tokens of findings, e.g. one read by LoadTokenizer for the model the
+	// prompts target; nil estimates them with EstimateTokens.
+	Tokenizer Tokenizer
+	// LanguageOverrides replaces MinLength and keyword sets per language name ("go") or file
+	// extension (".prompt"). See LoadLanguageOverrides.
+	LanguageOverrides map[string]LanguageOverride
+	// PathOverrides replaces options for, or skips, the files matching path globs. They take
+	// precedence over LanguageOverrides.
+	PathOverrides []PathOverride
+	// Adaptive profiles the string literals of the scanned tree before the scan and raises
+	// MinLength for languages whose literals run long (see adaptThresholds).
+	Adaptive bool
+	// TempDir is the directory repository clones are created in; empty uses os.TempDir, which
+	// honors $TMPDIR.
+	TempDir string
+	// SkipFiles are files that are never scanned, such as baseline or report files the scanner
+	// itself writes into the scanned tree.
+	SkipFiles []string
+	// OnlyFiles, when non-nil, replaces the directory walk: only these files are scanned, given
+	// relative to the scanned root or as absolute paths. Missing files and files a walk would skip
+	// (ignored, or under skipped directories) are left out. See ReadFindings for rescanning the
+	// files of an earlier report.
+	OnlyFiles []string
+	// MaxFileSize skips files larger than this many bytes without reading them; 0 scans files of
+	// any size. See Scanner.SkippedLargeFiles.
+	MaxFileSize int64
+	// ParseTimeout gives up on files tree-sitter takes longer than this to parse, so that one
+	// pathological file cannot hold up the scan; 0 waits for every file. See
+	// Scanner.TimedOutFiles.
+	ParseTimeout time.Duration
+	// MaxNestingDepth bounds how deep code held in other code is parsed: 1 parses a script held
+	// in a YAML value, 2 also code in a JSON payload that script sends, and so on. Deeper code
+	// is reported and left unparsed. 0 uses DefaultMaxNestingDepth; a negative value parses no
+	// embedded code.
+	MaxNestingDepth int
+	// Include and Exclude are doublestar globs (e.g. "src/**/*.py", "**/fixtures/**") matched
+	// against file paths relative to the scanned root, with forward slashes. When Include is set
+	// only the files matching one of its globs are scanned; files matching an Exclude glob never
+	// are. An Exclude glob ending in "/**" also prunes the directories it matches from the walk.
+	Include []string
+	Exclude []string
+	// NoDefaultExcludes also scans the lockfiles and data assets in DefaultExcludeList.
+	NoDefaultExcludes bool
+	// Baseline, if set, holds back the findings it already records (see Baseline.NewFindings).
+	Baseline *Baseline
+	// Index, if set, records every candidate string literal of the scanned files, reported or not,
+	// for later queries without re-parsing (see LiteralIndex).
+	Index *LiteralIndex
+	// AnonymousStats, if set, counts the findings per rule, language, severity and kind, and the
+	// PragmaIgnore marks, without recording any content (see AnonymousStatsReport).
+	AnonymousStats *AnonymousStats
+
+	// Progress, if set, is called as files are queued and scanned. It is called from multiple
+	// goroutines and must be safe for concurrent use.
+	Progress func(ProgressEvent)
+
+	// TraceLocation selects the candidates whose heuristics are traced: "path:line" matches
+	// candidates spanning that line in files whose path relative to the scanned root ends with
+	// path; a bare path traces every candidate in the file.
+	TraceLocation string
+	// Trace receives one line per rule evaluated for traced candidates. It may be called from
+	// multiple goroutines when TraceLocation matches several files.
+	Trace func(step string)
 
 	compiledVarKeywords  *regexp.Regexp
 	compiledContentWords *regexp.Regexp
 	compiledPlaceholders []*regexp.Regexp
+	compiledIgnoreKeys   []*regexp.Regexp
+	compiledTrace        *traceLocation
 }
 
 // FoundPrompt represents a potential LLM prompt found in a file.
 type FoundPrompt struct {
+	ID       string `json:"id,omitempty"` // Stable identifier that survives edits to the prompt text (see assignFindingIDs)
 	Filepath string `json:"filepath"`
 	Line     int    `json:"line"`
 	Content  string `json:"content"`
+	EndLine  int    `json:"end_line,omitempty"` // Last source line of the literal, 0 if unknown
+	Symbol   string `json:"symbol,omitempty"`   // Enclosing symbol breadcrumb, e.g. "module.ClassName.method_name"
+	Severity string `json:"severity,omitempty"` // SeverityHigh, SeverityMedium or SeverityLow
+	Audience string `json:"audience,omitempty"` // AudienceModel or AudienceHuman
+	Kind     string `json:"kind,omitempty"`     // Specific finding kind such as KindRAGScaffold, empty for plain prompts
+	Marked   bool   `json:"marked,omitempty"`   // Reported because of a PragmaPrompt comment
+	Rejected bool   `json:"rejected,omitempty"` // A candidate the heuristics rejected (see ScanOptions.IncludeRejected)
+	// RejectReason is the code of the rule that rejected the candidate, e.g. RejectLowScore.
+	RejectReason string   `json:"reject_reason,omitempty"`
+	Slots        []string `json:"slots,omitempty"` // Template slot names, e.g. ["context", "question"]
+	// OutputContracts are the response-format instructions embedded in the prompt.
+	OutputContracts []OutputContract `json:"output_contracts,omitempty"`
+	// Variables is the inferred schema of the template slots.
+	Variables []TemplateVariable `json:"variables,omitempty"`
+	// Lints are problems found in the prompt, such as placeholders missing from input_variables.
+	Lints []Lint `json:"lints,omitempty"`
+	// Embedded is set for findings in code or a template held by a config value, e.g. a Python
+	// script in a YAML "run" key.
+	Embedded *EmbeddedOrigin `json:"embedded,omitempty"`
+	// Tokens is the token count of Content, counted by ScanOptions.Tokenizer or estimated (see
+	// EstimateTokens).
+	Tokens int `json:"tokens,omitempty"`
+	// Model and Provider are inferred from the model name literal closest to the finding.
+	Model    string `json:"model,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	// PolicyViolations are the token-budget policy rules the finding breaks.
+	PolicyViolations []PolicyViolation `json:"policy_violations,omitempty"`
+	// Fingerprint identifies the prompt's text regardless of its location and whitespace (see
+	// PromptFingerprint).
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// VariableName is the variable, attribute or config key holding the string, if any.
+	VariableName string `json:"-"`
 
 	MatchedVariableName string
 	MatchedContentWord  string
 	MatchedPlaceholder  string
+	MatchedPath         string // Directory or file name that raised or lowered the score
 	IsMultiLine         bool
 }
 
 // JSONOutput is the structure for the --json flag output
 type JSONOutput struct {
-	Filepath string `json:"filepath"`
-	Line     int    `json:"line"`
-	Content  string `json:"content"`
+	ID          string   `json:"id,omitempty"`
+	Fingerprint string   `json:"fingerprint,omitempty"`
+	Filepath    string   `json:"filepath"`
+	Line        int      `json:"line"`
+	Content     string   `json:"content"`
+	Permalink   string   `json:"permalink,omitempty"` // Set when scanning a remote repository at a known commit
+	Symbol      string   `json:"symbol,omitempty"`
+	Severity    string   `json:"severity,omitempty"`
+	Audience    string   `json:"audience,omitempty"`
+	Kind        string   `json:"kind,omitempty"`
+	Marked      bool     `json:"marked,omitempty"`
+	Slots       []string `json:"slots,omitempty"`
+
+	Rejected     bool   `json:"rejected,omitempty"`
+	RejectReason string `json:"reject_reason,omitempty"`
+
+	OutputContracts []OutputContract   `json:"output_contracts,omitempty"`
+	Variables       []TemplateVariable `json:"variables,omitempty"`
+	Lints           []Lint             `json:"lints,omitempty"`
+	Embedded        *EmbeddedOrigin    `json:"embedded,omitempty"`
+
+	Tokens           int               `json:"tokens,omitempty"`
+	Model            string            `json:"model,omitempty"`
+	Provider         string            `json:"provider,omitempty"`
+	PolicyViolations []PolicyViolation `json:"policy_violations,omitempty"`
+
+	// Target is the path or repository URL the finding was found in, when a run scans several.
+	Target  string            `json:"target,omitempty"`
+	Project string            `json:"project,omitempty"`
+	Team    string            `json:"team,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// ProgressKind identifies the kind of a ProgressEvent.
+type ProgressKind int
+
+const (
+	FileQueued  ProgressKind = iota // A file was found by the walker and will be scanned
+	FileScanned                     // A file finished scanning
+)
+
+// ProgressEvent reports scan progress for a single file.
+type ProgressEvent struct {
+	Kind     ProgressKind
+	Filepath string
+	Language string        // Language or config format, e.g. "python" or "yaml"
+	Findings []FoundPrompt // FileScanned only: prompts found in the file
+	Err      error         // FileScanned only: error processing the file, if any
+}
+
+// ToolInfo identifies the program that produced a report.
+type ToolInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// HeuristicsVersion is the HeuristicsVersion of the built-in rules the tool scanned with.
+	HeuristicsVersion int `json:"heuristics_version,omitempty"`
+}
+
+// JSONEnvelope is the structure for the envelope output: findings plus scan metadata.
+// See OutputSchema for the published contract.
+type JSONEnvelope struct {
+	SchemaVersion string            `json:"schema_version"`
+	Tool          ToolInfo          `json:"tool"`
+	Target        string            `json:"target"`
+	Targets       []string          `json:"targets,omitempty"`
+	Commit        string            `json:"commit,omitempty"`
+	Ref           string            `json:"ref,omitempty"`
+	Project       string            `json:"project,omitempty"`
+	Team          string            `json:"team,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Hashes        *ScanHashes       `json:"hashes,omitempty"`
+	GeneratedAt   time.Time         `json:"generated_at"`
+	Summary       ScanSummary       `json:"summary"`
+	Findings      []JSONOutput      `json:"findings"`
 }
 
 // PromptContext provides context to the heuristic checker.
@@ -45,6 +246,8 @@ type PromptContext struct {
 	IsMultiLineExplicit    bool
 	LinesInContent         int
 	FileExtension          string
-	InvocationFunctionName string // e.g., "log", "info", "print" if string is a direct func arg
-	InvocationReceiverName string // e.g., "console", "logger", "fmt" if string is arg to a method call
+	InvocationFunctionName string   // e.g., "log", "info", "print" if string is a direct func arg
+	InvocationReceiverName string   // e.g., "console", "logger", "fmt" if string is arg to a method call
+	FileName               string   // Base name of the file, e.g. "system_prompt.py"
+	DirNames               []string // Directories between the scanned root and the file, e.g. ["src", "prompts"]
 }
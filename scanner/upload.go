@@ -0,0 +1,239 @@
+// scanner/upload.go
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// uploadAttempts is how often an upload is tried before it is spooled.
+	uploadAttempts = 3
+	// uploadBackoff is the wait before the second attempt; it doubles for each further attempt.
+	uploadBackoff = 2 * time.Second
+)
+
+// Uploader posts envelope reports to a central prompt inventory. Transient failures (network
+// errors, 429 and 5xx responses) are retried with exponential backoff; a report that still cannot
+// be delivered is spooled to disk and sent before the next report to the same URL, so runners
+// that are briefly offline lose nothing.
+type Uploader struct {
+	URL    string
+	APIKey string // Sent as a bearer token when set
+	// Secret, when set, signs each body with HMAC-SHA256 in the SignatureHeader header, so the
+	// receiver can check where a report came from without sharing an API key.
+	Secret    string
+	UserAgent string
+	// SpoolDir holds reports waiting to be delivered, in a subdirectory per URL. Empty uses
+	// DefaultSpoolDir.
+	SpoolDir string
+	// NoSpool disables the spool: undeliverable reports are returned as errors and reports already
+	// spooled are left alone, so the uploader never writes to disk.
+	NoSpool bool
+	Client  *http.Client // nil uses a client with a 30 second timeout
+	// Logf, if set, receives progress messages about retries and spooled reports.
+	Logf func(format string, args ...any)
+}
+
+// SignatureHeader carries the "sha256=<hex>" HMAC of the body of reports sent with a Secret.
+const SignatureHeader = "X-Prompt-Scanner-Signature"
+
+// ErrSpooled is returned, wrapped, by Upload when a report could not be delivered and was spooled
+// for a later attempt.
+var ErrSpooled = errors.New("report spooled for a later upload")
+
+// uploadError is a failed upload, with the HTTP status if the server answered and whether trying
+// again later can help.
+type uploadError struct {
+	err       error
+	status    int
+	transient bool
+}
+
+func (e *uploadError) Error() string { return e.err.Error() }
+func (e *uploadError) Unwrap() error { return e.err }
+
+// DefaultSpoolDir returns the spool directory under the user cache directory, or under the
+// temporary directory when there is none.
+func DefaultSpoolDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "prompt-scanner", "spool")
+}
+
+// Upload sends any spooled reports for the URL, oldest first, then envelope. When the spooled
+// reports cannot be delivered, or envelope fails with a transient error, envelope is spooled
+// behind them and an error wrapping ErrSpooled is returned. Other failures, such as a rejected
+// API key, are returned without spooling. With NoSpool set, Upload only sends envelope.
+func (u *Uploader) Upload(ctx context.Context, envelope JSONEnvelope) error {
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("marshalling upload: %w", err)
+	}
+	if u.NoSpool {
+		return u.post(ctx, body)
+	}
+	if err := u.flushSpool(ctx); err != nil {
+		if isAuthError(err) {
+			return err
+		}
+		// Keep the reports in order: queue this one behind those still waiting.
+		return u.spool(body, err)
+	}
+	if err := u.post(ctx, body); err != nil {
+		var uerr *uploadError
+		if errors.As(err, &uerr) && uerr.transient {
+			return u.spool(body, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// post sends body, retrying transient failures.
+func (u *Uploader) post(ctx context.Context, body []byte) error {
+	var err error
+	for attempt := 1; attempt <= uploadAttempts; attempt++ {
+		if attempt > 1 {
+			wait := uploadBackoff << (attempt - 2)
+			u.logf("Upload to %s failed (%v); retrying in %s", u.URL, err, wait)
+			select {
+			case <-ctx.Done():
+				return &uploadError{err: ctx.Err(), transient: true}
+			case <-time.After(wait):
+			}
+		}
+		err = u.postOnce(ctx, body)
+		var uerr *uploadError
+		if err == nil || !errors.As(err, &uerr) || !uerr.transient {
+			return err
+		}
+	}
+	return err
+}
+
+func (u *Uploader) postOnce(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid upload URL %s: %w", u.URL, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if u.UserAgent != "" {
+		req.Header.Set("User-Agent", u.UserAgent)
+	}
+	if u.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+u.APIKey)
+	}
+	if u.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(u.Secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	client := u.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return &uploadError{err: fmt.Errorf("uploading to %s: %w", u.URL, err), transient: true}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return &uploadError{
+		err:       fmt.Errorf("uploading to %s: %s: %s", u.URL, resp.Status, strings.TrimSpace(string(message))),
+		status:    resp.StatusCode,
+		transient: resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+	}
+}
+
+// spoolPath returns the spool subdirectory of the uploader's URL.
+func (u *Uploader) spoolPath() string {
+	dir := u.SpoolDir
+	if dir == "" {
+		dir = DefaultSpoolDir()
+	}
+	sum := sha256.Sum256([]byte(u.URL))
+	return filepath.Join(dir, hex.EncodeToString(sum[:6]))
+}
+
+// spool saves body for a later upload and returns the error that caused it, wrapped with
+// ErrSpooled.
+func (u *Uploader) spool(body []byte, cause error) error {
+	dir := u.spoolPath()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("%w; spooling failed: %v", cause, err)
+	}
+	name := fmt.Sprintf("%d.json", time.Now().UnixNano())
+	if err := os.WriteFile(filepath.Join(dir, name), body, 0o600); err != nil {
+		return fmt.Errorf("%w; spooling failed: %v", cause, err)
+	}
+	return fmt.Errorf("%w (%w, in %s)", cause, ErrSpooled, dir)
+}
+
+// flushSpool uploads the spooled reports for the URL, oldest first, removing each once delivered.
+// It stops at the first transient or authentication failure and returns it, keeping the queue.
+// Reports the server rejects for any other reason are dropped so they do not block the queue.
+func (u *Uploader) flushSpool(ctx context.Context) error {
+	dir := u.spoolPath()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		body, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if err := u.postOnce(ctx, body); err != nil {
+			var uerr *uploadError
+			if (errors.As(err, &uerr) && uerr.transient) || isAuthError(err) {
+				return err
+			}
+			u.logf("Dropping spooled report %s: %v", path, err)
+		} else {
+			u.logf("Uploaded spooled report %s", name)
+		}
+		os.Remove(path)
+	}
+	return nil
+}
+
+// isAuthError reports whether err is an upload the server refused with 401 or 403: a missing or
+// rejected API key, which no later attempt with the same key can get past.
+func isAuthError(err error) bool {
+	var uerr *uploadError
+	return errors.As(err, &uerr) && (uerr.status == http.StatusUnauthorized || uerr.status == http.StatusForbidden)
+}
+
+func (u *Uploader) logf(format string, args ...any) {
+	if u.Logf != nil {
+		u.Logf(format, args...)
+	}
+}
@@ -0,0 +1,237 @@
+// scanner/variables.go
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TemplateVariable is a template slot of a prompt with its inferred type, forming a small schema
+// of the prompt's inputs.
+type TemplateVariable struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // string, number, boolean, array or object
+}
+
+var (
+	// Template syntax that reveals a slot's type.
+	formatSpecPattern   = regexp.MustCompile(`\{\s*([A-Za-z_]\w*)\s*:[^{}]*[dfeg%n,]\s*\}`)
+	jinjaForPattern     = regexp.MustCompile(`\{%-?\s*for\s+([\w, ]+?)\s+in\s+([A-Za-z_][\w.]*)`)
+	jinjaIfPattern      = regexp.MustCompile(`\{%-?\s*(?:if|elif)\s+(?:not\s+)?([A-Za-z_][\w.]*)\s*-?%\}`)
+	handlebarsEach      = regexp.MustCompile(`\{\{#each\s+([A-Za-z_][\w.]*)`)
+	handlebarsIf        = regexp.MustCompile(`\{\{#(?:if|unless)\s+([A-Za-z_][\w.]*)`)
+	jinjaFilterPattern  = regexp.MustCompile(`\{\{\s*([A-Za-z_][\w.]*)\s*\|\s*(\w+)`)
+	arrayFilters        = map[string]bool{"join": true, "first": true, "last": true, "length": true, "count": true, "sort": true, "unique": true, "map": true, "select": true, "reject": true, "batch": true}
+	numberFilters       = map[string]bool{"round": true, "int": true, "float": true, "abs": true, "filesizeformat": true}
+	renderCallSuffixRef = `\s*\.\s*(?:format|render|format_messages|format_prompt|invoke|substitute|safe_substitute|partial|compile)\s*\(`
+)
+
+// inferVariables builds the variable schema of a finding from its slots, the template syntax
+// around them, and the arguments of format/render calls on its variable elsewhere in source.
+func inferVariables(p FoundPrompt, source []byte) []TemplateVariable {
+	types := make(map[string]string)
+	var order []string
+	set := func(name, typ string, override bool) {
+		if _, seen := types[name]; !seen {
+			order = append(order, name)
+			types[name] = typ
+		} else if override && typ != "" {
+			types[name] = typ
+		}
+	}
+
+	loopVars := make(map[string]bool)
+	for _, m := range jinjaForPattern.FindAllStringSubmatch(p.Content, -1) {
+		for _, v := range strings.Split(m[1], ",") {
+			loopVars[strings.TrimSpace(v)] = true
+		}
+		set(rootName(m[2]), "array", true)
+	}
+	for _, m := range handlebarsEach.FindAllStringSubmatch(p.Content, -1) {
+		set(rootName(m[1]), "array", true)
+	}
+	for _, slot := range p.Slots {
+		root := rootName(slot)
+		if loopVars[root] || root == "this" {
+			continue
+		}
+		if root != slot {
+			set(root, "object", false)
+		} else {
+			set(root, typeFromName(root), false)
+		}
+	}
+	for _, re := range []*regexp.Regexp{jinjaIfPattern, handlebarsIf} {
+		for _, m := range re.FindAllStringSubmatch(p.Content, -1) {
+			if name := rootName(m[1]); !loopVars[name] && name == m[1] {
+				set(name, "boolean", true)
+			}
+		}
+	}
+	for _, m := range formatSpecPattern.FindAllStringSubmatch(p.Content, -1) {
+		set(m[1], "number", true)
+	}
+	for _, m := range jinjaFilterPattern.FindAllStringSubmatch(p.Content, -1) {
+		if name := rootName(m[1]); !loopVars[name] && name == m[1] {
+			switch {
+			case arrayFilters[m[2]]:
+				set(name, "array", true)
+			case numberFilters[m[2]]:
+				set(name, "number", true)
+			}
+		}
+	}
+
+	// Literal arguments at render call sites are the strongest evidence.
+	for name, typ := range renderCallArgumentTypes(p.VariableName, source) {
+		if _, known := types[name]; known && typ != "" {
+			types[name] = typ
+		}
+	}
+
+	if len(order) == 0 {
+		return nil
+	}
+	variables := make([]TemplateVariable, 0, len(order))
+	for _, name := range order {
+		variables = append(variables, TemplateVariable{Name: name, Type: types[name]})
+	}
+	return variables
+}
+
+func rootName(slot string) string {
+	root, _, _ := strings.Cut(slot, ".")
+	return root
+}
+
+// typeFromName guesses a type from naming conventions, defaulting to string.
+func typeFromName(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasPrefix(lower, "is_") || strings.HasPrefix(lower, "has_") || strings.HasPrefix(lower, "should_") || strings.HasPrefix(lower, "use_"):
+		return "boolean"
+	case strings.HasPrefix(lower, "num_") || strings.HasPrefix(lower, "n_") || strings.HasSuffix(lower, "_count") || strings.HasSuffix(lower, "_id") ||
+		lower == "count" || lower == "limit" || lower == "max_tokens" || lower == "temperature" || lower == "age" || lower == "year":
+		return "number"
+	case strings.HasSuffix(lower, "_list") || lower == "items" || lower == "examples" || lower == "messages" || lower == "documents" || lower == "docs" || lower == "chunks":
+		return "array"
+	}
+	return "string"
+}
+
+// renderCallArgumentTypes finds calls such as PROMPT.format(name="x", count=3) or
+// chain.invoke({"question": q}) on varName and infers argument types from literal values.
+func renderCallArgumentTypes(varName string, source []byte) map[string]string {
+	name := varName[strings.LastIndexAny(varName, ".>:")+1:]
+	if name == "" || len(source) == 0 || !isIdentifier(name) {
+		return nil
+	}
+	callPattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + renderCallSuffixRef)
+	src := string(source)
+	types := make(map[string]string)
+	for _, loc := range callPattern.FindAllStringIndex(src, -1) {
+		args := balancedArgs(src[loc[1]:])
+		if trimmed := strings.TrimSpace(args); strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}") {
+			args = trimmed[1 : len(trimmed)-1] // A single dict/object argument
+		}
+		for _, arg := range splitTopLevel(args) {
+			key, value, ok := strings.Cut(arg, "=")
+			if !ok || strings.ContainsAny(key, `"':`) {
+				key, value, ok = strings.Cut(arg, ":")
+			}
+			key = strings.Trim(strings.TrimSpace(key), `"'`)
+			if !ok || !isIdentifier(key) {
+				continue
+			}
+			if typ := literalType(strings.TrimSpace(value)); typ != "" {
+				types[key] = typ
+			}
+		}
+	}
+	return types
+}
+
+// balancedArgs returns the text up to the parenthesis closing an already opened call.
+func balancedArgs(s string) string {
+	depth := 1
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case r == '(' || r == '[' || r == '{':
+			depth++
+		case r == ')' || r == ']' || r == '}':
+			depth--
+			if depth == 0 {
+				return s[:i]
+			}
+		}
+	}
+	return s
+}
+
+// splitTopLevel splits on commas that are not nested in brackets or quotes.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case r == '(' || r == '[' || r == '{':
+			depth++
+		case r == ')' || r == ']' || r == '}':
+			depth--
+		case r == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+var numberLiteral = regexp.MustCompile(`^-?\d[\d_]*(\.\d+)?$`)
+
+// literalType infers a type from a literal expression, or "" when it is not a literal.
+func literalType(expr string) string {
+	switch {
+	case expr == "":
+		return ""
+	case numberLiteral.MatchString(expr):
+		return "number"
+	case expr == "True" || expr == "False" || expr == "true" || expr == "false":
+		return "boolean"
+	case strings.HasPrefix(expr, "[") || strings.HasPrefix(expr, "list(") || strings.HasPrefix(expr, "Array"):
+		return "array"
+	case strings.HasPrefix(expr, "{") || strings.HasPrefix(expr, "dict("):
+		return "object"
+	case strings.HasPrefix(expr, `"`) || strings.HasPrefix(expr, "'") || strings.HasPrefix(expr, "`") ||
+		strings.HasPrefix(expr, `f"`) || strings.HasPrefix(expr, "str("):
+		return "string"
+	case strings.HasPrefix(expr, "len(") || strings.HasPrefix(expr, "int(") || strings.HasPrefix(expr, "float("):
+		return "number"
+	}
+	return ""
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
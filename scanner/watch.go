@@ -0,0 +1,106 @@
+// scanner/watch.go
+package scanner
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+// WatchEvent describes a file that changed while watching.
+type WatchEvent struct {
+	Filepath string
+	Findings []FoundPrompt // All findings in the file after the change
+	New      []FoundPrompt // Findings whose content was not in the file before the change
+}
+
+// fileState is what Watch remembers about a file between polls.
+type fileState struct {
+	modTime  time.Time
+	size     int64
+	contents map[string]bool // Contents of the file's findings
+}
+
+// Watch polls the files under rootDir every interval and rescans those that were added or
+// modified, calling onChange for each. initial holds the findings of the scan that preceded the
+// watch, so prompts that were already there are not reported as new. Watch returns when ctx is done.
+func (s *Scanner) Watch(ctx context.Context, rootDir string, initial []FoundPrompt, interval time.Duration, onChange func(WatchEvent)) error {
+	s.rootDir = rootDir
+	known := make(map[string]map[string]bool)
+	for _, p := range initial {
+		if known[p.Filepath] == nil {
+			known[p.Filepath] = make(map[string]bool)
+		}
+		known[p.Filepath][p.Content] = true
+	}
+	states := make(map[string]*fileState)
+	if err := s.walkFiles(ctx, rootDir, func(path, _ string) error {
+		if info, err := os.Stat(path); err == nil {
+			states[path] = &fileState{modTime: info.ModTime(), size: info.Size(), contents: known[path]}
+		}
+		return nil
+	}); err != nil {
+		return ignoreCanceled(ctx, err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		seen := make(map[string]bool, len(states))
+		err := s.walkFiles(ctx, rootDir, func(path, lang string) error {
+			seen[path] = true
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil
+			}
+			state := states[path]
+			if state != nil && state.modTime.Equal(info.ModTime()) && state.size == info.Size() {
+				return nil
+			}
+			if state == nil {
+				state = &fileState{}
+				states[path] = state
+			}
+			state.modTime, state.size = info.ModTime(), info.Size()
+
+			findings, err := s.processFile(ctx, path, lang, nil)
+			if err != nil && s.Options.Verbose {
+				log.Printf("Warning: Error processing file %q: %v", path, err)
+			}
+			event := WatchEvent{Filepath: path, Findings: findings}
+			contents := make(map[string]bool, len(findings))
+			for _, p := range findings {
+				contents[p.Content] = true
+				if !state.contents[p.Content] {
+					event.New = append(event.New, p)
+				}
+			}
+			state.contents = contents
+			onChange(event)
+			return nil
+		})
+		if err != nil {
+			return ignoreCanceled(ctx, err)
+		}
+		for path := range states {
+			if !seen[path] {
+				delete(states, path)
+			}
+		}
+	}
+}
+
+// ignoreCanceled returns nil for the error of a walk interrupted because ctx is done, which is how
+// Watch is meant to end.
+func ignoreCanceled(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+// scanner/xml_parser.go
+package scanner
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/alexferrari88/prompt-scanner/utils"
+)
+
+// xmlIdentifierAttrs name the attributes that identify an element better than its tag,
+// e.g. Android's <string name="system_prompt"> or Spring's <property name="systemPrompt">.
+var xmlIdentifierAttrs = []string{"name", "key", "id"}
+
+// xmlElement tracks an open element while its text content is collected.
+type xmlElement struct {
+	path     string
+	text     strings.Builder
+	textLine int
+}
+
+// ParseXMLFile parses XML resource and config files (Android strings.xml, Spring XML, ...).
+// Element text and attribute values are candidates; the dotted element path, with identifier
+// attributes such as name="..." standing in for the tag, is used as the variable name.
+func (s *Scanner) ParseXMLFile(filePath string, contentBytes []byte) ([]FoundPrompt, error) {
+	var prompts []FoundPrompt
+	ext := filepath.Ext(filePath)
+	decoder := xml.NewDecoder(bytes.NewReader(contentBytes))
+
+	evaluate := func(keyPath, val string, line int) {
+		if val == "" || s.isIgnoredKey(keyPath) || s.skipLiteral(val, line, keyPath, false) {
+			return
+		}
+		linesInContent := utils.CountNewlines(val) + 1
+		isMultiLineExplicit := strings.Contains(val, "\n")
+		fp := FoundPrompt{
+			Filepath:    filePath,
+			Line:        line,
+			EndLine:     line + utils.CountNewlines(val),
+			Content:     val,
+			IsMultiLine: isMultiLineExplicit || linesInContent > 1,
+		}
+		context := PromptContext{
+			Text:                val,
+			VariableName:        keyPath,
+			IsMultiLineExplicit: isMultiLineExplicit,
+			LinesInContent:      linesInContent,
+			FileExtension:       ext,
+		}
+		if s.evaluateCandidate(context, &fp) {
+			prompts = append(prompts, fp)
+		}
+	}
+
+	var stack []*xmlElement
+	for {
+		// The decoder's position before reading a token is where that token starts.
+		tokenLine, _ := decoder.InputPos()
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding XML from %s: %w", filePath, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			segment := t.Name.Local
+			for _, attrName := range xmlIdentifierAttrs {
+				if value := xmlAttr(t, attrName); value != "" {
+					segment = value
+					break
+				}
+			}
+			elementPath := segment
+			if len(stack) > 0 {
+				elementPath = stack[len(stack)-1].path + "." + segment
+			}
+			if s.isIgnoredKey(elementPath) {
+				// Skip the whole subtree, including its attributes.
+				if err := decoder.Skip(); err != nil {
+					return nil, fmt.Errorf("decoding XML from %s: %w", filePath, err)
+				}
+				continue
+			}
+			for _, attr := range t.Attr {
+				if isXMLIdentifierAttr(attr.Name.Local) || attr.Name.Space != "" {
+					continue
+				}
+				evaluate(elementPath+"."+attr.Name.Local, attr.Value, tokenLine)
+			}
+			stack = append(stack, &xmlElement{path: elementPath})
+		case xml.CharData:
+			if len(stack) == 0 {
+				continue
+			}
+			current := stack[len(stack)-1]
+			text := string(t)
+			if current.textLine == 0 && strings.TrimSpace(text) != "" {
+				leading := text[:len(text)-len(strings.TrimLeft(text, " \t\r\n"))]
+				current.textLine = tokenLine + utils.CountNewlines(leading)
+			}
+			current.text.WriteString(text)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				continue
+			}
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			evaluate(current.path, strings.TrimSpace(current.text.String()), current.textLine)
+		}
+	}
+	return prompts, nil
+}
+
+// xmlAttr returns the value of the named attribute, ignoring namespaces.
+func xmlAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func isXMLIdentifierAttr(name string) bool {
+	for _, attrName := range xmlIdentifierAttrs {
+		if name == attrName {
+			return true
+		}
+	}
+	return false
+}
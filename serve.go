@@ -0,0 +1,379 @@
+// serve.go
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexferrari88/prompt-scanner/scanner"
+)
+
+// Status of a scan job of the serve command.
+const (
+	jobQueued  = "queued"
+	jobRunning = "running"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+// scanJob is a scan requested from the serve command, as GET /results/{id} returns it. Report is
+// the envelope of a finished scan.
+type scanJob struct {
+	ID          string                `json:"id"`
+	Status      string                `json:"status"`
+	Target      string                `json:"target"`
+	Ref         string                `json:"ref,omitempty"`
+	Error       string                `json:"error,omitempty"`
+	SubmittedAt time.Time             `json:"submitted_at"`
+	FinishedAt  *time.Time            `json:"finished_at,omitempty"`
+	Report      *scanner.JSONEnvelope `json:"report,omitempty"`
+}
+
+// scanRequest is the JSON body of POST /scan for a repository.
+type scanRequest struct {
+	Repo string `json:"repo"`
+	Ref  string `json:"ref"`
+}
+
+// scanServer runs the scans submitted to the serve command and keeps their results for keep.
+type scanServer struct {
+	opts      scanner.ScanOptions
+	token     string
+	maxUpload int64
+	keep      time.Duration
+	slots     chan struct{} // Scans allowed to run at once
+	maxQueue  int           // Scans allowed to wait for a slot
+	ctx       context.Context
+
+	mu      sync.Mutex
+	jobs    map[string]*scanJob
+	pending int // Scans accepted and waiting for a slot
+}
+
+// runServeCommand implements "serve", which runs the scanner as an HTTP service: POST /scan
+// queues a scan of a GitHub repository or of an uploaded zip archive, and GET /results/{id}
+// returns its status and, once done, its envelope report.
+func runServeCommand(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "Address to listen on.")
+	token := fs.String("token", os.Getenv("PROMPT_SCANNER_TOKEN"), "Bearer token clients must send in the Authorization header (default $PROMPT_SCANNER_TOKEN; empty allows anyone who can reach -addr).")
+	maxUpload := byteSize(100 << 20)
+	fs.Var(&maxUpload, "max-upload", "Largest archive accepted by POST /scan, e.g. 500MB (default 100MB).")
+	concurrency := fs.Int("concurrency", 2, "Number of scans run at once; further scans wait in the queue.")
+	maxQueue := fs.Int("max-queue", 16, "Number of scans that may wait in the queue; further requests are refused with 503.")
+	keep := fs.Duration("keep", 24*time.Hour, "How long results are kept after a scan finishes.")
+	minLength := fs.Int("min-len", scanner.DefaultMinLength, "Minimum character length for a string to be considered a potential prompt.")
+	contentKeywordsStr := fs.String("content-keywords", scanner.DefaultContentKeywords, "Comma-separated keywords to search for within string content.")
+	scanConfigs := fs.Bool("scan-configs", false, "Also scan config files (.json, .yaml, .toml, .xml, .env).")
+	scanText := fs.Bool("scan-text", false, "Also scan .txt and .prompt files as whole-file prompts.")
+	useGitignore := fs.Bool("use-gitignore", false, "Skip files and directories listed in .gitignore files.")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging.")
+	presets := addPresetFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n  %s serve [-addr host:port] [options]\n\nServes a JSON API for running scans:\n  POST /scan          {\"repo\": \"https://github.com/owner/repo\", \"ref\": \"main\"}, or a zip archive\n                      (Content-Type: application/zip); answers 202 with the job id\n  GET  /results/{id}  the job's status, and its envelope report once done\n\nOptions:\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 0 || *concurrency < 1 || *maxQueue < 1 {
+		fs.Usage()
+		os.Exit(scanner.ExitError)
+	}
+	VLog = log.New(io.Discard, "", 0)
+	if *verbose {
+		VLog = log.New(os.Stderr, "", 0)
+	}
+
+	opts := scanner.ScanOptions{
+		MinLength:              *minLength,
+		VariableKeywords:       scanner.DefaultVarKeywordsList,
+		ContentKeywords:        splitAndTrim(*contentKeywordsStr),
+		PlaceholderPatterns:    scanner.DefaultPlaceholderPatternsList,
+		PromptFilenamePatterns: scanner.DefaultPromptFilenamePatternsList,
+		ScanConfigs:            *scanConfigs,
+		ScanText:               *scanText,
+		UseGitignore:           *useGitignore,
+		Verbose:                *verbose,
+		MaxFileSize:            scanner.DefaultMaxFileSize,
+		ParseTimeout:           scanner.DefaultParseTimeout,
+	}
+	preset, err := presets.resolve()
+	if err != nil {
+		fatalf("serve: %v", err)
+	}
+	applyPreset(fs, preset, &opts)
+	if _, err := scanner.New(opts); err != nil {
+		fatalf("serve: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	srv := &scanServer{
+		opts:      opts,
+		token:     *token,
+		maxUpload: int64(maxUpload),
+		keep:      *keep,
+		slots:     make(chan struct{}, *concurrency),
+		maxQueue:  *maxQueue,
+		ctx:       ctx,
+		jobs:      make(map[string]*scanJob),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /scan", srv.handleScan)
+	mux.HandleFunc("GET /results/{id}", srv.handleResults)
+	httpServer := &http.Server{Addr: *addr, Handler: srv.authorize(mux), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+	if srv.token == "" {
+		log.Printf("Warning: serving without -token; anyone who can reach %s can start scans.", *addr)
+	}
+	log.Printf("Serving the scan API on http://%s", *addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fatalf("serve: %v", err)
+	}
+}
+
+// authorize rejects requests without the bearer token, when one is configured.
+func (srv *scanServer) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if srv.token != "" {
+			token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(srv.token)) != 1 {
+				writeJSONError(w, http.StatusUnauthorized, "missing or wrong bearer token")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleScan queues a scan of the repository named by a JSON body, or of a zip archive body.
+// Requests arriving while the queue is full are refused before their body is read, since a
+// queued archive stays in memory until its scan runs.
+func (srv *scanServer) handleScan(w http.ResponseWriter, r *http.Request) {
+	if !srv.reserve() {
+		w.Header().Set("Retry-After", "30")
+		writeJSONError(w, http.StatusServiceUnavailable, "too many scans queued; try again later")
+		return
+	}
+	queued := false
+	defer func() {
+		if !queued {
+			srv.release()
+		}
+	}()
+	r.Body = http.MaxBytesReader(w, r.Body, srv.maxUpload)
+	var (
+		job     *scanJob
+		archive *zip.Reader
+	)
+	contentType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+	switch strings.TrimSpace(contentType) {
+	case "application/json":
+		var req scanRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+		if !isGitHubRepoURL(req.Repo) {
+			writeJSONError(w, http.StatusBadRequest, "repo must be an https://github.com/ repository URL")
+			return
+		}
+		// A ref is passed to git fetch, where a leading dash would read as an option.
+		if strings.HasPrefix(req.Ref, "-") {
+			writeJSONError(w, http.StatusBadRequest, "invalid ref")
+			return
+		}
+		job = &scanJob{Target: req.Repo, Ref: req.Ref}
+	case "application/zip":
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("failed to read the archive: %v", err))
+			return
+		}
+		if archive, err = zip.NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid zip archive: %v", err))
+			return
+		}
+		job = &scanJob{Target: "upload.zip"}
+	default:
+		writeJSONError(w, http.StatusUnsupportedMediaType, "send a JSON body naming the repo, or a zip archive with Content-Type: application/zip")
+		return
+	}
+
+	job.ID = newJobID()
+	job.Status = jobQueued
+	job.SubmittedAt = time.Now().UTC()
+	srv.mu.Lock()
+	srv.jobs[job.ID] = job
+	srv.mu.Unlock()
+	go srv.run(job, archive)
+	queued = true
+
+	w.Header().Set("Location", "/results/"+job.ID)
+	writeJSON(w, http.StatusAccepted, map[string]string{"id": job.ID, "status": jobQueued, "results": "/results/" + job.ID})
+}
+
+// isGitHubRepoURL reports whether repo is an https URL of a repository on github.com itself.
+// looksLikeGitHubURL also accepts any host ending in "github.com", which the command line can
+// trust but a shared service must not: callers could have it clone from hosts they control.
+func isGitHubRepoURL(repo string) bool {
+	u, err := url.Parse(repo)
+	if err != nil || u.Scheme != "https" || u.Host != "github.com" || u.User != nil {
+		return false
+	}
+	return looksLikeGitHubURL(repo)
+}
+
+// handleResults returns a job as it stands.
+func (srv *scanServer) handleResults(w http.ResponseWriter, r *http.Request) {
+	srv.mu.Lock()
+	job, ok := srv.jobs[r.PathValue("id")]
+	var snapshot scanJob
+	if ok {
+		snapshot = *job
+	}
+	srv.mu.Unlock()
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "no such scan; results are kept for a limited time")
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// run waits for a free slot, scans the job's target and records the outcome. The job is forgotten
+// keep after it finishes, including when shutdown cancels it before it gets a slot.
+func (srv *scanServer) run(job *scanJob, archive *zip.Reader) {
+	defer srv.forget(job)
+	select {
+	case srv.slots <- struct{}{}:
+		srv.release()
+	case <-srv.ctx.Done():
+		srv.release()
+		srv.finish(job, nil, srv.ctx.Err())
+		return
+	}
+	defer func() { <-srv.slots }()
+	srv.setStatus(job, jobRunning)
+	VLog.Printf("serve: scanning %s (job %s)", job.Target, job.ID)
+	report, err := srv.scan(job, archive)
+	srv.finish(job, report, err)
+}
+
+// forget removes a finished job keep from now.
+func (srv *scanServer) forget(job *scanJob) {
+	time.AfterFunc(srv.keep, func() {
+		srv.mu.Lock()
+		delete(srv.jobs, job.ID)
+		srv.mu.Unlock()
+	})
+}
+
+// scan runs the scan of a job: a clone of its repository, or the uploaded archive.
+func (srv *scanServer) scan(job *scanJob, archive *zip.Reader) (*scanner.JSONEnvelope, error) {
+	s, err := scanner.New(srv.opts)
+	if err != nil {
+		return nil, err
+	}
+	meta := scanner.ReportMeta{Target: job.Target, Ref: job.Ref, StartedAt: time.Now()}
+	var prompts []scanner.FoundPrompt
+	if archive != nil {
+		prompts, err = s.ScanFSContext(srv.ctx, archive, ".")
+	} else {
+		var target resolvedTarget
+		target, err = resolveTarget(srv.ctx, s, job.Target, job.Ref)
+		defer removeClones([]resolvedTarget{target})
+		if err != nil {
+			return nil, err
+		}
+		meta.Root, meta.RepoWebURL, meta.Commit = target.path, target.RepoWebURL, target.Commit
+		prompts, err = s.ScanDirectoryContext(srv.ctx, target.path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	scanner.SortFindings(prompts)
+	envelope := scanner.BuildEnvelope(meta, version, prompts, scanner.DefaultSeverityWeights)
+	return &envelope, nil
+}
+
+// reserve takes a place in the queue for a new scan, unless the queue is full.
+func (srv *scanServer) reserve() bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.pending >= srv.maxQueue {
+		return false
+	}
+	srv.pending++
+	return true
+}
+
+// release gives back a place taken by reserve, once its scan has a slot or was never queued.
+func (srv *scanServer) release() {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.pending--
+}
+
+// setStatus updates the status of a job.
+func (srv *scanServer) setStatus(job *scanJob, status string) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	job.Status = status
+}
+
+// finish records the report of a job, or why it failed.
+func (srv *scanServer) finish(job *scanJob, report *scanner.JSONEnvelope, err error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	now := time.Now().UTC()
+	job.FinishedAt = &now
+	if err != nil {
+		job.Status, job.Error = jobFailed, err.Error()
+		log.Printf("serve: scan of %s failed (job %s): %v", job.Target, job.ID, err)
+		return
+	}
+	job.Status, job.Report = jobDone, report
+}
+
+// newJobID returns a random job identifier.
+func newJobID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// writeJSON writes v as the JSON body of a response with status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// writeJSONError writes an error response with status.
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,161 @@
+// serve_test.go
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexferrari88/prompt-scanner/scanner"
+)
+
+// testServer returns a scanServer with the default scan options that runs up to concurrency jobs.
+func testServer(t *testing.T, concurrency int) *scanServer {
+	t.Helper()
+	VLog = log.New(io.Discard, "", 0)
+	return &scanServer{
+		opts: scanner.ScanOptions{
+			MinLength:              scanner.DefaultMinLength,
+			VariableKeywords:       scanner.DefaultVarKeywordsList,
+			ContentKeywords:        splitAndTrim(scanner.DefaultContentKeywords),
+			PlaceholderPatterns:    scanner.DefaultPlaceholderPatternsList,
+			PromptFilenamePatterns: scanner.DefaultPromptFilenamePatternsList,
+			MaxFileSize:            scanner.DefaultMaxFileSize,
+			ParseTimeout:           scanner.DefaultParseTimeout,
+		},
+		maxUpload: 1 << 20,
+		keep:      time.Minute,
+		slots:     make(chan struct{}, concurrency),
+		maxQueue:  1,
+		ctx:       context.Background(),
+		jobs:      make(map[string]*scanJob),
+	}
+}
+
+// testArchive returns a zip archive holding a single Python file with a system prompt.
+func testArchive(t *testing.T) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create("agent.py")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(f, "SYSTEM_PROMPT = \"You are a helpful assistant. Answer the user's question about {topic} concisely.\"\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return archive
+}
+
+// TestServeConcurrentJobs runs two jobs at once; with -race it catches scanners sharing state.
+func TestServeConcurrentJobs(t *testing.T) {
+	srv := testServer(t, 2)
+	jobs := []*scanJob{{ID: "a", Status: jobQueued, Target: "upload.zip"}, {ID: "b", Status: jobQueued, Target: "upload.zip"}}
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		srv.jobs[job.ID] = job
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			srv.run(job, testArchive(t))
+		}()
+	}
+	wg.Wait()
+	for _, job := range jobs {
+		if job.Status != jobDone {
+			t.Fatalf("job %s: status %s, error %q", job.ID, job.Status, job.Error)
+		}
+		if job.Report == nil || len(job.Report.Findings) != 1 {
+			t.Errorf("job %s: expected one finding, got report %+v", job.ID, job.Report)
+		}
+	}
+}
+
+func TestIsGitHubRepoURL(t *testing.T) {
+	for repo, want := range map[string]bool{
+		"https://github.com/owner/repo":      true,
+		"https://github.com/owner/repo.git":  true,
+		"http://github.com/owner/repo":       false,
+		"https://evilgithub.com/owner/repo":  false,
+		"https://github.com.evil/owner/repo": false,
+		"https://user@github.com/owner/repo": false,
+		"git@github.com:owner/repo.git":      false,
+	} {
+		if got := isGitHubRepoURL(repo); got != want {
+			t.Errorf("isGitHubRepoURL(%q) = %v, want %v", repo, got, want)
+		}
+	}
+}
+
+// TestServeQueueFull refuses a scan while the queue is full, and accepts one once it has room.
+func TestServeQueueFull(t *testing.T) {
+	srv := testServer(t, 1)
+	srv.slots <- struct{}{} // The only slot is busy
+	post := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader(`{"repo": "https://github.com/owner/repo"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		srv.handleScan(rec, req)
+		return rec.Code
+	}
+	if code := post(); code != http.StatusAccepted {
+		t.Fatalf("first scan: status %d, want %d", code, http.StatusAccepted)
+	}
+	if code := post(); code != http.StatusServiceUnavailable {
+		t.Fatalf("scan with a full queue: status %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader("not json"))
+	req.Header.Set("Content-Type", "application/json")
+	srv.handleScan(httptest.NewRecorder(), req)
+	srv.mu.Lock()
+	pending := srv.pending
+	srv.mu.Unlock()
+	if pending != 1 {
+		t.Errorf("pending = %d after a refused and a rejected request, want 1", pending)
+	}
+}
+
+// TestServeForgetsCancelledJobs forgets a job that shutdown cancels while it waits for a slot.
+func TestServeForgetsCancelledJobs(t *testing.T) {
+	srv := testServer(t, 1)
+	srv.keep = time.Millisecond
+	srv.slots <- struct{}{} // The only slot is busy
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	srv.ctx = ctx
+	job := &scanJob{ID: "a", Status: jobQueued, Target: "upload.zip"}
+	srv.jobs[job.ID] = job
+	srv.pending = 1
+	srv.run(job, testArchive(t))
+	if job.Status != jobFailed {
+		t.Fatalf("status %s, want %s", job.Status, jobFailed)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		srv.mu.Lock()
+		_, kept := srv.jobs[job.ID]
+		srv.mu.Unlock()
+		if !kept {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("cancelled job was never forgotten")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
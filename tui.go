@@ -0,0 +1,191 @@
+// tui.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+
+	"github.com/alexferrari88/prompt-scanner/scanner"
+)
+
+const (
+	dashboardRefresh  = 100 * time.Millisecond
+	dashboardFeedSize = 8
+	dashboardBarWidth = 24
+)
+
+// languageProgress tracks per-language counters for the dashboard.
+type languageProgress struct {
+	queued   int
+	scanned  int
+	findings int
+}
+
+// dashboard is the live -tui view drawn on stderr while a scan runs. It redraws in place using
+// ANSI cursor movement, so it only makes sense on an interactive terminal.
+type dashboard struct {
+	mu         sync.Mutex
+	out        *os.File
+	root       string
+	started    time.Time
+	languages  map[string]*languageProgress
+	feed       []string
+	findings   int
+	errors     int
+	linesDrawn int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newDashboard returns a dashboard drawing to out, or nil if out is not a terminal.
+func newDashboard(out *os.File) *dashboard {
+	if !term.IsTerminal(int(out.Fd())) {
+		return nil
+	}
+	return &dashboard{
+		out:       out,
+		started:   time.Now(),
+		languages: make(map[string]*languageProgress),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// setRoot sets the directory feed entries are shown relative to.
+func (d *dashboard) setRoot(root string) {
+	d.mu.Lock()
+	d.root = root
+	d.mu.Unlock()
+}
+
+// handle is the scanner's Progress callback.
+func (d *dashboard) handle(ev scanner.ProgressEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	lp, ok := d.languages[ev.Language]
+	if !ok {
+		lp = &languageProgress{}
+		d.languages[ev.Language] = lp
+	}
+	switch ev.Kind {
+	case scanner.FileQueued:
+		lp.queued++
+	case scanner.FileScanned:
+		lp.scanned++
+		lp.findings += len(ev.Findings)
+		d.findings += len(ev.Findings)
+		if ev.Err != nil {
+			d.errors++
+		}
+		for _, p := range ev.Findings {
+			path := p.Filepath
+			if rel, err := filepath.Rel(d.root, path); err == nil && d.root != "" {
+				path = rel
+			}
+			firstLine := strings.TrimSpace(strings.SplitN(strings.TrimSpace(p.Content), "\n", 2)[0])
+			d.feed = append(d.feed, fmt.Sprintf("%s:%d  %s", path, p.Line, firstLine))
+		}
+		if len(d.feed) > dashboardFeedSize {
+			d.feed = d.feed[len(d.feed)-dashboardFeedSize:]
+		}
+	}
+}
+
+// start begins redrawing periodically until finish is called.
+func (d *dashboard) start() {
+	fmt.Fprint(d.out, "\x1b[?25l") // Hide cursor while redrawing
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(dashboardRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stop:
+				d.draw()
+				return
+			case <-ticker.C:
+				d.draw()
+			}
+		}
+	}()
+}
+
+// finish draws the final state and restores the cursor. The last frame stays on screen.
+func (d *dashboard) finish() {
+	close(d.stop)
+	<-d.done
+	fmt.Fprint(d.out, "\x1b[?25h")
+}
+
+func (d *dashboard) draw() {
+	d.mu.Lock()
+	lines := d.render()
+	d.mu.Unlock()
+
+	width := 80
+	if w, _, err := term.GetSize(int(d.out.Fd())); err == nil && w > 0 {
+		width = w
+	}
+	var b strings.Builder
+	if d.linesDrawn > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", d.linesDrawn) // Move back to the top of the previous frame
+	}
+	for _, line := range lines {
+		b.WriteString("\x1b[2K")
+		b.WriteString(runewidth.Truncate(line, width-1, "…"))
+		b.WriteString("\n")
+	}
+	// Clear leftovers if the frame shrank.
+	for i := len(lines); i < d.linesDrawn; i++ {
+		b.WriteString("\x1b[2K\n")
+	}
+	if extra := d.linesDrawn - len(lines); extra > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", extra)
+	}
+	d.linesDrawn = len(lines)
+	fmt.Fprint(d.out, b.String())
+}
+
+// render builds the frame's lines. Callers must hold d.mu.
+func (d *dashboard) render() []string {
+	totalQueued, totalScanned := 0, 0
+	names := make([]string, 0, len(d.languages))
+	for name, lp := range d.languages {
+		names = append(names, name)
+		totalQueued += lp.queued
+		totalScanned += lp.scanned
+	}
+	sort.Strings(names)
+
+	lines := []string{
+		fmt.Sprintf("Scanning... %d/%d files, %d prompts, %d errors, %.1fs",
+			totalScanned, totalQueued, d.findings, d.errors, time.Since(d.started).Seconds()),
+		"",
+	}
+	for _, name := range names {
+		lp := d.languages[name]
+		filled := 0
+		if lp.queued > 0 {
+			filled = lp.scanned * dashboardBarWidth / lp.queued
+		}
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", dashboardBarWidth-filled)
+		lines = append(lines, fmt.Sprintf("  %-11s %s %5d/%-5d %4d prompts", name, bar, lp.scanned, lp.queued, lp.findings))
+	}
+	lines = append(lines, "", "Latest findings:")
+	if len(d.feed) == 0 {
+		lines = append(lines, "  (none yet)")
+	}
+	for _, entry := range d.feed {
+		lines = append(lines, "  "+entry)
+	}
+	return lines
+}
@@ -2,7 +2,11 @@
 package utils
 
 import (
+	"fmt"
+	"net/url"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"strings"
 )
 
@@ -36,4 +40,34 @@ func CountNewlines(s string) int {
 func CommandExists(cmd string) bool {
 	_, err := exec.LookPath(cmd)
 	return err == nil
-}
\ No newline at end of file
+}
+
+// GitHubWebURL converts a clone URL (https or git@github.com:) into the repository's web URL,
+// e.g. "git@github.com:org/repo.git" -> "https://github.com/org/repo".
+func GitHubWebURL(cloneURL string) string {
+	u := strings.TrimSpace(cloneURL)
+	if strings.HasPrefix(u, "git@github.com:") {
+		u = "https://github.com/" + strings.TrimPrefix(u, "git@github.com:")
+	}
+	u = strings.TrimSuffix(u, "/")
+	u = strings.TrimSuffix(u, ".git")
+	return u
+}
+
+// GitHubPermalink builds a commit-pinned blob URL for a line range of a file in a repository.
+// relPath uses OS separators and is converted to URL form, each segment escaped so that names
+// with spaces, "#", "?" or "%" neither break the link nor move its line anchor.
+func GitHubPermalink(repoWebURL, commit, relPath string, startLine, endLine int) string {
+	segments := strings.Split(path.Clean(filepath.ToSlash(relPath)), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	link := fmt.Sprintf("%s/blob/%s/%s", repoWebURL, commit, strings.Join(segments, "/"))
+	if startLine <= 0 {
+		return link
+	}
+	if endLine > startLine {
+		return fmt.Sprintf("%s#L%d-L%d", link, startLine, endLine)
+	}
+	return fmt.Sprintf("%s#L%d", link, startLine)
+}
@@ -0,0 +1,17 @@
+// utils/utils_test.go
+package utils
+
+import "testing"
+
+func TestGitHubPermalink(t *testing.T) {
+	for relPath, want := range map[string]string{
+		"src/agent.py":           "https://github.com/org/repo/blob/abc/src/agent.py#L3",
+		"docs/my prompts/a.md":   "https://github.com/org/repo/blob/abc/docs/my%20prompts/a.md#L3",
+		"prompts/#1 draft?.txt":  "https://github.com/org/repo/blob/abc/prompts/%231%20draft%3F.txt#L3",
+		"prompts/100%/system.md": "https://github.com/org/repo/blob/abc/prompts/100%25/system.md#L3",
+	} {
+		if got := GitHubPermalink("https://github.com/org/repo", "abc", relPath, 3, 0); got != want {
+			t.Errorf("GitHubPermalink(%q) = %q, want %q", relPath, got, want)
+		}
+	}
+}